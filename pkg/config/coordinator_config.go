@@ -16,11 +16,43 @@ const (
 	CfgCoordinatorMerkleTreeFilePath = "coordinator.merkleTreeFilePath"
 	// the interval milestones are issued
 	CfgCoordinatorIntervalSeconds = "coordinator.intervalSeconds"
+	// when milestones are issued ("fixed", "adaptive" or "onDemand")
+	// "fixed" uses the interval of "coordinator.intervalSeconds", "onDemand" only issues milestones via the "issueMilestone" API call
+	CfgCoordinatorSchedulingMode = "coordinator.scheduling.mode"
+	// the minimum interval milestones are issued in the "adaptive" scheduling mode
+	CfgCoordinatorSchedulingAdaptiveMinIntervalSeconds = "coordinator.scheduling.adaptive.minIntervalSeconds"
+	// the maximum interval milestones are issued in the "adaptive" scheduling mode
+	CfgCoordinatorSchedulingAdaptiveMaxIntervalSeconds = "coordinator.scheduling.adaptive.maxIntervalSeconds"
+	// the amount of unconfirmed transactions at which the minimum interval is used in the "adaptive" scheduling mode
+	CfgCoordinatorSchedulingAdaptiveHighLoadTransactions = "coordinator.scheduling.adaptive.highLoadTransactions"
 	// the hash function the coordinator will use to calculate milestone merkle tree hash (see RFC-0012)
 	CfgCoordinatorMilestoneMerkleTreeHashFunc = "coordinator.milestoneMerkleTreeHashFunc"
+	// the percentages of remaining Merkle tree keys at which a warning is logged
+	CfgCoordinatorMerkleKeyWarningThresholds = "coordinator.merkleKeyWarningThresholds"
+	// the signer which signs the milestones ("inProcess", "remote" or "softHSM")
+	// "inProcess" loads the seed from the COO_SEED environment variable
+	CfgCoordinatorSignerType = "coordinator.signer.type"
+	// the path to the file which persists the used Merkle key indexes of the "inProcess" signer and the signing daemon
+	CfgCoordinatorSignerStateFilePath = "coordinator.signer.stateFilePath"
+	// the path to the unix socket of the signing daemon used by the "remote" signer
+	// only the user running the signing daemon is allowed to connect to the socket
+	CfgCoordinatorSignerRemoteSocketPath = "coordinator.signer.remote.socketPath"
+	// the path to the token file of the "softHSM" signer
+	// the PIN of the token is loaded from the COO_HSM_PIN environment variable
+	CfgCoordinatorSignerSoftHSMTokenPath = "coordinator.signer.softHSM.tokenPath"
+	// whether to run in active/standby mode, in which only the instance holding the lease issues milestones
+	CfgCoordinatorHAEnabled = "coordinator.ha.enabled"
+	// the unique ID of this coordinator instance (hostname and process ID if empty)
+	CfgCoordinatorHAInstanceID = "coordinator.ha.instanceID"
+	// the path of the lease files shared by all coordinator instances, a file is written per version of the lease
+	CfgCoordinatorHALeaseFilePath = "coordinator.ha.leaseFilePath"
+	// the duration of the lease in seconds, after which a standby instance takes over if the lease wasn't renewed
+	CfgCoordinatorHALeaseDurationSeconds = "coordinator.ha.leaseDurationSeconds"
 	// the maximum amount of known bundle tails for milestone tipselection
 	// if this limit is exceeded, a new checkpoint is issued
 	CfgCoordinatorCheckpointsMaxTrackedTails = "coordinator.checkpoints.maxTrackedTransactions"
+	// the strategy which rates the branches for milestone tipselection ("heaviest", "oldest", "value" or "inputAddress")
+	CfgCoordinatorTipselectStrategy = "coordinator.tipsel.strategy"
 	// the minimum threshold of unconfirmed transactions in the heaviest branch for milestone tipselection
 	// if the value falls below that threshold, no more heaviest branch tips are picked
 	CfgCoordinatorTipselectMinHeaviestBranchUnconfirmedTransactionsThreshold = "coordinator.tipsel.minHeaviestBranchUnconfirmedTransactionsThreshold"
@@ -43,8 +75,22 @@ func init() {
 	configFlagSet.String(CfgCoordinatorStateFilePath, "coordinator.state", "the path to the state file of the coordinator")
 	configFlagSet.String(CfgCoordinatorMerkleTreeFilePath, "coordinator.tree", "the path to the Merkle tree of the coordinator")
 	configFlagSet.Int(CfgCoordinatorIntervalSeconds, 10, "the interval milestones are issued")
+	configFlagSet.String(CfgCoordinatorSchedulingMode, "fixed", "when milestones are issued (\"fixed\", \"adaptive\" or \"onDemand\")")
+	configFlagSet.Int(CfgCoordinatorSchedulingAdaptiveMinIntervalSeconds, 2, "the minimum interval milestones are issued in the \"adaptive\" scheduling mode")
+	configFlagSet.Int(CfgCoordinatorSchedulingAdaptiveMaxIntervalSeconds, 60, "the maximum interval milestones are issued in the \"adaptive\" scheduling mode")
+	configFlagSet.Int(CfgCoordinatorSchedulingAdaptiveHighLoadTransactions, 1000, "the amount of unconfirmed transactions at which the minimum interval is used in the \"adaptive\" scheduling mode")
 	configFlagSet.String(CfgCoordinatorMilestoneMerkleTreeHashFunc, "BLAKE2b-512", "the hash function the coordinator will use to calculate milestone merkle tree hash (see RFC-0012)")
+	configFlagSet.IntSlice(CfgCoordinatorMerkleKeyWarningThresholds, []int{25, 10, 5, 1}, "the percentages of remaining Merkle tree keys at which a warning is logged")
+	configFlagSet.String(CfgCoordinatorSignerType, "inProcess", "the signer which signs the milestones (\"inProcess\", \"remote\" or \"softHSM\")")
+	configFlagSet.String(CfgCoordinatorSignerStateFilePath, "coordinator_signer.state", "the path to the file which persists the used Merkle key indexes of the \"inProcess\" signer and the signing daemon")
+	configFlagSet.String(CfgCoordinatorSignerRemoteSocketPath, "coordinator_signer.sock", "the path to the unix socket of the signing daemon used by the \"remote\" signer")
+	configFlagSet.String(CfgCoordinatorSignerSoftHSMTokenPath, "coordinator.token", "the path to the token file of the \"softHSM\" signer")
+	configFlagSet.Bool(CfgCoordinatorHAEnabled, false, "whether to run in active/standby mode, in which only the instance holding the lease issues milestones")
+	configFlagSet.String(CfgCoordinatorHAInstanceID, "", "the unique ID of this coordinator instance (hostname and process ID if empty)")
+	configFlagSet.String(CfgCoordinatorHALeaseFilePath, "coordinator.lease", "the path of the lease files shared by all coordinator instances, a file is written per version of the lease")
+	configFlagSet.Int(CfgCoordinatorHALeaseDurationSeconds, 15, "the duration of the lease in seconds, after which a standby instance takes over if the lease wasn't renewed")
 	configFlagSet.Int(CfgCoordinatorCheckpointsMaxTrackedTails, 10000, "maximum amount of known bundle tails for milestone tipselection")
+	configFlagSet.String(CfgCoordinatorTipselectStrategy, "heaviest", "the strategy which rates the branches for milestone tipselection (\"heaviest\", \"oldest\", \"value\" or \"inputAddress\")")
 	configFlagSet.Int(CfgCoordinatorTipselectMinHeaviestBranchUnconfirmedTransactionsThreshold, 20, "minimum threshold of unconfirmed transactions in the heaviest branch")
 	configFlagSet.Int(CfgCoordinatorTipselectMaxHeaviestBranchTipsPerCheckpoint, 10, "maximum amount of checkpoint transactions with heaviest branch tips")
 	configFlagSet.Int(CfgCoordinatorTipselectRandomTipsPerCheckpoint, 3, "amount of checkpoint transactions with random tips")
@@ -5,6 +5,7 @@ type PeerConfig struct {
 	ID         string `json:"identity" mapstructure:"identity"`
 	Alias      string `json:"alias" mapstructure:"alias"`
 	PreferIPv6 bool   `json:"preferIPv6" mapstructure:"preferIPv6"`
+	PublicKey  string `json:"publicKey,omitempty" mapstructure:"publicKey"`
 }
 
 const (
@@ -14,6 +15,53 @@ const (
 	CfgNetGossipBindAddress = "network.gossip.bindAddress"
 	// the number of seconds to wait before trying to reconnect to a disconnected peer
 	CfgNetGossipReconnectAttemptIntervalSeconds = "network.gossip.reconnectAttemptIntervalSeconds"
+	// whether to use encrypted connections to peers with a pinned public key and to accept encrypted inbound connections
+	CfgNetGossipEncryptionEnabled = "network.gossip.encryption.enabled"
+	// the path to the private key of the identity used for encrypted connections
+	CfgNetGossipEncryptionIdentityPrivateKeyPath = "network.gossip.encryption.identityPrivateKeyPath"
+	// whether to reject unencrypted connections
+	CfgNetGossipEncryptionRequired = "network.gossip.encryption.required"
+	// the maximum number of unanswered transaction requests per peer (0 = unlimited)
+	CfgNetGossipMaxRequestsInFlightPerPeer = "network.gossip.maxRequestsInFlightPerPeer"
+	// whether to batch and compress transactions sent to peers which support it
+	CfgNetGossipTransactionBatching = "network.gossip.transactionBatching"
+	// the maximum number of bytes per second sent to a single peer (0 = unlimited)
+	CfgNetGossipMaxUploadBytesPerSecond = "network.gossip.maxUploadBytesPerSecond"
+	// the maximum number of bytes per second received from a single peer (0 = unlimited)
+	CfgNetGossipMaxDownloadBytesPerSecond = "network.gossip.maxDownloadBytesPerSecond"
+	// whether to run in light mode, in which transactions aren't relayed and only milestone cones
+	// and transactions of the watched addresses are processed
+	CfgNetGossipLightModeEnabled = "network.gossip.lightMode.enabled"
+	// the addresses whose unconfirmed transactions are processed in light mode
+	CfgNetGossipLightModeWatchedAddresses = "network.gossip.lightMode.watchedAddresses"
+	// the maximum number of events kept in the connection lifecycle history of a peer
+	CfgNetPeerHistorySize = "network.peerHistorySize"
+	// the score below which a peer gets disconnected and blacklisted temporarily
+	CfgNetReputationBlacklistThreshold = "network.reputation.blacklistThreshold"
+	// the number of seconds a peer is blacklisted the first time, the duration doubles with every further blacklisting
+	CfgNetReputationBlacklistDurationSeconds = "network.reputation.blacklistDurationSeconds"
+	// the maximum number of seconds a peer is blacklisted
+	CfgNetReputationMaxBlacklistDurationSeconds = "network.reputation.maxBlacklistDurationSeconds"
+	// the path to the file in which the peer scores are persisted
+	CfgNetReputationScoresPath = "network.reputation.scoresPath"
+	// the "host:port" addresses whose A/AAAA records are resolved to static peers
+	CfgNetPeerSourcesDNS = "network.peerSources.dns"
+	// the names whose SRV records are resolved to static peers
+	CfgNetPeerSourcesSRV = "network.peerSources.srv"
+	// the file paths or HTTP(S) URLs of signed seed lists of static peers
+	CfgNetPeerSourcesSeedLists = "network.peerSources.seedLists"
+	// the hex encoded ed25519 public key used to verify the seed lists
+	CfgNetPeerSourcesSeedListPublicKey = "network.peerSources.seedListPublicKey"
+	// the number of seconds between the refreshes of the peer sources
+	CfgNetPeerSourcesRefreshIntervalSeconds = "network.peerSources.refreshIntervalSeconds"
+	// the maximum number of inbound connections which are handshaking at the same time (0 = unlimited)
+	CfgNetInboundLimitsMaxHandshaking = "network.inboundLimits.maxHandshaking"
+	// the maximum number of inbound connections per IP address and minute (0 = unlimited)
+	CfgNetInboundLimitsMaxConnectionsPerIPPerMinute = "network.inboundLimits.maxConnectionsPerIPPerMinute"
+	// the number of seconds in which a connection has to complete the handshake (0 = unlimited)
+	CfgNetInboundLimitsHandshakeTimeoutSeconds = "network.inboundLimits.handshakeTimeoutSeconds"
+	// the maximum number of autopeered and accepted unknown peers in the same /24 (IPv4) or /48 (IPv6) subnet (0 = unlimited)
+	CfgNetInboundLimitsMaxPeersPerSubnet = "network.inboundLimits.maxPeersPerSubnet"
 
 	// enable inbound connections from unknown peers
 	CfgPeeringAcceptAnyConnection = "acceptAnyConnection"
@@ -48,6 +96,33 @@ func init() {
 	configFlagSet.Bool(CfgNetPreferIPv6, false, "defines if IPv6 is preferred for peers added through the API")
 	configFlagSet.String(CfgNetGossipBindAddress, "0.0.0.0:15600", "the bind address of the gossip TCP server")
 	configFlagSet.Int(CfgNetGossipReconnectAttemptIntervalSeconds, 60, "the number of seconds to wait before trying to reconnect to a disconnected peer")
+	configFlagSet.Bool(CfgNetGossipEncryptionEnabled, false, "whether to use encrypted connections to peers with a pinned public key and to accept encrypted inbound connections")
+	configFlagSet.String(CfgNetGossipEncryptionIdentityPrivateKeyPath, "gossip_identity.key", "the path to the private key of the identity used for encrypted connections (created if it doesn't exist)")
+	configFlagSet.Bool(CfgNetGossipEncryptionRequired, false, "whether to reject unencrypted connections")
+	configFlagSet.Int(CfgNetGossipMaxRequestsInFlightPerPeer, 1000, "the maximum number of unanswered transaction requests per peer (0 = unlimited)")
+	configFlagSet.Bool(CfgNetGossipTransactionBatching, true, "whether to batch and compress transactions sent to peers which support it")
+	configFlagSet.Int(CfgNetGossipMaxUploadBytesPerSecond, 0, "the maximum number of bytes per second sent to a single peer (0 = unlimited)")
+	configFlagSet.Int(CfgNetGossipMaxDownloadBytesPerSecond, 0, "the maximum number of bytes per second received from a single peer (0 = unlimited)")
+	configFlagSet.Bool(CfgNetGossipLightModeEnabled, false, "whether to run in light mode, in which transactions aren't relayed and only milestone cones and transactions of the watched addresses are processed")
+	configFlagSet.StringSlice(CfgNetGossipLightModeWatchedAddresses, []string{}, "the addresses whose unconfirmed transactions are processed in light mode")
+	configFlagSet.Int(CfgNetPeerHistorySize, 100, "the maximum number of events kept in the connection lifecycle history of a peer")
+	configFlagSet.Float64(CfgNetReputationBlacklistThreshold, -50, "the score (-100 to 100) below which a peer gets disconnected and blacklisted temporarily")
+	configFlagSet.Int(CfgNetReputationBlacklistDurationSeconds, 60, "the number of seconds a peer is blacklisted the first time, the duration doubles with every further blacklisting")
+	configFlagSet.Int(CfgNetReputationMaxBlacklistDurationSeconds, 3600, "the maximum number of seconds a peer is blacklisted")
+	configFlagSet.String(CfgNetReputationScoresPath, "peer_scores.json", "the path to the file in which the peer scores are persisted")
+
+	// inbound limits
+	configFlagSet.Int(CfgNetInboundLimitsMaxHandshaking, 20, "the maximum number of inbound connections which are handshaking at the same time (0 = unlimited)")
+	configFlagSet.Int(CfgNetInboundLimitsMaxConnectionsPerIPPerMinute, 10, "the maximum number of inbound connections per IP address and minute (0 = unlimited)")
+	configFlagSet.Int(CfgNetInboundLimitsHandshakeTimeoutSeconds, 10, "the number of seconds in which a connection has to complete the handshake (0 = unlimited)")
+	configFlagSet.Int(CfgNetInboundLimitsMaxPeersPerSubnet, 2, "the maximum number of autopeered and accepted unknown peers in the same /24 (IPv4) or /48 (IPv6) subnet (0 = unlimited)")
+
+	// peer sources
+	configFlagSet.StringSlice(CfgNetPeerSourcesDNS, []string{}, "the \"host:port\" addresses whose A/AAAA records are resolved to static peers")
+	configFlagSet.StringSlice(CfgNetPeerSourcesSRV, []string{}, "the names whose SRV records are resolved to static peers, e.g. \"_hornet._tcp.example.com\"")
+	configFlagSet.StringSlice(CfgNetPeerSourcesSeedLists, []string{}, "the file paths or HTTP(S) URLs of signed seed lists of static peers")
+	configFlagSet.String(CfgNetPeerSourcesSeedListPublicKey, "", "the hex encoded ed25519 public key used to verify the seed lists")
+	configFlagSet.Int(CfgNetPeerSourcesRefreshIntervalSeconds, 300, "the number of seconds between the refreshes of the peer sources")
 
 	// peering
 	peeringFlagSet.Bool(CfgPeeringAcceptAnyConnection, false, "enable inbound connections from unknown peers")
@@ -1,5 +1,11 @@
 package config
 
+// PinnedSnapshotConfig holds the information about a named snapshot at a fixed milestone index.
+type PinnedSnapshotConfig struct {
+	Name  string `json:"name" mapstructure:"name"`
+	Index int    `json:"index" mapstructure:"index"`
+}
+
 const (
 	// which snapshot type to load. 'local' or 'global'
 	CfgSnapshotLoadType = "snapshots.loadType"
@@ -9,6 +15,16 @@ const (
 	CfgLocalSnapshotsIntervalSynced = "snapshots.local.intervalSynced"
 	// interval, in milestone transactions, at which snapshot files are created if the ledger is not fully synchronized
 	CfgLocalSnapshotsIntervalUnsynced = "snapshots.local.intervalUnsynced"
+	// the schedule used to create local snapshots. 'interval', 'modulo' or 'cron'
+	CfgLocalSnapshotsScheduleMode = "snapshots.local.schedule.mode"
+	// local snapshots are created at target indexes which are a multiple of this value (schedule mode 'modulo')
+	CfgLocalSnapshotsScheduleMilestoneModulo = "snapshots.local.schedule.milestoneModulo"
+	// cron-like schedule "minute hour day-of-month month day-of-week" at which local snapshots are created (schedule mode 'cron')
+	CfgLocalSnapshotsScheduleCron = "snapshots.local.schedule.cron"
+	// amount of local snapshot files to keep in rotation (0 = only keep the latest one)
+	CfgLocalSnapshotsRetentionKeepFiles = "snapshots.local.retention.keepFiles"
+	// named snapshots at fixed milestone indexes that are never overwritten or rotated
+	CfgLocalSnapshotsPinned = "snapshots.local.pinned"
 	// path to the local snapshot file
 	CfgLocalSnapshotsPath = "snapshots.local.path"
 	// URL to load the local snapshot file from
@@ -23,6 +39,23 @@ const (
 	CfgPruningEnabled = "snapshots.pruning.enabled"
 	// amount of milestone transactions to keep in the database
 	CfgPruningDelay = "snapshots.pruning.delay"
+	// the maximum size of the database, e.g. "30GB" (empty = prune by delay only).
+	// if set, the pruning target is derived from the database size, and the delay is the minimum amount of milestones to keep
+	CfgPruningTargetDatabaseSize = "snapshots.pruning.targetDatabaseSize"
+	// the percentage the database size is reduced below the target size if pruning by size was triggered
+	CfgPruningTargetDatabaseSizeThresholdPercentage = "snapshots.pruning.targetDatabaseSizeThresholdPercentage"
+	// addresses whose transactions and bundles are kept in the database while pruning
+	CfgPruningRetentionAddresses = "snapshots.pruning.retention.addresses"
+	// tags whose transactions and bundles are kept in the database while pruning
+	CfgPruningRetentionTags = "snapshots.pruning.retention.tags"
+	// bundle hashes whose transactions are kept in the database while pruning
+	CfgPruningRetentionBundles = "snapshots.pruning.retention.bundles"
+	// the amount of milestones the retained transactions are kept after their milestone was pruned (0 = keep forever)
+	CfgPruningRetentionMilestones = "snapshots.pruning.retention.milestones"
+	// the maximum amount of milestones pruned per second (0 = unlimited)
+	CfgPruningMaxMilestonesPerSecond = "snapshots.pruning.throttle.maxMilestonesPerSecond"
+	// the maximum amount of transactions deleted per second (0 = unlimited)
+	CfgPruningMaxTransactionsPerSecond = "snapshots.pruning.throttle.maxTransactionsPerSecond"
 	// enable support for wereAddressesSpentFrom (needed for Trinity, but local snapshots are much bigger)
 	CfgSpentAddressesEnabled = "spentAddresses.enabled"
 )
@@ -32,6 +65,11 @@ func init() {
 	configFlagSet.Int(CfgLocalSnapshotsDepth, 50, "the depth, respectively the starting point, at which a local snapshot of the ledger is generated")
 	configFlagSet.Int(CfgLocalSnapshotsIntervalSynced, 50, "interval, in milestone transactions, at which snapshot files are created if the ledger is fully synchronized")
 	configFlagSet.Int(CfgLocalSnapshotsIntervalUnsynced, 1000, "interval, in milestone transactions, at which snapshot files are created if the ledger is not fully synchronized")
+	configFlagSet.String(CfgLocalSnapshotsScheduleMode, "interval", "the schedule used to create local snapshots. 'interval', 'modulo' or 'cron'")
+	configFlagSet.Int(CfgLocalSnapshotsScheduleMilestoneModulo, 10000, "local snapshots are created at target indexes which are a multiple of this value (schedule mode 'modulo')")
+	configFlagSet.String(CfgLocalSnapshotsScheduleCron, "0 0 * * *", "cron-like schedule \"minute hour day-of-month month day-of-week\" at which local snapshots are created (schedule mode 'cron')")
+	configFlagSet.Int(CfgLocalSnapshotsRetentionKeepFiles, 0, "amount of local snapshot files to keep in rotation (0 = only keep the latest one)")
+	NodeConfig.SetDefault(CfgLocalSnapshotsPinned, []PinnedSnapshotConfig{})
 	configFlagSet.String(CfgLocalSnapshotsPath, "snapshots/mainnet/export.bin", "path to the local snapshot file")
 	configFlagSet.StringSlice(CfgLocalSnapshotsDownloadURLs, []string{}, "URLs to load the local snapshot file from. Provide multiple URLs as fall back sources")
 	configFlagSet.String(CfgGlobalSnapshotPath, "snapshotMainnet.txt", "path to the global snapshot file containing the ledger state")
@@ -39,9 +77,17 @@ func init() {
 		"previousEpochsSpentAddresses1.txt",
 		"previousEpochsSpentAddresses2.txt",
 		"previousEpochsSpentAddresses3.txt",
-	}, "paths to the spent addresses files")
+	}, "paths to the spent addresses files (text files or files created by the exportSpentAddresses API call)")
 	configFlagSet.Int(CfgGlobalSnapshotIndex, 1050000, "milestone index of the global snapshot")
 	configFlagSet.Bool(CfgPruningEnabled, true, "whether to delete old transaction data from the database")
 	configFlagSet.Int(CfgPruningDelay, 60480, "amount of milestone transactions to keep in the database")
+	configFlagSet.String(CfgPruningTargetDatabaseSize, "", "the maximum size of the database, e.g. \"30GB\" (empty = prune by delay only). if set, the delay is the minimum amount of milestones to keep")
+	configFlagSet.Float64(CfgPruningTargetDatabaseSizeThresholdPercentage, 10.0, "the percentage the database size is reduced below the target size if pruning by size was triggered")
+	configFlagSet.StringSlice(CfgPruningRetentionAddresses, []string{}, "addresses whose transactions and bundles are kept in the database while pruning")
+	configFlagSet.StringSlice(CfgPruningRetentionTags, []string{}, "tags whose transactions and bundles are kept in the database while pruning")
+	configFlagSet.StringSlice(CfgPruningRetentionBundles, []string{}, "bundle hashes whose transactions are kept in the database while pruning")
+	configFlagSet.Int(CfgPruningRetentionMilestones, 0, "the amount of milestones the retained transactions are kept after their milestone was pruned (0 = keep forever)")
+	configFlagSet.Float64(CfgPruningMaxMilestonesPerSecond, 0, "the maximum amount of milestones pruned per second (0 = unlimited)")
+	configFlagSet.Int(CfgPruningMaxTransactionsPerSecond, 0, "the maximum amount of transactions deleted per second while pruning (0 = unlimited)")
 	configFlagSet.Bool(CfgSpentAddressesEnabled, true, "enable support for wereAddressesSpentFrom (needed for Trinity, but local snapshots are much bigger)")
 }
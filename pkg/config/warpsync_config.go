@@ -3,8 +3,14 @@ package config
 const (
 	// the used advancement range per warpsync checkpoint
 	CfgWarpSyncAdvancementRange = "warpsync.advancementRange"
+	// whether to request whole milestone cones in bulk from peers which support it
+	CfgWarpSyncMilestoneConeSync = "warpsync.milestoneConeSync"
+	// the amount of milestone cones served to a single peer per second (0 = unlimited)
+	CfgWarpSyncMilestoneConesPerSecond = "warpsync.milestoneConesPerSecond"
 )
 
 func init() {
 	configFlagSet.Int(CfgWarpSyncAdvancementRange, 50, "the used advancement range per warpsync checkpoint")
+	configFlagSet.Bool(CfgWarpSyncMilestoneConeSync, true, "whether to request whole milestone cones in bulk from peers which support it")
+	configFlagSet.Int(CfgWarpSyncMilestoneConesPerSecond, 10, "the amount of milestone cones served to a single peer per second (0 = unlimited)")
 }
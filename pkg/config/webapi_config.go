@@ -27,6 +27,10 @@ const (
 	CfgWebAPILimitsMaxGetTrytes = "httpAPI.limits.getTrytes"
 	// the maximum number of parameters in an API call
 	CfgWebAPILimitsMaxRequestsList = "httpAPI.limits.requestsList"
+	// the maximum number of tips that may be given to the simulateConfirmation endpoint
+	CfgWebAPILimitsMaxSimulateConfirmationTips = "httpAPI.limits.simulateConfirmationTips"
+	// the maximum number of transactions that may be traversed by the simulateConfirmation endpoint
+	CfgWebAPILimitsMaxSimulateConfirmationTransactions = "httpAPI.limits.simulateConfirmationTransactions"
 )
 
 func init() {
@@ -58,4 +62,6 @@ func init() {
 	configFlagSet.Int(CfgWebAPILimitsMaxFindTransactions, 1000, "the maximum number of transactions that may be returned by the findTransactions endpoint")
 	configFlagSet.Int(CfgWebAPILimitsMaxGetTrytes, 1000, "the maximum number of trytes that may be returned by the getTrytes endpoint")
 	configFlagSet.Int(CfgWebAPILimitsMaxRequestsList, 1000, "the maximum number of parameters in an API call")
+	configFlagSet.Int(CfgWebAPILimitsMaxSimulateConfirmationTips, 10, "the maximum number of tips that may be given to the simulateConfirmation endpoint")
+	configFlagSet.Int(CfgWebAPILimitsMaxSimulateConfirmationTransactions, 50000, "the maximum number of transactions that may be traversed by the simulateConfirmation endpoint")
 }
@@ -139,6 +139,36 @@ func (t *ApproveesTraverser) TraverseTrunkAndBranch(trunkTxHash hornet.Hash, bra
 	return nil
 }
 
+// TraverseMultiple starts to traverse the approvees (past cone) of the given start transactions in the given order.
+// Every start transaction is only traversed after the past cones of the previous ones were processed,
+// so transactions that are referenced by several start transactions are only consumed once.
+// It is a DFS with trunk / branch.
+// Caution: condition func is not in DFS order
+func (t *ApproveesTraverser) TraverseMultiple(startTxHashes hornet.Hashes, traverseSolidEntryPoints bool, traverseTailsOnly bool) error {
+
+	// make sure only one traversal is running
+	t.traverserLock.Lock()
+
+	// Prepare for a new traversal
+	t.reset()
+
+	t.traverseSolidEntryPoints = traverseSolidEntryPoints
+	t.traverseTailsOnly = traverseTailsOnly
+
+	defer t.cleanup(true)
+
+	for _, startTxHash := range startTxHashes {
+		t.stack.PushFront(startTxHash)
+		for t.stack.Len() > 0 {
+			if err := t.processStackApprovees(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // processStackApprovees checks if the current element in the stack must be processed or traversed.
 // first the trunk is traversed, then the branch.
 func (t *ApproveesTraverser) processStackApprovees() error {
@@ -77,6 +77,16 @@ func TraverseApproveesTrunkBranch(trunkTxHash hornet.Hash, branchTxHash hornet.H
 	return t.TraverseTrunkAndBranch(trunkTxHash, branchTxHash, traverseSolidEntryPoints, traverseTailsOnly)
 }
 
+// TraverseApproveesMultiple starts to traverse the approvees (past cone) of the given start transactions in the given order
+// until the traversal stops due to no more transactions passing the given condition.
+// It is a DFS with trunk / branch.
+// Caution: condition func is not in DFS order
+func TraverseApproveesMultiple(startTxHashes hornet.Hashes, condition Predicate, consumer Consumer, onMissingApprovee OnMissingApprovee, onSolidEntryPoint OnSolidEntryPoint, traverseSolidEntryPoints bool, traverseTailsOnly bool, abortSignal <-chan struct{}) error {
+
+	t := NewApproveesTraverser(condition, consumer, onMissingApprovee, onSolidEntryPoint, abortSignal)
+	return t.TraverseMultiple(startTxHashes, traverseSolidEntryPoints, traverseTailsOnly)
+}
+
 // TraverseApprovees starts to traverse the approvees (past cone) of the given start transaction until
 // the traversal stops due to no more transactions passing the given condition.
 // It is a DFS with trunk / branch.
@@ -0,0 +1,81 @@
+package inclusionproof
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+var (
+	// ErrTailNotIncluded is returned when a proof is created for a tail which was not included by the milestone.
+	ErrTailNotIncluded = errors.New("tail transaction was not included by the milestone")
+	// ErrMilestoneNotFound is returned when the milestone bundle of the confirmation is not found.
+	ErrMilestoneNotFound = errors.New("milestone not found")
+)
+
+// CreateProof creates the proof that the bundle of the given tail was included by the given milestone confirmation.
+// The proof can be verified with the milestone bundle alone.
+func CreateProof(conf *tangle.MilestoneConfirmation, tailHash hornet.Hash, milestoneMerkleHashFunc crypto.Hash) (*Proof, error) {
+
+	index := -1
+	for i, tail := range conf.TailsIncluded {
+		if bytes.Equal(tail, tailHash) {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 {
+		return nil, fmt.Errorf("%w: tail %s, milestone %d", ErrTailNotIncluded, tailHash.Trytes(), conf.MilestoneIndex)
+	}
+
+	auditPath, err := whiteflag.NewHasher(milestoneMerkleHashFunc).AuditPath(conf.TailsIncluded, index)
+	if err != nil {
+		return nil, err
+	}
+
+	auditPathHex := make([]string, 0, len(auditPath))
+	for _, node := range auditPath {
+		auditPathHex = append(auditPathHex, hex.EncodeToString(node))
+	}
+
+	cachedMs := tangle.GetMilestoneOrNil(conf.MilestoneIndex) // bundle +1
+	if cachedMs == nil {
+		return nil, fmt.Errorf("%w: %d", ErrMilestoneNotFound, conf.MilestoneIndex)
+	}
+	defer cachedMs.Release(true) // bundle -1
+
+	cachedTxs := cachedMs.GetBundle().GetTransactions() // tx +1
+	defer cachedTxs.Release(true)                       // tx -1
+
+	milestoneTrytes := make([]trinary.Trytes, len(cachedTxs))
+	for _, cachedTx := range cachedTxs {
+		tx := cachedTx.GetTransaction().Tx
+		if tx.CurrentIndex >= uint64(len(milestoneTrytes)) {
+			return nil, fmt.Errorf("invalid milestone bundle %d", conf.MilestoneIndex)
+		}
+
+		txTrytes, err := transaction.TransactionToTrytes(tx)
+		if err != nil {
+			return nil, err
+		}
+		milestoneTrytes[tx.CurrentIndex] = txTrytes
+	}
+
+	return &Proof{
+		TailTransaction: tailHash.Trytes(),
+		Index:           index,
+		Count:           len(conf.TailsIncluded),
+		AuditPath:       auditPathHex,
+		MilestoneTrytes: milestoneTrytes,
+	}, nil
+}
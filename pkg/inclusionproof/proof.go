@@ -0,0 +1,169 @@
+// Package inclusionproof verifies that a bundle was included by a milestone.
+// The proof only consists of the milestone bundle and the audit path of the bundle's tail
+// in the white-flag Merkle tree of the milestone, so it can be checked without the ledger or the tangle.
+package inclusionproof
+
+import (
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/encoding/b1t6"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/merkle"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+var (
+	// ErrInvalidMilestone is returned when the milestone bundle of the proof was not issued by the coordinator.
+	ErrInvalidMilestone = errors.New("invalid milestone")
+	// ErrInvalidAuditPath is returned when the audit path doesn't prove the inclusion of the tail in the milestone.
+	ErrInvalidAuditPath = errors.New("invalid audit path")
+)
+
+// Proof proves that the bundle of a tail transaction was included by a milestone.
+type Proof struct {
+	// The tail transaction hash of the included bundle.
+	TailTransaction trinary.Hash `json:"tailTransaction"`
+	// The position of the tail in the included tails of the milestone.
+	Index int `json:"index"`
+	// The amount of included tails of the milestone.
+	Count int `json:"count"`
+	// The hex encoded sibling nodes from the leaf up to the Merkle tree root.
+	AuditPath []string `json:"auditPath"`
+	// The transaction trytes of the milestone bundle, ordered by their index in the bundle.
+	MilestoneTrytes []trinary.Trytes `json:"milestoneTrytes"`
+}
+
+// Verifier checks proofs against the milestones of a coordinator.
+type Verifier struct {
+	coordinatorAddress trinary.Hash
+	securityLvl        int
+	merkleTreeDepth    int
+	hasher             *whiteflag.Hasher
+}
+
+// NewVerifier creates a new Verifier for the milestones of the coordinator with the given address and parameters.
+func NewVerifier(coordinatorAddress trinary.Hash, securityLvl int, merkleTreeDepth int, milestoneMerkleHashFunc crypto.Hash) *Verifier {
+	return &Verifier{
+		coordinatorAddress: coordinatorAddress,
+		securityLvl:        securityLvl,
+		merkleTreeDepth:    merkleTreeDepth,
+		hasher:             whiteflag.NewHasher(milestoneMerkleHashFunc),
+	}
+}
+
+// Verify checks that the milestone bundle of the proof was signed by the coordinator
+// and that the audit path proves the inclusion of the tail in the white-flag Merkle tree hash of the milestone.
+// Returns the index of the milestone which included the tail.
+func (v *Verifier) Verify(proof *Proof) (milestone.Index, error) {
+
+	if !guards.IsTransactionHash(proof.TailTransaction) {
+		return 0, fmt.Errorf("%w: invalid tail transaction hash", ErrInvalidAuditPath)
+	}
+
+	msIndex, merkleTreeHash, err := v.verifyMilestone(proof.MilestoneTrytes)
+	if err != nil {
+		return 0, err
+	}
+
+	auditPath := make([][]byte, 0, len(proof.AuditPath))
+	for _, nodeHex := range proof.AuditPath {
+		node, err := hex.DecodeString(nodeHex)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidAuditPath, err)
+		}
+		auditPath = append(auditPath, node)
+	}
+
+	if !v.hasher.VerifyAuditPath(hornet.HashFromHashTrytes(proof.TailTransaction), proof.Index, proof.Count, auditPath, merkleTreeHash) {
+		return 0, fmt.Errorf("%w: tail %s was not included by milestone %d", ErrInvalidAuditPath, proof.TailTransaction, msIndex)
+	}
+
+	return msIndex, nil
+}
+
+// verifyMilestone checks the structure and the signature of the milestone bundle
+// and returns its index and the white-flag Merkle tree hash.
+func (v *Verifier) verifyMilestone(milestoneTrytes []trinary.Trytes) (milestone.Index, []byte, error) {
+
+	// a milestone bundle consists of securityLvl transactions for the signatures and one for the audit path
+	if len(milestoneTrytes) != v.securityLvl+1 {
+		return 0, nil, fmt.Errorf("%w: wrong amount of transactions: %d", ErrInvalidMilestone, len(milestoneTrytes))
+	}
+
+	txs, err := transaction.AsTransactionObjects(milestoneTrytes, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrInvalidMilestone, err)
+	}
+
+	siblingsTx := &txs[v.securityLvl]
+	msIndex := milestone.Index(trinary.TrytesToInt(txs[0].ObsoleteTag))
+
+	if msIndex >= milestone.Index(1<<uint(v.merkleTreeDepth)) {
+		return 0, nil, fmt.Errorf("%w: milestone index %d exceeds the Merkle tree", ErrInvalidMilestone, msIndex)
+	}
+
+	var fragments []trinary.Trytes
+	for i := range txs {
+		tx := &txs[i]
+
+		if tx.CurrentIndex != uint64(i) || tx.LastIndex != uint64(v.securityLvl) || tx.Bundle != txs[0].Bundle || tx.Value != 0 {
+			return 0, nil, fmt.Errorf("%w: structure is wrong, hash: %v", ErrInvalidMilestone, tx.Hash)
+		}
+
+		if tx.ObsoleteTag != txs[0].ObsoleteTag {
+			return 0, nil, fmt.Errorf("%w: milestone index differs, hash: %v", ErrInvalidMilestone, tx.Hash)
+		}
+
+		if i == v.securityLvl {
+			if tx.Address != v.coordinatorAddress && tx.Address != consts.NullHashTrytes {
+				return 0, nil, fmt.Errorf("%w: transaction was not issued by the coordinator, hash: %v", ErrInvalidMilestone, tx.Hash)
+			}
+			continue
+		}
+
+		if tx.Address != v.coordinatorAddress {
+			return 0, nil, fmt.Errorf("%w: transaction was not issued by the coordinator, hash: %v", ErrInvalidMilestone, tx.Hash)
+		}
+
+		if tx.TrunkTransaction != txs[i+1].Hash || tx.BranchTransaction != siblingsTx.TrunkTransaction {
+			return 0, nil, fmt.Errorf("%w: structure is wrong, hash: %v", ErrInvalidMilestone, tx.Hash)
+		}
+
+		fragments = append(fragments, tx.SignatureMessageFragment)
+	}
+
+	auditPathTrytesLen := v.merkleTreeDepth * consts.HashTrytesSize
+	hashTrytesLen := b1t6.EncodedLen(v.hasher.Size()) / consts.TritsPerTryte
+	if auditPathTrytesLen+hashTrytesLen > len(siblingsTx.SignatureMessageFragment) {
+		return 0, nil, fmt.Errorf("%w: invalid audit path length", ErrInvalidMilestone)
+	}
+
+	var path []trinary.Trytes
+	for i := 0; i < v.merkleTreeDepth; i++ {
+		path = append(path, siblingsTx.SignatureMessageFragment[i*consts.HashTrytesSize:(i+1)*consts.HashTrytesSize])
+	}
+
+	// verify milestone signature
+	if valid, err := merkle.ValidateSignatureFragments(v.coordinatorAddress, uint32(msIndex), path, fragments, siblingsTx.Hash); !valid {
+		if err != nil {
+			return 0, nil, fmt.Errorf("%w: %v", ErrInvalidMilestone, err)
+		}
+		return 0, nil, fmt.Errorf("%w: signature was not valid, hash: %v", ErrInvalidMilestone, txs[0].Hash)
+	}
+
+	merkleTreeHash, err := b1t6.DecodeTrytes(siblingsTx.SignatureMessageFragment[auditPathTrytesLen : auditPathTrytesLen+hashTrytesLen])
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: invalid Merkle tree hash: %v", ErrInvalidMilestone, err)
+	}
+
+	return msIndex, merkleTreeHash, nil
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"crypto"
+	"errors"
+	"testing"
+
+	_ "golang.org/x/crypto/blake2b"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/inclusionproof"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/testsuite/utils"
+)
+
+const (
+	seed1 = "JBN9ZRCOH9YRUGSWIQNZWAIFEZUBDUGTFPVRKXWPAUCEQQFS9NHPQLXCKZKRHVCCUZNF9CZZWKXRZVCWZ"
+	seed2 = "JBNAZRCOH9YRUGSWIQNZWAIFEZUBDUGTFPVRKXWPAUCEQQFS9NHPQLXCKZKRHVCCUZNF9CZZWKXRZVCWZ"
+
+	// the coordinator of the testsuite
+	cooAddress      = "WZZQHXUDONRBBIUBCNGNCULQWMLHW9VWEESGFTMWVDVGDTO9EBFGSQXNYPAAFUOI9WIGALDNTSSGNW9ZC"
+	cooSecLevel     = int(consts.SecurityLevelMedium)
+	merkleTreeDepth = 10
+	merkleHashFunc  = crypto.BLAKE2b_512
+
+	showConfirmationGraphs = false
+)
+
+// creates the proof for the given included tail.
+func createProof(t *testing.T, msBundle *tangle.Bundle, tailHash hornet.Hash) *inclusionproof.Proof {
+
+	conf, err := tangle.GetMilestoneConfirmation(msBundle.GetMilestoneIndex())
+	require.NoError(t, err)
+	require.NotNil(t, conf)
+
+	proof, err := inclusionproof.CreateProof(conf, tailHash, merkleHashFunc)
+	require.NoError(t, err)
+
+	return proof
+}
+
+func TestInclusionProof(t *testing.T) {
+
+	// Fill up the balances
+	balances := make(map[string]uint64)
+	balances[string(utils.GenerateAddress(t, seed1, 0))] = 1000
+
+	te := testsuite.SetupTestEnvironment(t, balances, 3, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	// Valid transfer 100 from seed1[0] to seed2[0]
+	bundleA := te.AttachAndStoreBundle(te.Milestones[0].GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ValueTx(t, "A", seed1, 0, 1000, seed2, 0, 100))
+	// Valid transfer 50 from seed2[0] to seed1[3]
+	bundleB := te.AttachAndStoreBundle(bundleA.GetBundle().GetTailHash(), te.Milestones[2].GetBundle().GetTailHash(), utils.ValueTx(t, "B", seed2, 0, 100, seed1, 3, 50))
+
+	te.IssueAndConfirmMilestoneOnTip(bundleB.GetBundle().GetTailHash(), false)
+	ms := te.Milestones[len(te.Milestones)-1].GetBundle()
+
+	verifier := inclusionproof.NewVerifier(cooAddress, cooSecLevel, merkleTreeDepth, merkleHashFunc)
+
+	includedTails := hornet.Hashes{bundleA.GetBundle().GetTailHash(), bundleB.GetBundle().GetTailHash()}
+	for _, tailHash := range includedTails {
+		msIndex, err := verifier.Verify(createProof(t, ms, tailHash))
+		require.NoError(t, err)
+		require.Equal(t, ms.GetMilestoneIndex(), msIndex)
+	}
+
+	// the proof of another tail is not valid
+	proof := createProof(t, ms, bundleA.GetBundle().GetTailHash())
+	proof.TailTransaction = bundleB.GetBundle().GetTailHash().Trytes()
+	_, err := verifier.Verify(proof)
+	require.True(t, errors.Is(err, inclusionproof.ErrInvalidAuditPath))
+
+	// no proof is created for a tail which was not included
+	conf, err := tangle.GetMilestoneConfirmation(ms.GetMilestoneIndex())
+	require.NoError(t, err)
+	_, err = inclusionproof.CreateProof(conf, te.Milestones[0].GetBundle().GetTailHash(), merkleHashFunc)
+	require.True(t, errors.Is(err, inclusionproof.ErrTailNotIncluded))
+
+	// a proof against the milestone of another coordinator is not valid
+	proof = createProof(t, ms, bundleA.GetBundle().GetTailHash())
+	otherVerifier := inclusionproof.NewVerifier(utils.GenerateAddress(t, seed1, 0).Trytes(), cooSecLevel, merkleTreeDepth, merkleHashFunc)
+	_, err = otherVerifier.Verify(proof)
+	require.True(t, errors.Is(err, inclusionproof.ErrInvalidMilestone))
+
+	// a manipulated Merkle tree hash in the milestone invalidates the signature
+	proof = createProof(t, ms, bundleA.GetBundle().GetTailHash())
+	headTrytes := []byte(proof.MilestoneTrytes[cooSecLevel])
+	offset := merkleTreeDepth * consts.HashTrytesSize
+	if headTrytes[offset] == 'A' {
+		headTrytes[offset] = 'B'
+	} else {
+		headTrytes[offset] = 'A'
+	}
+	proof.MilestoneTrytes[cooSecLevel] = trinary.Trytes(headTrytes)
+	_, err = verifier.Verify(proof)
+	require.True(t, errors.Is(err, inclusionproof.ErrInvalidMilestone))
+}
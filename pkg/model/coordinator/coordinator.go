@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/iotaledger/iota.go/merkle"
 	"github.com/iotaledger/iota.go/trinary"
 
+	"github.com/gohornet/hornet/pkg/model/coordinator/signer"
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
@@ -49,7 +51,7 @@ type Coordinator struct {
 	milestoneLock syncutils.Mutex
 
 	// config options
-	seed                    trinary.Hash
+	signer                  signer.Signer
 	securityLvl             consts.SecurityLevel
 	merkleTreeDepth         int
 	minWeightMagnitude      int
@@ -64,6 +66,10 @@ type Coordinator struct {
 	merkleTree   *merkle.MerkleTree
 	bootstrapped bool
 
+	// the lease which decides which coordinator instance is active, nil if only a single instance is running
+	lease       Lease
+	leaseHolder string
+
 	// events of the coordinator
 	Events *CoordinatorEvents
 }
@@ -93,9 +99,9 @@ func MilestoneMerkleTreeHashFuncWithName(name string) crypto.Hash {
 }
 
 // New creates a new coordinator instance.
-func New(seed trinary.Hash, securityLvl consts.SecurityLevel, merkleTreeDepth int, minWeightMagnitude int, stateFilePath string, milestoneIntervalSec int, powHandler *pow.Handler, sendBundleFunc SendBundleFunc, milestoneMerkleHashFunc crypto.Hash) *Coordinator {
+func New(milestoneSigner signer.Signer, securityLvl consts.SecurityLevel, merkleTreeDepth int, minWeightMagnitude int, stateFilePath string, milestoneIntervalSec int, powHandler *pow.Handler, sendBundleFunc SendBundleFunc, milestoneMerkleHashFunc crypto.Hash) *Coordinator {
 	result := &Coordinator{
-		seed:                    seed,
+		signer:                  milestoneSigner,
 		securityLvl:             securityLvl,
 		merkleTreeDepth:         merkleTreeDepth,
 		minWeightMagnitude:      minWeightMagnitude,
@@ -130,6 +136,10 @@ func (coo *Coordinator) InitMerkleTree(filePath string, cooAddress trinary.Hash)
 		return fmt.Errorf("coordinator address does not match Merkle tree root: %v != %v", cooAddress, coo.merkleTree.Root)
 	}
 
+	if coo.merkleTree.Depth != coo.merkleTreeDepth {
+		return fmt.Errorf("Merkle tree depth does not match the configured depth: %d != %d", coo.merkleTree.Depth, coo.merkleTreeDepth)
+	}
+
 	return nil
 }
 
@@ -187,7 +197,7 @@ func (coo *Coordinator) InitState(bootstrap bool, startIndex milestone.Index) er
 		return fmt.Errorf("state file not found: %v", coo.stateFilePath)
 	}
 
-	coo.state, err = loadStateFile(coo.stateFilePath)
+	coo.state, err = LoadStateFile(coo.stateFilePath)
 	if err != nil {
 		return err
 	}
@@ -206,6 +216,146 @@ func (coo *Coordinator) InitState(bootstrap bool, startIndex milestone.Index) er
 	return nil
 }
 
+// CheckSigner makes sure that the signer can be reached and didn't hand out the Merkle key of the next milestone yet,
+// which happens if the state file is outdated.
+func (coo *Coordinator) CheckSigner() error {
+	lastKeyIndex, err := coo.signer.LastKeyIndex()
+	if err != nil {
+		return fmt.Errorf("failed to query the signer: %w", err)
+	}
+
+	if milestone.Index(lastKeyIndex) > coo.state.LatestMilestoneIndex {
+		return fmt.Errorf("the signer already used the Merkle key of milestone %d, but the latest milestone is %d", lastKeyIndex, coo.state.LatestMilestoneIndex)
+	}
+
+	return nil
+}
+
+// CheckMerkleKeys makes sure that the Merkle tree has keys left to sign the next milestone.
+func (coo *Coordinator) CheckMerkleKeys() error {
+	coo.milestoneLock.Lock()
+	defer coo.milestoneLock.Unlock()
+
+	if usage := coo.merkleKeyUsage(); usage.Remaining == 0 {
+		return fmt.Errorf("%w: latest milestone %d, capacity of the Merkle tree %d", ErrMerkleTreeExhausted, coo.state.LatestMilestoneIndex, usage.Capacity)
+	}
+	return nil
+}
+
+// InitStateFromDatabase creates the state from the latest milestone in the database.
+// This is used by standby instances, which follow the milestones of the active instance.
+func (coo *Coordinator) InitStateFromDatabase() error {
+
+	latestMilestoneFromDatabase := tangle.SearchLatestMilestoneIndexInStore()
+	if latestMilestoneFromDatabase == 0 {
+		return errors.New("no milestone found in database, the network has to be bootstrapped by the active coordinator instance first")
+	}
+
+	coo.state = &State{}
+	if err := coo.applyMilestone(latestMilestoneFromDatabase); err != nil {
+		return err
+	}
+
+	coo.bootstrapped = true
+	return nil
+}
+
+// SetLease sets the lease which decides which of several coordinator instances is active.
+// Every milestone index is recorded in the lease before it is signed.
+func (coo *Coordinator) SetLease(lease Lease, holder string) {
+	coo.lease = lease
+	coo.leaseHolder = holder
+}
+
+// ApplyMilestone updates the state with the given milestone issued by the active coordinator instance,
+// so that a standby instance can continue with the next milestone index after a takeover.
+// Milestones which are not newer than the current state are ignored.
+func (coo *Coordinator) ApplyMilestone(index milestone.Index) error {
+
+	coo.milestoneLock.Lock()
+	defer coo.milestoneLock.Unlock()
+
+	if index <= coo.state.LatestMilestoneIndex {
+		return nil
+	}
+
+	if err := coo.applyMilestone(index); err != nil {
+		return err
+	}
+
+	coo.bootstrapped = true
+	return coo.state.storeStateFile(coo.stateFilePath)
+}
+
+// updates the state with the milestone with the given index from the database.
+func (coo *Coordinator) applyMilestone(index milestone.Index) error {
+
+	cachedBndl := tangle.GetMilestoneOrNil(index) // bundle +1
+	if cachedBndl == nil {
+		return fmt.Errorf("milestone (%d) not found in database", index)
+	}
+	defer cachedBndl.Release(true) // bundle -1
+
+	bndl := cachedBndl.GetBundle()
+
+	cachedTailTx := bndl.GetTail()   // tx +1
+	defer cachedTailTx.Release(true) // tx -1
+
+	coo.state.LatestMilestoneIndex = index
+	coo.state.LatestMilestoneHash = bndl.GetTailHash()
+	// the timestamp of the tail is used like when issuing the milestone, not the attachment timestamp
+	coo.state.LatestMilestoneTime = int64(cachedTailTx.GetTransaction().Tx.Timestamp)
+	coo.state.LatestMilestoneTransactions = milestoneTxHashes(bndl)
+
+	return nil
+}
+
+// returns the transaction hashes of the milestone bundle ordered by their index in the bundle,
+// like they are stored in the state after issuing a milestone.
+func milestoneTxHashes(bndl *tangle.Bundle) hornet.Hashes {
+
+	cachedTxs := bndl.GetTransactions() // tx +1
+	defer cachedTxs.Release(true)       // tx -1
+
+	sort.Slice(cachedTxs, func(i, j int) bool {
+		return cachedTxs[i].GetTransaction().Tx.CurrentIndex < cachedTxs[j].GetTransaction().Tx.CurrentIndex
+	})
+
+	txHashes := make(hornet.Hashes, 0, len(cachedTxs))
+	for _, cachedTx := range cachedTxs {
+		txHashes = append(txHashes, cachedTx.GetTransaction().GetTxHash())
+	}
+
+	return txHashes
+}
+
+// records the given milestone index in the lease before it is signed.
+func (coo *Coordinator) recordSigning(index milestone.Index) error {
+	if coo.lease == nil {
+		return nil
+	}
+	return coo.lease.RecordSigning(coo.leaseHolder, index)
+}
+
+// leaseSigner records the milestone index in the lease right before it is signed,
+// so that failures before the signing don't make the other instances wait for a milestone which can't exist.
+type leaseSigner struct {
+	signer.Signer
+	coo *Coordinator
+}
+
+func (s *leaseSigner) Sign(keyIndex uint32, hash trinary.Hash) ([]trinary.Trytes, error) {
+	if err := s.coo.recordSigning(milestone.Index(keyIndex)); err != nil {
+		return nil, err
+	}
+	return s.Signer.Sign(keyIndex, hash)
+}
+
+// IsLeaseError tells whether the given error was caused by the lease, in which case the instance has to switch to standby.
+func IsLeaseError(err error) bool {
+	return errors.Is(err, ErrLeaseLost) || errors.Is(err, ErrMilestoneAlreadySigned) || errors.Is(err, ErrLeaseUpdateConflict)
+}
+
 // createAndSendMilestone creates a milestone, sends it to the network and stores a new coordinator state file.
 func (coo *Coordinator) createAndSendMilestone(trunkHash hornet.Hash, branchHash hornet.Hash, newMilestoneIndex milestone.Index) error {
 
@@ -232,7 +382,7 @@ func (coo *Coordinator) createAndSendMilestone(trunkHash hornet.Hash, branchHash
 		return fmt.Errorf("failed to compute muations: %w", err)
 	}
 
-	b, err := createMilestone(coo.seed, newMilestoneIndex, coo.securityLvl, trunkHash, branchHash, coo.minWeightMagnitude, coo.merkleTree, mutations.MerkleTreeHash, coo.powHandler)
+	b, err := createMilestone(&leaseSigner{Signer: coo.signer, coo: coo}, newMilestoneIndex, coo.securityLvl, trunkHash, branchHash, coo.minWeightMagnitude, coo.merkleTree, mutations.MerkleTreeHash, coo.powHandler)
 	if err != nil {
 		return fmt.Errorf("failed to create: %w", err)
 	}
@@ -273,6 +423,10 @@ func (coo *Coordinator) Bootstrap() (hornet.Hash, error) {
 	defer coo.milestoneLock.Unlock()
 
 	if !coo.bootstrapped {
+		if coo.merkleKeyUsage().Exceeded(coo.state.LatestMilestoneIndex + 1) {
+			return nil, ErrMerkleTreeExhausted
+		}
+
 		// create first milestone to bootstrap the network
 		// trunk and branch reference the last known milestone or NullHash if startIndex = 1 (see InitState)
 		if err := coo.createAndSendMilestone(coo.state.LatestMilestoneHash, coo.state.LatestMilestoneHash, coo.state.LatestMilestoneIndex+1); err != nil {
@@ -333,7 +487,17 @@ func (coo *Coordinator) IssueMilestone(trunkHash hornet.Hash, branchHash hornet.
 		return nil, tangle.ErrNodeNotSynced, nil
 	}
 
+	if coo.merkleKeyUsage().Exceeded(coo.state.LatestMilestoneIndex + 1) {
+		// return a non-critical error, the coordinator can't sign milestones anymore, but the node keeps running
+		return nil, ErrMerkleTreeExhausted, nil
+	}
+
 	if err := coo.createAndSendMilestone(trunkHash, branchHash, coo.state.LatestMilestoneIndex+1); err != nil {
+		if IsLeaseError(err) {
+			// return a non-critical error, so that the instance can switch to standby if the lease was lost
+			return nil, err, nil
+		}
+
 		// creating milestone failed => critical error
 		return nil, nil, err
 	}
@@ -350,3 +514,20 @@ func (coo *Coordinator) GetInterval() time.Duration {
 func (coo *Coordinator) State() *State {
 	return coo.state
 }
+
+// MerkleKeyUsage returns how many keys of the Merkle tree were used and how many remain.
+// Returns nil if the state wasn't initialized yet.
+func (coo *Coordinator) MerkleKeyUsage() *MerkleKeyUsage {
+	coo.milestoneLock.Lock()
+	defer coo.milestoneLock.Unlock()
+
+	if coo.state == nil {
+		return nil
+	}
+	return coo.merkleKeyUsage()
+}
+
+// returns how many keys of the Merkle tree were used and how many remain. the caller must hold the milestoneLock.
+func (coo *Coordinator) merkleKeyUsage() *MerkleKeyUsage {
+	return NewMerkleKeyUsage(coo.merkleTreeDepth, coo.state.LatestMilestoneIndex)
+}
@@ -0,0 +1,79 @@
+package coordinator
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+var (
+	// ErrMerkleTreeExhausted is returned when all keys of the Merkle tree of the coordinator were used.
+	ErrMerkleTreeExhausted = errors.New("all keys of the Merkle tree were used")
+)
+
+// MerkleKeyUsage describes how many keys of the Merkle tree of the coordinator were used and how many remain.
+type MerkleKeyUsage struct {
+	// The depth of the Merkle tree.
+	Depth int `json:"depth"`
+	// The amount of keys which can be used to sign milestones.
+	// The key with index 0 is never used, because milestone indexes start at 1.
+	Capacity uint32 `json:"capacity"`
+	// The amount of keys which were used.
+	Used uint32 `json:"used"`
+	// The amount of keys which remain.
+	Remaining uint32 `json:"remaining"`
+}
+
+// NewMerkleKeyUsage computes the key usage of a Merkle tree with the given depth,
+// after the milestone with the given index was signed.
+func NewMerkleKeyUsage(merkleTreeDepth int, latestMilestoneIndex milestone.Index) *MerkleKeyUsage {
+	capacity := uint32(1<<uint(merkleTreeDepth)) - 1
+
+	used := uint32(latestMilestoneIndex)
+	if used > capacity {
+		used = capacity
+	}
+
+	return &MerkleKeyUsage{
+		Depth:     merkleTreeDepth,
+		Capacity:  capacity,
+		Used:      used,
+		Remaining: capacity - used,
+	}
+}
+
+// Exceeded tells whether the given milestone index can't be signed with a key of the Merkle tree.
+func (u *MerkleKeyUsage) Exceeded(index milestone.Index) bool {
+	return uint32(index) > u.Capacity
+}
+
+// RemainingPercentage returns the percentage of the keys which remain.
+func (u *MerkleKeyUsage) RemainingPercentage() float64 {
+	if u.Capacity == 0 {
+		return 0
+	}
+	return float64(u.Remaining) * 100 / float64(u.Capacity)
+}
+
+// TimeLeft estimates the time until all keys are used if a milestone is issued in the given interval.
+func (u *MerkleKeyUsage) TimeLeft(interval time.Duration) time.Duration {
+	return time.Duration(u.Remaining) * interval
+}
+
+// LowestReachedThreshold returns the lowest of the given thresholds (in percent of remaining keys),
+// which is reached by the key usage. Returns false if no threshold was reached.
+func (u *MerkleKeyUsage) LowestReachedThreshold(thresholds []float64) (float64, bool) {
+	sorted := make([]float64, len(thresholds))
+	copy(sorted, thresholds)
+	sort.Float64s(sorted)
+
+	remaining := u.RemainingPercentage()
+	for _, threshold := range sorted {
+		if remaining <= threshold {
+			return threshold, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,39 @@
+package coordinator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleKeyUsage(t *testing.T) {
+	usage := NewMerkleKeyUsage(4, 0)
+	require.EqualValues(t, 15, usage.Capacity)
+	require.EqualValues(t, 0, usage.Used)
+	require.EqualValues(t, 15, usage.Remaining)
+	require.False(t, usage.Exceeded(15))
+	require.True(t, usage.Exceeded(16))
+
+	_, reached := usage.LowestReachedThreshold([]float64{25, 10})
+	require.False(t, reached)
+
+	usage = NewMerkleKeyUsage(4, 12)
+	require.EqualValues(t, 12, usage.Used)
+	require.EqualValues(t, 3, usage.Remaining)
+	require.Equal(t, 30*time.Second, usage.TimeLeft(10*time.Second))
+
+	// 20% remaining
+	threshold, reached := usage.LowestReachedThreshold([]float64{10, 25, 50})
+	require.True(t, reached)
+	require.EqualValues(t, 25, threshold)
+
+	// the state can't be beyond the capacity, but the usage doesn't overflow
+	usage = NewMerkleKeyUsage(4, 20)
+	require.EqualValues(t, 15, usage.Used)
+	require.EqualValues(t, 0, usage.Remaining)
+
+	threshold, reached = usage.LowestReachedThreshold([]float64{10, 25, 50})
+	require.True(t, reached)
+	require.EqualValues(t, 10, threshold)
+}
@@ -0,0 +1,333 @@
+package coordinator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+const (
+	// the interval in which a conflicting update of the lease is retried.
+	leaseUpdateRetryInterval = 10 * time.Millisecond
+	// the maximum time to retry conflicting updates of the lease.
+	leaseUpdateTimeout = 2 * time.Second
+	// the amount of versions of the lease record which are kept. a removed version could be written again
+	// by an instance which read an outdated version, which is detected as long as the newer versions are kept.
+	leaseKeptVersions = 10
+)
+
+var (
+	// ErrLeaseHeld is returned when the lease is held by another coordinator instance.
+	ErrLeaseHeld = errors.New("lease is held by another coordinator instance")
+	// ErrLeaseLost is returned when a coordinator instance doesn't hold the lease anymore.
+	ErrLeaseLost = errors.New("lease lost")
+	// ErrMilestoneAlreadySigned is returned when a milestone index should be signed which was already signed.
+	ErrMilestoneAlreadySigned = errors.New("milestone index already signed")
+	// ErrLeaseUpdateConflict is returned when the lease couldn't be updated in time, because other instances updated it concurrently.
+	ErrLeaseUpdateConflict = errors.New("lease was updated concurrently")
+	// ErrSigningNotRecorded is returned when a signing should be cleared which isn't the last recorded one.
+	ErrSigningNotRecorded = errors.New("milestone index is not the last signed one")
+	// errLeaseVersionExists is returned when another instance already wrote the version of the lease record.
+	errLeaseVersionExists = errors.New("lease version already exists")
+)
+
+// LeaseRecord is the state of the lease which decides which coordinator instance is active.
+type LeaseRecord struct {
+	// The ID of the coordinator instance which holds the lease.
+	Holder string `json:"holder"`
+	// The term is increased every time the lease changes its holder.
+	Term uint64 `json:"term"`
+	// The unix time in milliseconds at which the lease expires.
+	ExpiresAt int64 `json:"expiresAt"`
+	// The index of the last milestone which was signed by any holder of the lease.
+	LastSignedIndex milestone.Index `json:"lastSignedIndex"`
+}
+
+// Lease decides which of several coordinator instances is the active one.
+// The lease keeps track of the last signed milestone index, so a new holder can make sure
+// it doesn't sign a milestone index again, which was already signed by the previous holder.
+type Lease interface {
+	// Acquire acquires the lease for the given holder, or renews it if the holder already holds it.
+	// Returns ErrLeaseHeld if another holder holds a lease which didn't expire yet.
+	Acquire(holder string) (*LeaseRecord, error)
+	// RecordSigning records that the given holder is about to sign the milestone with the given index.
+	// Returns ErrLeaseLost if the holder doesn't hold the lease anymore and ErrMilestoneAlreadySigned
+	// if the milestone index was already signed.
+	RecordSigning(holder string, index milestone.Index) error
+	// Release releases the lease if the given holder holds it.
+	Release(holder string) error
+	// ClearSigning removes the record of the given last signed milestone index, if the milestone was never issued.
+	// Returns ErrSigningNotRecorded if the given index is not the last signed milestone index.
+	ClearSigning(index milestone.Index) error
+}
+
+// NewFileLease creates a new lease which is stored in versioned files next to the given file path.
+// All coordinator instances have to use the same file path, e.g. on a shared file system.
+func NewFileLease(filePath string, duration time.Duration) *FileLease {
+	return &FileLease{filePath: filePath, duration: duration}
+}
+
+// FileLease is a lease which is stored in a file per version of the lease record.
+// Every update writes the next version by hard linking a temporary file to the versioned file path,
+// which fails if another instance already wrote that version. Unlike exclusively creating a file,
+// hard linking is atomic on network file systems too, and no lock has to be taken over from crashed instances.
+type FileLease struct {
+	filePath string
+	duration time.Duration
+}
+
+// Acquire acquires or renews the lease for the given holder.
+func (l *FileLease) Acquire(holder string) (*LeaseRecord, error) {
+	var result *LeaseRecord
+	err := l.update(func(record *LeaseRecord, now time.Time) error {
+		if record.Holder != holder {
+			if record.Holder != "" && now.Before(expiryTime(record)) {
+				return fmt.Errorf("%w: %s", ErrLeaseHeld, record.Holder)
+			}
+			record.Holder = holder
+			record.Term++
+		}
+		record.ExpiresAt = now.Add(l.duration).UnixNano() / int64(time.Millisecond)
+
+		recordCopy := *record
+		result = &recordCopy
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RecordSigning records the milestone index which is about to be signed by the given holder.
+func (l *FileLease) RecordSigning(holder string, index milestone.Index) error {
+	return l.update(func(record *LeaseRecord, now time.Time) error {
+		if record.Holder != holder || !now.Before(expiryTime(record)) {
+			return ErrLeaseLost
+		}
+		if index <= record.LastSignedIndex {
+			return fmt.Errorf("%w: %d", ErrMilestoneAlreadySigned, index)
+		}
+		record.LastSignedIndex = index
+		return nil
+	})
+}
+
+// Release releases the lease if the given holder holds it.
+func (l *FileLease) Release(holder string) error {
+	return l.update(func(record *LeaseRecord, now time.Time) error {
+		if record.Holder != holder {
+			return nil
+		}
+		record.ExpiresAt = 0
+		return nil
+	})
+}
+
+// ClearSigning removes the record of the given last signed milestone index.
+// This has to be done by the operator if an instance failed to issue the milestone after recording it,
+// because the other instances wait for that milestone before they become active.
+func (l *FileLease) ClearSigning(index milestone.Index) error {
+	return l.update(func(record *LeaseRecord, now time.Time) error {
+		if index == 0 || record.LastSignedIndex != index {
+			return fmt.Errorf("%w: %d, last signed %d", ErrSigningNotRecorded, index, record.LastSignedIndex)
+		}
+		record.LastSignedIndex = index - 1
+		return nil
+	})
+}
+
+// Record returns the current state of the lease.
+func (l *FileLease) Record() (*LeaseRecord, error) {
+	_, record, err := l.read()
+	return record, err
+}
+
+// returns the time at which the given lease record expires.
+func expiryTime(record *LeaseRecord) time.Time {
+	return time.Unix(0, record.ExpiresAt*int64(time.Millisecond))
+}
+
+// returns the file path of the given version of the lease record.
+func (l *FileLease) versionFilePath(version uint64) string {
+	return fmt.Sprintf("%s.%d", l.filePath, version)
+}
+
+// returns the existing versions of the lease record.
+func (l *FileLease) versions() ([]uint64, error) {
+	files, err := ioutil.ReadDir(filepath.Dir(l.filePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	prefix := filepath.Base(l.filePath) + "."
+
+	var versions []uint64
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), prefix) {
+			continue
+		}
+		version, err := strconv.ParseUint(strings.TrimPrefix(file.Name(), prefix), 10, 64)
+		if err != nil {
+			// temporary files of an update
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// returns the latest version of the lease record.
+func (l *FileLease) latestVersion() (uint64, error) {
+	versions, err := l.versions()
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint64
+	for _, version := range versions {
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
+
+// reads the latest version of the lease record. an empty record with version 0 is returned if the lease wasn't written yet.
+func (l *FileLease) read() (uint64, *LeaseRecord, error) {
+	for {
+		version, err := l.latestVersion()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		record := &LeaseRecord{}
+		if version == 0 {
+			return 0, record, nil
+		}
+
+		data, err := ioutil.ReadFile(l.versionFilePath(version))
+		if err != nil {
+			if os.IsNotExist(err) {
+				// the version was removed by a newer update in the meantime
+				continue
+			}
+			return 0, nil, err
+		}
+
+		if err := json.Unmarshal(data, record); err != nil {
+			return 0, nil, fmt.Errorf("invalid lease file: %w", err)
+		}
+		return version, record, nil
+	}
+}
+
+// writes the given version of the lease record. returns errLeaseVersionExists if the version was already written,
+// or if a newer version exists, which means that the given version is outdated.
+func (l *FileLease) write(version uint64, record *LeaseRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(l.filePath), filepath.Base(l.filePath)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpFilePath := tmpFile.Name()
+	defer os.Remove(tmpFilePath)
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	versionFilePath := l.versionFilePath(version)
+	if err := os.Link(tmpFilePath, versionFilePath); err != nil {
+		// the reply of a successful link can get lost on network file systems, so the result is checked
+		tmpInfo, tmpErr := os.Stat(tmpFilePath)
+		versionInfo, versionErr := os.Stat(versionFilePath)
+		if tmpErr != nil || versionErr != nil || !os.SameFile(tmpInfo, versionInfo) {
+			if os.IsExist(err) {
+				return errLeaseVersionExists
+			}
+			return err
+		}
+	}
+
+	// an instance which read an outdated version could write a version which was already removed again
+	latest, err := l.latestVersion()
+	if err != nil {
+		return err
+	}
+	if latest >= version+leaseKeptVersions {
+		_ = os.Remove(versionFilePath)
+		return errLeaseVersionExists
+	}
+
+	return nil
+}
+
+// removes the versions of the lease record which are older than the kept ones.
+func (l *FileLease) cleanup(version uint64) {
+	versions, err := l.versions()
+	if err != nil {
+		return
+	}
+
+	for _, v := range versions {
+		if v+leaseKeptVersions <= version {
+			_ = os.Remove(l.versionFilePath(v))
+		}
+	}
+}
+
+// modifies the latest version of the lease record and writes the next version.
+// the modification is retried if another instance wrote the next version concurrently.
+// the record is only written if the modification succeeded.
+func (l *FileLease) update(modify func(record *LeaseRecord, now time.Time) error) error {
+	deadline := time.Now().Add(leaseUpdateTimeout)
+
+	for {
+		version, record, err := l.read()
+		if err != nil {
+			return err
+		}
+
+		if err := modify(record, time.Now()); err != nil {
+			return err
+		}
+
+		err = l.write(version+1, record)
+		if err == nil {
+			l.cleanup(version + 1)
+			return nil
+		}
+		if !errors.Is(err, errLeaseVersionExists) {
+			return err
+		}
+
+		if time.Now().After(deadline) {
+			return ErrLeaseUpdateConflict
+		}
+		time.Sleep(leaseUpdateRetryInterval)
+	}
+}
@@ -0,0 +1,169 @@
+package coordinator
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/iota.go/trinary"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+func TestFileLease(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "lease")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	lease := NewFileLease(filepath.Join(tempDir, "coordinator.lease"), 200*time.Millisecond)
+
+	record, err := lease.Acquire("first")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, record.Term)
+
+	// the lease is held by the first instance
+	_, err = lease.Acquire("second")
+	require.True(t, errors.Is(err, ErrLeaseHeld))
+	require.True(t, errors.Is(lease.RecordSigning("second", 1), ErrLeaseLost))
+
+	require.NoError(t, lease.RecordSigning("first", 1))
+	require.NoError(t, lease.RecordSigning("first", 2))
+	require.True(t, errors.Is(lease.RecordSigning("first", 2), ErrMilestoneAlreadySigned))
+
+	// renewing keeps the term
+	record, err = lease.Acquire("first")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, record.Term)
+
+	// the second instance takes over after the lease expired and learns about the last signed milestone
+	time.Sleep(250 * time.Millisecond)
+	require.True(t, errors.Is(lease.RecordSigning("first", 3), ErrLeaseLost))
+
+	record, err = lease.Acquire("second")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, record.Term)
+	require.EqualValues(t, 2, record.LastSignedIndex)
+
+	require.True(t, errors.Is(lease.RecordSigning("second", 2), ErrMilestoneAlreadySigned))
+	require.NoError(t, lease.RecordSigning("second", 3))
+
+	// a released lease can be acquired immediately
+	require.NoError(t, lease.Release("second"))
+	record, err = lease.Acquire("first")
+	require.NoError(t, err)
+	require.EqualValues(t, 3, record.Term)
+	require.EqualValues(t, 3, record.LastSignedIndex)
+}
+
+func TestFileLeaseConcurrentAcquire(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "lease")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	leaseFilePath := filepath.Join(tempDir, "coordinator.lease")
+
+	// every instance uses its own lease instance, like separate processes on a shared file system
+	holders := []string{"first", "second", "third", "fourth"}
+	acquired := make(chan string, len(holders))
+
+	var wg sync.WaitGroup
+	for _, holder := range holders {
+		wg.Add(1)
+		go func(holder string) {
+			defer wg.Done()
+			if _, err := NewFileLease(leaseFilePath, time.Minute).Acquire(holder); err == nil {
+				acquired <- holder
+			}
+		}(holder)
+	}
+	wg.Wait()
+	close(acquired)
+
+	// only a single instance holds the lease
+	require.Len(t, acquired, 1)
+	winner := <-acquired
+
+	record, err := NewFileLease(leaseFilePath, time.Minute).Record()
+	require.NoError(t, err)
+	require.Equal(t, winner, record.Holder)
+	require.EqualValues(t, 1, record.Term)
+
+	// the holder records all milestones, while the others fail to take over the lease
+	lease := NewFileLease(leaseFilePath, time.Minute)
+	for index := milestone.Index(1); index <= 3*leaseKeptVersions; index++ {
+		wg.Add(1)
+		go func(holder string) {
+			defer wg.Done()
+			_, _ = NewFileLease(leaseFilePath, time.Minute).Acquire(holder)
+		}(holders[int(index)%len(holders)])
+
+		require.NoError(t, lease.RecordSigning(winner, index))
+	}
+	wg.Wait()
+
+	record, err = lease.Record()
+	require.NoError(t, err)
+	require.Equal(t, winner, record.Holder)
+	require.EqualValues(t, 3*leaseKeptVersions, record.LastSignedIndex)
+
+	// the old versions of the lease record are removed
+	versions, err := lease.versions()
+	require.NoError(t, err)
+	require.Len(t, versions, leaseKeptVersions)
+}
+
+// failingSigner fails to sign every milestone.
+type failingSigner struct{}
+
+func (failingSigner) LastKeyIndex() (uint32, error) {
+	return 0, nil
+}
+
+func (failingSigner) Sign(uint32, trinary.Hash) ([]trinary.Trytes, error) {
+	return nil, errors.New("signing daemon unavailable")
+}
+
+func TestFileLeaseFailedIssue(t *testing.T) {
+	const hashToSign = "SDQJSCHKPVSGNKESLFWTWBHWOHZHUATIYB9PQGNFJOYDEWZAYCECZZVGJPYNKJUJNHFKPXZOULCEJSXOW"
+
+	tempDir, err := ioutil.TempDir("", "lease")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	lease := NewFileLease(filepath.Join(tempDir, "coordinator.lease"), 200*time.Millisecond)
+
+	_, err = lease.Acquire("first")
+	require.NoError(t, err)
+	require.NoError(t, lease.RecordSigning("first", 1))
+
+	// the milestone index is recorded right before it is signed, but the first instance fails to issue the milestone
+	coo := &Coordinator{lease: lease, leaseHolder: "first"}
+	_, err = (&leaseSigner{Signer: failingSigner{}, coo: coo}).Sign(2, hashToSign)
+	require.Error(t, err)
+	require.False(t, IsLeaseError(err))
+
+	// the second instance takes over, but the milestone it waits for doesn't exist
+	time.Sleep(250 * time.Millisecond)
+	record, err := lease.Acquire("second")
+	require.NoError(t, err)
+	require.EqualValues(t, 2, record.LastSignedIndex)
+
+	// the second instance can't sign the milestone, until the operator cleared the record of it
+	coo.leaseHolder = "second"
+	_, err = (&leaseSigner{Signer: failingSigner{}, coo: coo}).Sign(2, hashToSign)
+	require.True(t, IsLeaseError(err))
+
+	require.True(t, errors.Is(lease.ClearSigning(1), ErrSigningNotRecorded))
+	require.NoError(t, lease.ClearSigning(2))
+
+	record, err = lease.Record()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, record.LastSignedIndex)
+
+	require.NoError(t, lease.RecordSigning("second", 2))
+}
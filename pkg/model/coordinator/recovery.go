@@ -0,0 +1,98 @@
+package coordinator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+var (
+	// ErrNoCoordinatorMilestoneFound is returned when the state should be recovered,
+	// but no milestone of the coordinator was found in the database.
+	ErrNoCoordinatorMilestoneFound = errors.New("no milestone of the coordinator found in database")
+)
+
+// RecoverState rebuilds the state from the latest milestone in the database which was signed by the given
+// coordinator address and overwrites the state file. This is used if the state file was lost or corrupted.
+func (coo *Coordinator) RecoverState(cooAddress trinary.Hash) error {
+
+	var msIndexes []milestone.Index
+	tangle.ForEachMilestoneIndex(func(index milestone.Index) bool {
+		msIndexes = append(msIndexes, index)
+		return true
+	}, false)
+
+	// search from the newest to the oldest milestone
+	sort.Slice(msIndexes, func(i, j int) bool {
+		return msIndexes[i] > msIndexes[j]
+	})
+
+	// the latest milestone known to the node, also if the node wasn't started yet
+	latestIndex := tangle.GetLatestMilestoneIndex()
+	if len(msIndexes) > 0 && msIndexes[0] > latestIndex {
+		latestIndex = msIndexes[0]
+	}
+
+	for _, msIndex := range msIndexes {
+		issued, err := isMilestoneIssuedBy(msIndex, hornet.HashFromAddressTrytes(cooAddress))
+		if err != nil {
+			return err
+		}
+
+		if !issued {
+			continue
+		}
+
+		// the coordinator would sign a milestone index twice if the node knows a newer milestone than the recovered one
+		if latestIndex > msIndex {
+			return fmt.Errorf("the node knows milestone %d, but the latest milestone of the coordinator in the database is %d. sync the node before recovering the state", latestIndex, msIndex)
+		}
+
+		coo.state = &State{}
+		if err := coo.applyMilestone(msIndex); err != nil {
+			return err
+		}
+
+		coo.bootstrapped = true
+		return coo.state.storeStateFile(coo.stateFilePath)
+	}
+
+	return ErrNoCoordinatorMilestoneFound
+}
+
+// isMilestoneIssuedBy checks whether the milestone with the given index was issued by the given coordinator address.
+// The signature of milestones is verified before they are stored in the database,
+// so only the address of the signature transactions has to be checked.
+func isMilestoneIssuedBy(index milestone.Index, cooAddress hornet.Hash) (bool, error) {
+
+	cachedBndl := tangle.GetMilestoneOrNil(index) // bundle +1
+	if cachedBndl == nil {
+		return false, fmt.Errorf("milestone (%d) not found in database", index)
+	}
+	defer cachedBndl.Release(true) // bundle -1
+
+	cachedTxs := cachedBndl.GetBundle().GetTransactions() // tx +1
+	defer cachedTxs.Release(true)                         // tx -1
+
+	for _, cachedTx := range cachedTxs {
+		tx := cachedTx.GetTransaction()
+
+		if tx.Tx.CurrentIndex == tx.Tx.LastIndex {
+			// the last transaction contains the siblings and is not signed
+			continue
+		}
+
+		if !bytes.Equal(tx.GetAddress(), cooAddress) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
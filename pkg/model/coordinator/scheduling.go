@@ -0,0 +1,76 @@
+package coordinator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SchedulingMode defines when the coordinator issues milestones.
+type SchedulingMode string
+
+const (
+	// SchedulingModeFixed issues milestones in a fixed interval.
+	SchedulingModeFixed SchedulingMode = "fixed"
+	// SchedulingModeAdaptive shortens the interval under load and lengthens it when the network is idle.
+	SchedulingModeAdaptive SchedulingMode = "adaptive"
+	// SchedulingModeOnDemand only issues milestones if they are requested.
+	SchedulingModeOnDemand SchedulingMode = "onDemand"
+)
+
+var (
+	// ErrUnknownSchedulingMode is returned for unknown milestone scheduling modes.
+	ErrUnknownSchedulingMode = errors.New("unknown scheduling mode")
+	// ErrInvalidAdaptiveInterval is returned when the bounds of the adaptive interval are invalid.
+	ErrInvalidAdaptiveInterval = errors.New("invalid adaptive interval")
+)
+
+// SchedulingModeWithName returns the scheduling mode with the given name (case insensitive).
+func SchedulingModeWithName(name string) (SchedulingMode, error) {
+	for _, mode := range []SchedulingMode{SchedulingModeFixed, SchedulingModeAdaptive, SchedulingModeOnDemand} {
+		if strings.EqualFold(string(mode), name) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownSchedulingMode, name)
+}
+
+// AdaptiveInterval scales the milestone interval between a minimum and a maximum
+// by the amount of unconfirmed transactions.
+type AdaptiveInterval struct {
+	minInterval          time.Duration
+	maxInterval          time.Duration
+	highLoadTransactions int
+}
+
+// NewAdaptiveInterval creates a new AdaptiveInterval.
+// The maximum interval is used if there are no unconfirmed transactions,
+// the minimum interval is used if there are at least highLoadTransactions unconfirmed transactions.
+func NewAdaptiveInterval(minInterval time.Duration, maxInterval time.Duration, highLoadTransactions int) (*AdaptiveInterval, error) {
+	if minInterval <= 0 || maxInterval < minInterval {
+		return nil, fmt.Errorf("%w: min %v, max %v", ErrInvalidAdaptiveInterval, minInterval, maxInterval)
+	}
+	if highLoadTransactions <= 0 {
+		return nil, fmt.Errorf("%w: high load threshold must be positive: %d", ErrInvalidAdaptiveInterval, highLoadTransactions)
+	}
+
+	return &AdaptiveInterval{
+		minInterval:          minInterval,
+		maxInterval:          maxInterval,
+		highLoadTransactions: highLoadTransactions,
+	}, nil
+}
+
+// Interval returns the milestone interval for the given amount of unconfirmed transactions.
+// The interval decreases linearly from the maximum to the minimum up to the high load threshold.
+func (a *AdaptiveInterval) Interval(unconfirmedTransactions int) time.Duration {
+	if unconfirmedTransactions <= 0 {
+		return a.maxInterval
+	}
+	if unconfirmedTransactions >= a.highLoadTransactions {
+		return a.minInterval
+	}
+
+	return a.maxInterval - (a.maxInterval-a.minInterval)*time.Duration(unconfirmedTransactions)/time.Duration(a.highLoadTransactions)
+}
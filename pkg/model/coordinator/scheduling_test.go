@@ -0,0 +1,47 @@
+package coordinator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulingModeWithName(t *testing.T) {
+	mode, err := SchedulingModeWithName("adaptive")
+	require.NoError(t, err)
+	require.Equal(t, SchedulingModeAdaptive, mode)
+
+	mode, err = SchedulingModeWithName("ondemand")
+	require.NoError(t, err)
+	require.Equal(t, SchedulingModeOnDemand, mode)
+
+	_, err = SchedulingModeWithName("random")
+	require.True(t, errors.Is(err, ErrUnknownSchedulingMode))
+}
+
+func TestAdaptiveInterval(t *testing.T) {
+	_, err := NewAdaptiveInterval(0, 10*time.Second, 100)
+	require.True(t, errors.Is(err, ErrInvalidAdaptiveInterval))
+
+	_, err = NewAdaptiveInterval(20*time.Second, 10*time.Second, 100)
+	require.True(t, errors.Is(err, ErrInvalidAdaptiveInterval))
+
+	_, err = NewAdaptiveInterval(2*time.Second, 10*time.Second, 0)
+	require.True(t, errors.Is(err, ErrInvalidAdaptiveInterval))
+
+	adaptive, err := NewAdaptiveInterval(2*time.Second, 10*time.Second, 100)
+	require.NoError(t, err)
+
+	// idle
+	require.Equal(t, 10*time.Second, adaptive.Interval(0))
+
+	// the interval shrinks linearly with the load
+	require.Equal(t, 6*time.Second, adaptive.Interval(50))
+	require.Equal(t, 8*time.Second, adaptive.Interval(25))
+
+	// high load
+	require.Equal(t, 2*time.Second, adaptive.Interval(100))
+	require.Equal(t, 2*time.Second, adaptive.Interval(5000))
+}
@@ -0,0 +1,207 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/iota.go/trinary"
+)
+
+const (
+	// the name of the signing service of the signing daemon.
+	serviceName = "Signer"
+	// the timeout for connecting to the signing daemon.
+	dialTimeout = 5 * time.Second
+)
+
+var (
+	// ErrSignerUnavailable is returned when the signing daemon can't be reached.
+	ErrSignerUnavailable = errors.New("signing daemon unavailable")
+	// ErrUnixSocketRequired is returned when the signing daemon should serve on another listener than a unix socket.
+	ErrUnixSocketRequired = errors.New("the signing daemon only serves on unix sockets")
+)
+
+// SignRequest is the request to sign a hash sent to the signing daemon.
+type SignRequest struct {
+	KeyIndex uint32
+	Hash     trinary.Hash
+}
+
+// SignResponse is the response of the signing daemon containing the signature fragments.
+type SignResponse struct {
+	Fragments []trinary.Trytes
+}
+
+// service exposes a Signer via RPC.
+type service struct {
+	signer Signer
+}
+
+// the argument is unused, but net/rpc requires one and gob can't encode empty structs.
+func (s *service) LastKeyIndex(_ bool, keyIndex *uint32) error {
+	index, err := s.signer.LastKeyIndex()
+	if err != nil {
+		return err
+	}
+	*keyIndex = index
+	return nil
+}
+
+func (s *service) Sign(req *SignRequest, res *SignResponse) error {
+	fragments, err := s.signer.Sign(req.KeyIndex, req.Hash)
+	if err != nil {
+		return err
+	}
+	res.Fragments = fragments
+	return nil
+}
+
+// NewServer creates a new signing daemon server which signs with the given signer.
+func NewServer(signer Signer) (*Server, error) {
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName(serviceName, &service{signer: signer}); err != nil {
+		return nil, err
+	}
+	return &Server{rpcServer: rpcServer}, nil
+}
+
+// Server is a signing daemon which serves the signatures of a Signer to the coordinator.
+// The RPC protocol is unauthenticated, so the signing daemon only serves on unix sockets,
+// which are protected by the file permissions.
+type Server struct {
+	rpcServer *rpc.Server
+}
+
+// Listen creates the unix socket of the signing daemon at the given path, which only the current user can connect to.
+// An existing socket of a previous run is replaced.
+func Listen(socketPath string) (net.Listener, error) {
+	// the socket is created under a temporary name and only moved to its path once the permissions are restricted,
+	// so there is no time window in which other users could connect.
+	tmpSocketPath := fmt.Sprintf("%s.%d.tmp", socketPath, os.Getpid())
+	if err := os.Remove(tmpSocketPath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmpSocketPath, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	listener.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmpSocketPath, 0600); err != nil {
+		_ = listener.Close()
+		_ = os.Remove(tmpSocketPath)
+		return nil, err
+	}
+
+	if err := os.Rename(tmpSocketPath, socketPath); err != nil {
+		_ = listener.Close()
+		_ = os.Remove(tmpSocketPath)
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+// Serve accepts connections on the given unix socket listener until it is closed.
+func (s *Server) Serve(listener net.Listener) error {
+	if listener.Addr().Network() != "unix" {
+		return ErrUnixSocketRequired
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.rpcServer.ServeConn(conn)
+	}
+}
+
+// NewRemoteSigner creates a new signer which requests the signatures from the signing daemon
+// listening on the unix socket at the given path.
+func NewRemoteSigner(socketPath string) *RemoteSigner {
+	return &RemoteSigner{socketPath: socketPath}
+}
+
+// RemoteSigner requests the signatures from a separate signing daemon, so the seed never lives in the coordinator process.
+type RemoteSigner struct {
+	mu         sync.Mutex
+	socketPath string
+	client     *rpc.Client
+}
+
+// LastKeyIndex returns the last Merkle key index which was handed out by the signing daemon.
+func (s *RemoteSigner) LastKeyIndex() (uint32, error) {
+	var keyIndex uint32
+	if err := s.call(serviceName+".LastKeyIndex", false, &keyIndex); err != nil {
+		return 0, err
+	}
+	return keyIndex, nil
+}
+
+// Sign requests the signature fragments of the given hash created with the key of the given Merkle key index.
+func (s *RemoteSigner) Sign(keyIndex uint32, hash trinary.Hash) ([]trinary.Trytes, error) {
+	res := &SignResponse{}
+	if err := s.call(serviceName+".Sign", &SignRequest{KeyIndex: keyIndex, Hash: hash}, res); err != nil {
+		return nil, err
+	}
+	return res.Fragments, nil
+}
+
+// Close closes the connection to the signing daemon.
+func (s *RemoteSigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Close()
+	s.client = nil
+	return err
+}
+
+// calls the given method of the signing daemon and reconnects once if the connection was lost.
+func (s *RemoteSigner) call(method string, args interface{}, reply interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.client == nil {
+			conn, err := net.DialTimeout("unix", s.socketPath, dialTimeout)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrSignerUnavailable, err)
+			}
+			s.client = rpc.NewClient(conn)
+		}
+
+		err := s.client.Call(method, args, reply)
+		if err == nil {
+			return nil
+		}
+
+		if serverErr, isServerError := err.(rpc.ServerError); isServerError {
+			// errors are transmitted as strings, so the known errors are restored to be able to check them
+			for _, knownErr := range []error{ErrKeyIndexAlreadyUsed, ErrKeyIndexNotNext} {
+				if msg := string(serverErr); strings.HasPrefix(msg, knownErr.Error()) {
+					return fmt.Errorf("%w%s", knownErr, strings.TrimPrefix(msg, knownErr.Error()))
+				}
+			}
+			return serverErr
+		}
+
+		// the connection is broken, reconnect. signing the same hash again with the same key index is allowed,
+		// so it doesn't matter whether the request was already processed by the signing daemon.
+		_ = s.client.Close()
+		s.client = nil
+	}
+
+	return ErrSignerUnavailable
+}
@@ -0,0 +1,162 @@
+package signer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/merkle"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+var (
+	// ErrKeyIndexAlreadyUsed is returned when a Merkle key index was already handed out for another hash.
+	ErrKeyIndexAlreadyUsed = errors.New("Merkle key index already used")
+	// ErrKeyIndexNotNext is returned when a Merkle key index would skip the next unused one.
+	ErrKeyIndexNotNext = errors.New("Merkle key index is not the next one")
+)
+
+// Signer hands out the one-time keys of the Merkle tree of the coordinator and returns the signature fragments
+// created with them, so the coordinator doesn't need to know the seed.
+type Signer interface {
+	// LastKeyIndex returns the last Merkle key index which was handed out, or 0 if none was handed out yet.
+	LastKeyIndex() (uint32, error)
+	// Sign hands out the key with the given Merkle key index and returns the signature fragments of the given hash.
+	// A key index is only handed out once, since signing different hashes with the same one-time key leaks parts of it.
+	Sign(keyIndex uint32, hash trinary.Hash) ([]trinary.Trytes, error)
+}
+
+// NormalizeSeed sets the 243rd trit of the given seed to zero, since it is ignored by the key derivation.
+// Returns whether the seed was modified.
+func NormalizeSeed(seed trinary.Hash) (trinary.Hash, bool) {
+	lastTrits := trinary.MustTrytesToTrits(string(seed[consts.HashTrytesSize-1]))
+	if lastTrits[consts.TritsPerTryte-1] == 0 {
+		return seed, false
+	}
+
+	lastTrits[consts.TritsPerTryte-1] = 0
+	return seed[:consts.HashTrytesSize-1] + trinary.MustTritsToTrytes(lastTrits), true
+}
+
+// keyIndexGuard makes sure that the Merkle key indexes are used in order and each only to sign a single hash.
+// Signing the same hash again is allowed, so that a failed milestone can be re-signed.
+type keyIndexGuard struct {
+	sync.Mutex
+	lastKeyIndex uint32
+	lastHash     trinary.Hash
+	// persists the used key index before the signature is handed out (nil = in-memory only).
+	store func(keyIndex uint32, hash trinary.Hash) error
+}
+
+// checks whether the given key index may be used to sign the given hash. the caller must hold the lock.
+// only the next key index or the last one with the identical hash is accepted. as long as no hash was signed,
+// the first key index may be anywhere after the last one, which marks the position within the Merkle tree.
+func (g *keyIndexGuard) check(keyIndex uint32, hash trinary.Hash) error {
+	switch {
+	case keyIndex == g.lastKeyIndex && g.lastHash != "" && g.lastHash == hash:
+		return nil
+	case keyIndex == g.lastKeyIndex+1:
+		return nil
+	case keyIndex > g.lastKeyIndex && g.lastHash == "":
+		return nil
+	case keyIndex <= g.lastKeyIndex:
+		return fmt.Errorf("%w: %d", ErrKeyIndexAlreadyUsed, keyIndex)
+	default:
+		return fmt.Errorf("%w: %d, last used %d", ErrKeyIndexNotNext, keyIndex, g.lastKeyIndex)
+	}
+}
+
+// marks the given key index as used for the given hash. the caller must hold the lock.
+func (g *keyIndexGuard) use(keyIndex uint32, hash trinary.Hash) error {
+	if g.store != nil {
+		if err := g.store(keyIndex, hash); err != nil {
+			return fmt.Errorf("failed to persist the used key index: %w", err)
+		}
+	}
+
+	g.lastKeyIndex = keyIndex
+	g.lastHash = hash
+	return nil
+}
+
+// the file format of the persisted state of the InProcessSigner.
+type inProcessSignerState struct {
+	LastKeyIndex uint32       `json:"lastKeyIndex"`
+	LastHash     trinary.Hash `json:"lastHash"`
+}
+
+// NewInProcessSigner creates a new signer which derives the keys from the given seed in the coordinator process.
+// The used key indexes are only kept in memory.
+func NewInProcessSigner(seed trinary.Hash, securityLvl consts.SecurityLevel) *InProcessSigner {
+	return &InProcessSigner{seed: seed, securityLvl: securityLvl}
+}
+
+// NewPersistentInProcessSigner creates a new signer which derives the keys from the given seed in the coordinator process.
+// The used key indexes are persisted in the given state file, so keys can't be reused after a restart.
+func NewPersistentInProcessSigner(seed trinary.Hash, securityLvl consts.SecurityLevel, stateFilePath string) (*InProcessSigner, error) {
+	s := NewInProcessSigner(seed, securityLvl)
+
+	data, err := ioutil.ReadFile(stateFilePath)
+	switch {
+	case err == nil:
+		state := &inProcessSignerState{}
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("invalid signer state file: %w", err)
+		}
+		s.guard.lastKeyIndex = state.LastKeyIndex
+		s.guard.lastHash = state.LastHash
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	s.guard.store = func(keyIndex uint32, hash trinary.Hash) error {
+		data, err := json.MarshalIndent(&inProcessSignerState{LastKeyIndex: keyIndex, LastHash: hash}, "", "  ")
+		if err != nil {
+			return err
+		}
+		return utils.WriteFileAtomically(stateFilePath, data, 0600)
+	}
+
+	return s, nil
+}
+
+// InProcessSigner signs with the seed held in memory of the coordinator process.
+type InProcessSigner struct {
+	guard       keyIndexGuard
+	seed        trinary.Hash
+	securityLvl consts.SecurityLevel
+}
+
+// LastKeyIndex returns the last Merkle key index which was handed out.
+func (s *InProcessSigner) LastKeyIndex() (uint32, error) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	return s.guard.lastKeyIndex, nil
+}
+
+// Sign returns the signature fragments of the given hash created with the key of the given Merkle key index.
+func (s *InProcessSigner) Sign(keyIndex uint32, hash trinary.Hash) ([]trinary.Trytes, error) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if err := s.guard.check(keyIndex, hash); err != nil {
+		return nil, err
+	}
+
+	fragments, err := merkle.SignatureFragments(s.seed, keyIndex, s.securityLvl, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.guard.use(keyIndex, hash); err != nil {
+		return nil, err
+	}
+	return fragments, nil
+}
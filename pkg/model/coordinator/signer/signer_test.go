@@ -0,0 +1,182 @@
+package signer
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// the seed and Merkle tree of the coordinator of the testsuite.
+	cooSeed        = "WMC9IZAXFW9WQHSJDFUROTNVZPSCDJAQJCTPPAIDFKHVOGPONPQUGDEGWNLSEPZYXOPKQKGKDDINIVOCY"
+	cooSecLevel    = consts.SecurityLevelMedium
+	merkleTreePath = "../../../testsuite/assets/coordinator.tree"
+
+	hashToSign      = "SDQJSCHKPVSGNKESLFWTWBHWOHZHUATIYB9PQGNFJOYDEWZAYCECZZVGJPYNKJUJNHFKPXZOULCEJSXOW"
+	otherHashToSign = "DXRYJQZMUDDETIECUFYZDVNPLFNCUHOXDUVDHBIGIRHNLHHGEJXCIMYQGDZDDPEBQVXJMWHTVMQYIVSSQ"
+)
+
+// signs with the given signer and validates the signature against the Merkle tree.
+func signAndValidate(t *testing.T, s Signer, tree *merkle.MerkleTree, keyIndex uint32, hash string) {
+	fragments, err := s.Sign(keyIndex, hash)
+	require.NoError(t, err)
+	require.Len(t, fragments, int(cooSecLevel))
+
+	path, err := tree.AuditPath(keyIndex)
+	require.NoError(t, err)
+
+	valid, err := merkle.ValidateSignatureFragments(tree.Root, keyIndex, path, fragments, hash)
+	require.NoError(t, err)
+	require.True(t, valid)
+}
+
+// checks that the signer hands out every key index only once and in order.
+func testKeyIndexReuse(t *testing.T, s Signer, tree *merkle.MerkleTree) {
+	// the first key index marks the position within the Merkle tree
+	signAndValidate(t, s, tree, 3, hashToSign)
+
+	// signing the same hash again is allowed
+	signAndValidate(t, s, tree, 3, hashToSign)
+
+	_, err := s.Sign(3, otherHashToSign)
+	require.True(t, errors.Is(err, ErrKeyIndexAlreadyUsed))
+
+	_, err = s.Sign(2, otherHashToSign)
+	require.True(t, errors.Is(err, ErrKeyIndexAlreadyUsed))
+
+	lastKeyIndex, err := s.LastKeyIndex()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, lastKeyIndex)
+
+	// key indexes can't be skipped
+	_, err = s.Sign(5, otherHashToSign)
+	require.True(t, errors.Is(err, ErrKeyIndexNotNext))
+
+	signAndValidate(t, s, tree, 4, otherHashToSign)
+}
+
+func TestInProcessSigner(t *testing.T) {
+	tree, err := merkle.LoadMerkleTreeFile(merkleTreePath)
+	require.NoError(t, err)
+
+	testKeyIndexReuse(t, NewInProcessSigner(cooSeed, cooSecLevel), tree)
+}
+
+func TestPersistentInProcessSigner(t *testing.T) {
+	tree, err := merkle.LoadMerkleTreeFile(merkleTreePath)
+	require.NoError(t, err)
+
+	tempDir, err := ioutil.TempDir("", "signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	stateFilePath := filepath.Join(tempDir, "coordinator_signer.state")
+
+	s, err := NewPersistentInProcessSigner(cooSeed, cooSecLevel, stateFilePath)
+	require.NoError(t, err)
+	testKeyIndexReuse(t, s, tree)
+
+	// the used key indexes survive a restart
+	s, err = NewPersistentInProcessSigner(cooSeed, cooSecLevel, stateFilePath)
+	require.NoError(t, err)
+
+	lastKeyIndex, err := s.LastKeyIndex()
+	require.NoError(t, err)
+	require.EqualValues(t, 4, lastKeyIndex)
+
+	_, err = s.Sign(4, hashToSign)
+	require.True(t, errors.Is(err, ErrKeyIndexAlreadyUsed))
+
+	_, err = s.Sign(6, hashToSign)
+	require.True(t, errors.Is(err, ErrKeyIndexNotNext))
+
+	signAndValidate(t, s, tree, 5, hashToSign)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	tree, err := merkle.LoadMerkleTreeFile(merkleTreePath)
+	require.NoError(t, err)
+
+	server, err := NewServer(NewInProcessSigner(cooSeed, cooSecLevel))
+	require.NoError(t, err)
+
+	// the unauthenticated RPC protocol isn't served on TCP
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer tcpListener.Close()
+	require.Equal(t, ErrUnixSocketRequired, server.Serve(tcpListener))
+
+	tempDir, err := ioutil.TempDir("", "signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	socketPath := filepath.Join(tempDir, "coordinator_signer.sock")
+	listener, err := Listen(socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+	go server.Serve(listener)
+
+	// only the current user can connect to the socket
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	remoteSigner := NewRemoteSigner(socketPath)
+	defer remoteSigner.Close()
+
+	testKeyIndexReuse(t, remoteSigner, tree)
+
+	// the signer reconnects if the connection was lost
+	require.NoError(t, remoteSigner.Close())
+	signAndValidate(t, remoteSigner, tree, 5, hashToSign)
+
+	_, err = NewRemoteSigner(filepath.Join(tempDir, "missing.sock")).LastKeyIndex()
+	require.True(t, errors.Is(err, ErrSignerUnavailable))
+}
+
+func TestSoftHSM(t *testing.T) {
+	tree, err := merkle.LoadMerkleTreeFile(merkleTreePath)
+	require.NoError(t, err)
+
+	tempDir, err := ioutil.TempDir("", "softhsm")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	tokenPath := filepath.Join(tempDir, "coordinator.token")
+	require.NoError(t, CreateSoftHSMToken(tokenPath, "test", "1234", cooSeed, cooSecLevel))
+	require.True(t, errors.Is(CreateSoftHSMToken(tokenPath, "test", "1234", cooSeed, cooSecLevel), ErrTokenExists))
+
+	_, err = OpenSoftHSM(tokenPath, consts.SecurityLevelHigh)
+	require.True(t, errors.Is(err, ErrSecurityLevelMismatch))
+
+	hsm, err := OpenSoftHSM(tokenPath, cooSecLevel)
+	require.NoError(t, err)
+	require.Equal(t, "test", hsm.Label())
+
+	_, err = hsm.Sign(1, hashToSign)
+	require.Equal(t, ErrNotLoggedIn, err)
+
+	require.Equal(t, ErrInvalidPIN, hsm.Login("4321"))
+	require.NoError(t, hsm.Login("1234"))
+
+	testKeyIndexReuse(t, hsm, tree)
+
+	// the used key indexes survive a restart
+	hsm, err = OpenSoftHSM(tokenPath, cooSecLevel)
+	require.NoError(t, err)
+	require.NoError(t, hsm.Login("1234"))
+
+	lastKeyIndex, err := hsm.LastKeyIndex()
+	require.NoError(t, err)
+	require.EqualValues(t, 4, lastKeyIndex)
+
+	_, err = hsm.Sign(4, hashToSign)
+	require.True(t, errors.Is(err, ErrKeyIndexAlreadyUsed))
+}
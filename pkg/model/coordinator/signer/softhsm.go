@@ -0,0 +1,229 @@
+package signer
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/merkle"
+	"github.com/iotaledger/iota.go/trinary"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+const (
+	// the scrypt parameters used to derive the token key from the PIN.
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	saltLength   = 32
+	nonceLength  = 24
+	tokenKeySize = 32
+)
+
+var (
+	// ErrTokenExists is returned when a software HSM token should be created, but the file already exists.
+	ErrTokenExists = errors.New("software HSM token already exists")
+	// ErrInvalidPIN is returned when the login to a software HSM token failed.
+	ErrInvalidPIN = errors.New("invalid PIN")
+	// ErrNotLoggedIn is returned when a software HSM token is used before logging in.
+	ErrNotLoggedIn = errors.New("not logged in to the software HSM token")
+	// ErrSecurityLevelMismatch is returned when the security level of a software HSM token doesn't match the configured one.
+	ErrSecurityLevelMismatch = errors.New("security level of the software HSM token doesn't match")
+)
+
+// the file format of a software HSM token.
+type softHSMToken struct {
+	Label         string               `json:"label"`
+	SecurityLevel consts.SecurityLevel `json:"securityLevel"`
+	Salt          []byte               `json:"salt"`
+	Nonce         []byte               `json:"nonce"`
+	EncryptedSeed []byte               `json:"encryptedSeed"`
+	LastKeyIndex  uint32               `json:"lastKeyIndex"`
+	LastHash      trinary.Hash         `json:"lastHash"`
+}
+
+// derives the key which encrypts the seed of the token from the given PIN.
+func deriveTokenKey(pin string, salt []byte) (*[tokenKeySize]byte, error) {
+	keyBytes, err := scrypt.Key([]byte(pin), salt, scryptN, scryptR, scryptP, tokenKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	var key [tokenKeySize]byte
+	copy(key[:], keyBytes)
+	return &key, nil
+}
+
+// CreateSoftHSMToken creates a new software HSM token at the given file path,
+// which holds the given seed encrypted with a key derived from the given PIN.
+func CreateSoftHSMToken(filePath string, label string, pin string, seed trinary.Hash, securityLvl consts.SecurityLevel) error {
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrTokenExists, filePath)
+	}
+
+	if !guards.IsTrytesOfExactLength(seed, consts.HashTrytesSize) {
+		return consts.ErrInvalidSeed
+	}
+
+	token := &softHSMToken{
+		Label:         label,
+		SecurityLevel: securityLvl,
+		Salt:          make([]byte, saltLength),
+		Nonce:         make([]byte, nonceLength),
+	}
+
+	if _, err := rand.Read(token.Salt); err != nil {
+		return err
+	}
+	if _, err := rand.Read(token.Nonce); err != nil {
+		return err
+	}
+
+	key, err := deriveTokenKey(pin, token.Salt)
+	if err != nil {
+		return err
+	}
+
+	var nonce [nonceLength]byte
+	copy(nonce[:], token.Nonce)
+	token.EncryptedSeed = secretbox.Seal(nil, []byte(seed), &nonce, key)
+
+	return storeSoftHSMToken(filePath, token)
+}
+
+// writes the token atomically to the given file path, so a crash can't reset the last used key index.
+func storeSoftHSMToken(filePath string, token *softHSMToken) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomically(filePath, data, 0600)
+}
+
+// OpenSoftHSM opens the software HSM token at the given file path and checks that it signs with the given security level.
+// The token has to be unlocked with Login before it can be used to sign.
+func OpenSoftHSM(filePath string, securityLvl consts.SecurityLevel) (*SoftHSM, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &softHSMToken{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, fmt.Errorf("invalid software HSM token: %w", err)
+	}
+
+	if len(token.Nonce) != nonceLength {
+		return nil, errors.New("invalid software HSM token: invalid nonce")
+	}
+
+	if token.SecurityLevel != securityLvl {
+		return nil, fmt.Errorf("%w: token %d, configured %d", ErrSecurityLevelMismatch, token.SecurityLevel, securityLvl)
+	}
+
+	hsm := &SoftHSM{filePath: filePath, token: token}
+	hsm.guard.lastKeyIndex = token.LastKeyIndex
+	hsm.guard.lastHash = token.LastHash
+	hsm.guard.store = hsm.storeKeyIndex
+	return hsm, nil
+}
+
+// SoftHSM is a software stand-in for a PKCS#11 hardware security module.
+// Like a hardware token, it requires a login with a PIN, never exports the seed and keeps
+// a persistent counter of the used Merkle key indexes, so keys can't be reused after a restart.
+type SoftHSM struct {
+	guard    keyIndexGuard
+	filePath string
+	token    *softHSMToken
+	seed     trinary.Hash
+}
+
+// Label returns the label of the token.
+func (hsm *SoftHSM) Label() string {
+	return hsm.token.Label
+}
+
+// Login unlocks the token with the given PIN.
+func (hsm *SoftHSM) Login(pin string) error {
+	key, err := deriveTokenKey(pin, hsm.token.Salt)
+	if err != nil {
+		return err
+	}
+
+	var nonce [nonceLength]byte
+	copy(nonce[:], hsm.token.Nonce)
+
+	seed, ok := secretbox.Open(nil, hsm.token.EncryptedSeed, &nonce, key)
+	if !ok {
+		return ErrInvalidPIN
+	}
+
+	hsm.guard.Lock()
+	defer hsm.guard.Unlock()
+
+	hsm.seed = trinary.Hash(seed)
+	return nil
+}
+
+// Logout locks the token again.
+func (hsm *SoftHSM) Logout() {
+	hsm.guard.Lock()
+	defer hsm.guard.Unlock()
+
+	hsm.seed = ""
+}
+
+// LastKeyIndex returns the last Merkle key index which was handed out by the token.
+func (hsm *SoftHSM) LastKeyIndex() (uint32, error) {
+	hsm.guard.Lock()
+	defer hsm.guard.Unlock()
+
+	return hsm.guard.lastKeyIndex, nil
+}
+
+// Sign returns the signature fragments of the given hash created with the key of the given Merkle key index.
+// The used key index is persisted before the signature is returned.
+func (hsm *SoftHSM) Sign(keyIndex uint32, hash trinary.Hash) ([]trinary.Trytes, error) {
+	hsm.guard.Lock()
+	defer hsm.guard.Unlock()
+
+	if hsm.seed == "" {
+		return nil, ErrNotLoggedIn
+	}
+
+	if err := hsm.guard.check(keyIndex, hash); err != nil {
+		return nil, err
+	}
+
+	fragments, err := merkle.SignatureFragments(hsm.seed, keyIndex, hsm.token.SecurityLevel, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := hsm.guard.use(keyIndex, hash); err != nil {
+		return nil, err
+	}
+	return fragments, nil
+}
+
+// persists the used key index in the token file. the caller must hold the lock of the guard.
+func (hsm *SoftHSM) storeKeyIndex(keyIndex uint32, hash trinary.Hash) error {
+	token := *hsm.token
+	token.LastKeyIndex = keyIndex
+	token.LastHash = hash
+	if err := storeSoftHSMToken(hsm.filePath, &token); err != nil {
+		return err
+	}
+
+	hsm.token = &token
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/utils"
 )
 
 // State stores the latest state of the coordinator.
@@ -79,8 +80,8 @@ func (cs *State) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
-// loadStateFile loads the binary state file and unmarshals it.
-func loadStateFile(filePath string) (*State, error) {
+// LoadStateFile loads the binary state file and unmarshals it.
+func LoadStateFile(filePath string) (*State, error) {
 
 	stateFile, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
 	if err != nil {
@@ -102,6 +103,7 @@ func loadStateFile(filePath string) (*State, error) {
 }
 
 // storeStateFile stores the state file for the coordinator in binary format.
+// The file is replaced atomically, so a crash while storing doesn't corrupt the previous state.
 func (cs *State) storeStateFile(filePath string) error {
 
 	data, err := cs.MarshalBinary()
@@ -109,19 +111,5 @@ func (cs *State) storeStateFile(filePath string) error {
 		return err
 	}
 
-	stateFile, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE, 0660)
-	if err != nil {
-		return err
-	}
-	defer stateFile.Close()
-
-	if _, err := stateFile.Write(data); err != nil {
-		return err
-	}
-
-	if err := stateFile.Sync(); err != nil {
-		return err
-	}
-
-	return nil
+	return utils.WriteFileAtomically(filePath, data, 0660)
 }
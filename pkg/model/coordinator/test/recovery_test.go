@@ -0,0 +1,54 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	_ "golang.org/x/crypto/blake2b"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/coordinator"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/testsuite/utils"
+)
+
+const (
+	cooAddress   = "WZZQHXUDONRBBIUBCNGNCULQWMLHW9VWEESGFTMWVDVGDTO9EBFGSQXNYPAAFUOI9WIGALDNTSSGNW9ZC"
+	otherAddress = "UDYXTZBE9GZGPM9SSQV9LTZNDLJIZMPUVVXYXFYVBLIEUHLSEWFTKZZLXYRHHWVQV9MNNX9KZC9D9UZWZ"
+
+	showConfirmationGraphs = false
+)
+
+func TestRecoverState(t *testing.T) {
+
+	te := testsuite.SetupTestEnvironment(t, make(map[string]uint64), 3, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	expected := *te.CoordinatorState()
+	require.Equal(t, tangle.GetLatestMilestoneIndex(), expected.LatestMilestoneIndex)
+
+	// no milestone was issued by another coordinator
+	err := te.RecoverCoordinatorState(otherAddress)
+	require.True(t, errors.Is(err, coordinator.ErrNoCoordinatorMilestoneFound))
+
+	require.NoError(t, te.RecoverCoordinatorState(cooAddress))
+	require.Equal(t, &expected, te.CoordinatorState())
+
+	// the coordinator continues with the next milestone
+	bundleA := te.AttachAndStoreBundle(te.Milestones[1].GetBundle().GetTailHash(), te.Milestones[2].GetBundle().GetTailHash(), utils.ZeroValueTx(t, "A"))
+	te.IssueAndConfirmMilestoneOnTip(bundleA.GetBundle().GetTailHash(), false)
+	require.Equal(t, expected.LatestMilestoneIndex+1, te.CoordinatorState().LatestMilestoneIndex)
+}
+
+func TestRecoverStateNewerMilestoneKnown(t *testing.T) {
+
+	te := testsuite.SetupTestEnvironment(t, make(map[string]uint64), 3, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	// the node knows a newer milestone than the latest milestone of the coordinator in the database
+	tangle.SetLatestMilestoneIndex(te.CoordinatorState().LatestMilestoneIndex + 1)
+
+	require.Error(t, te.RecoverCoordinatorState(cooAddress))
+}
@@ -19,6 +19,7 @@ const (
 	TransactionMetadataIsHead      = 3
 	TransactionMetadataIsTail      = 4
 	TransactionMetadataIsValue     = 5
+	TransactionMetadataRetained    = 6
 )
 
 type TransactionMetadata struct {
@@ -173,6 +174,25 @@ func (m *TransactionMetadata) SetConflicting(conflicting bool) {
 	}
 }
 
+// IsRetained returns whether the transaction was kept as retained history while its milestone got pruned.
+func (m *TransactionMetadata) IsRetained() bool {
+	m.RLock()
+	defer m.RUnlock()
+
+	return m.metadata.HasBit(TransactionMetadataRetained)
+}
+
+// SetRetained marks the transaction as retained history.
+func (m *TransactionMetadata) SetRetained(retained bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	if retained != m.metadata.HasBit(TransactionMetadataRetained) {
+		m.metadata = m.metadata.ModifyBit(TransactionMetadataRetained, retained)
+		m.SetModified(true)
+	}
+}
+
 func (m *TransactionMetadata) SetRootSnapshotIndexes(yrtsi milestone.Index, ortsi milestone.Index, rtsci milestone.Index) {
 	m.Lock()
 	defer m.Unlock()
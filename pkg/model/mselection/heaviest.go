@@ -22,9 +22,13 @@ var (
 )
 
 // HeaviestSelector implements the heaviest branch selection strategy.
+// The weight of a branch is rated by the tip scorer of the selection strategy first
+// and by the amount of referenced bundles second.
 type HeaviestSelector struct {
 	sync.Mutex
 
+	scorer tipScorer
+
 	minHeaviestBranchUnconfirmedTransactionsThreshold int
 	maxHeaviestBranchTipsPerCheckpoint                int
 	randomTipsPerCheckpoint                           int
@@ -41,7 +45,8 @@ type bundleTail struct {
 }
 
 type bundleTailList struct {
-	tails map[string]*bundleTail
+	tails  map[string]*bundleTail
+	scorer tipScorer
 }
 
 // Len returns the length of the inner tails slice.
@@ -90,7 +95,13 @@ func (il *bundleTailList) removeTip(tip *bundleTail) {
 
 // New creates a new HeaviestSelector instance.
 func New(minHeaviestBranchUnconfirmedTransactionsThreshold int, maxHeaviestBranchTipsPerCheckpoint int, randomTipsPerCheckpoint int, heaviestBranchSelectionDeadline time.Duration) *HeaviestSelector {
+	return newHeaviestSelector(&heaviestScorer{}, minHeaviestBranchUnconfirmedTransactionsThreshold, maxHeaviestBranchTipsPerCheckpoint, randomTipsPerCheckpoint, heaviestBranchSelectionDeadline)
+}
+
+// newHeaviestSelector creates a new HeaviestSelector instance which rates the branches with the given tip scorer.
+func newHeaviestSelector(scorer tipScorer, minHeaviestBranchUnconfirmedTransactionsThreshold int, maxHeaviestBranchTipsPerCheckpoint int, randomTipsPerCheckpoint int, heaviestBranchSelectionDeadline time.Duration) *HeaviestSelector {
 	s := &HeaviestSelector{
+		scorer: scorer,
 		minHeaviestBranchUnconfirmedTransactionsThreshold: minHeaviestBranchUnconfirmedTransactionsThreshold,
 		maxHeaviestBranchTipsPerCheckpoint:                maxHeaviestBranchTipsPerCheckpoint,
 		randomTipsPerCheckpoint:                           randomTipsPerCheckpoint,
@@ -110,10 +121,12 @@ func (s *HeaviestSelector) reset() {
 
 	// create an empty list
 	s.tips = list.New()
+
+	s.scorer.reset()
 }
 
 // selectTip selects a tip to be used for the next checkpoint.
-// it returns a tip, confirming the most transactions in the future cone,
+// it returns the tip with the highest score, confirming the most transactions in the future cone if scores are equal,
 // and the amount of referenced transactions of this tip, that were not referenced by previously chosen tips.
 func (s *HeaviestSelector) selectTip(tipsList *bundleTailList) (*bundleTail, uint, error) {
 
@@ -123,22 +136,26 @@ func (s *HeaviestSelector) selectTip(tipsList *bundleTailList) (*bundleTail, uin
 
 	var best = struct {
 		tips  []*bundleTail
+		score uint
 		count uint
 	}{
 		tips:  []*bundleTail{},
+		score: 0,
 		count: 0,
 	}
 
-	// loop through all tips and find the one with the most referenced transactions
+	// loop through all tips and find the one with the highest score and the most referenced transactions
 	for _, tip := range tipsList.tails {
+		score := tipsList.scorer.score(tip.refs)
 		c := tip.refs.Count()
-		if c > best.count {
+		if score > best.score || (score == best.score && c > best.count) {
 			// tip with heavier branch found
 			best.tips = []*bundleTail{
 				tip,
 			}
+			best.score = score
 			best.count = c
-		} else if c == best.count {
+		} else if score == best.score && c == best.count {
 			// add the tip to the slice of currently best tips
 			best.tips = append(best.tips, tip)
 		}
@@ -259,13 +276,14 @@ func (s *HeaviestSelector) OnNewSolidBundle(bndl *tangle.Bundle) (trackedTailsCo
 		it.refs.InPlaceUnion(branchItem.refs)
 	}
 	s.trackedTails[string(it.hash)] = it
+	s.scorer.track(idx, bndl)
 
 	// update tips
 	s.removeTip(trunkItem)
 	s.removeTip(branchItem)
 	it.tip = s.tips.PushBack(it)
 
-	return s.GetTrackedTailsCount()
+	return len(s.trackedTails)
 }
 
 // removeTip removes the tip item from s.
@@ -287,10 +305,13 @@ func (s *HeaviestSelector) tipsToList() *bundleTailList {
 		tip := e.Value.(*bundleTail)
 		result[string(tip.hash)] = tip
 	}
-	return &bundleTailList{tails: result}
+	return &bundleTailList{tails: result, scorer: s.scorer.clone()}
 }
 
 // GetTrackedTailsCount returns the amount of known bundle tails.
 func (s *HeaviestSelector) GetTrackedTailsCount() (trackedTails int) {
+	s.Lock()
+	defer s.Unlock()
+
 	return len(s.trackedTails)
 }
@@ -0,0 +1,152 @@
+package mselection
+
+import (
+	"sort"
+
+	"github.com/willf/bitset"
+
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+// tipScorer rates the tips of the selector, the tip with the highest score is selected next.
+// tips with the same score are rated by the amount of referenced bundles.
+type tipScorer interface {
+	// track is called for every new tracked bundle with the index of its bit in the bitsets of the referenced bundles.
+	track(idx uint, bndl *tangle.Bundle)
+	// score returns the score of a tip with the given bitset of referenced bundles.
+	score(refs *bitset.BitSet) uint
+	// clone returns a copy of the scorer, which is not modified by newly tracked bundles.
+	clone() tipScorer
+	// reset forgets all tracked bundles.
+	reset()
+}
+
+// heaviestScorer rates all tips the same, so only the amount of referenced bundles counts.
+type heaviestScorer struct{}
+
+func (s *heaviestScorer) track(_ uint, _ *tangle.Bundle) {}
+
+func (s *heaviestScorer) score(_ *bitset.BitSet) uint {
+	return 0
+}
+
+func (s *heaviestScorer) clone() tipScorer {
+	return s
+}
+
+func (s *heaviestScorer) reset() {}
+
+// oldestScorer rates the tips higher the older the oldest referenced bundle is.
+// the bundles are tracked in the order of solidification, so the lowest index is the oldest bundle.
+type oldestScorer struct{}
+
+func (s *oldestScorer) track(_ uint, _ *tangle.Bundle) {}
+
+func (s *oldestScorer) score(refs *bitset.BitSet) uint {
+	oldest, found := refs.NextSet(0)
+	if !found {
+		return 0
+	}
+	return ^uint(0) - oldest
+}
+
+func (s *oldestScorer) clone() tipScorer {
+	return s
+}
+
+func (s *oldestScorer) reset() {}
+
+// valueScorer rates the tips by the amount of referenced value transfers.
+type valueScorer struct {
+	valueBundles *bitset.BitSet
+}
+
+func newValueScorer() *valueScorer {
+	s := &valueScorer{}
+	s.reset()
+	return s
+}
+
+func (s *valueScorer) track(idx uint, bndl *tangle.Bundle) {
+	if !bndl.IsValueSpam() {
+		s.valueBundles.Set(idx)
+	}
+}
+
+func (s *valueScorer) score(refs *bitset.BitSet) uint {
+	return refs.IntersectionCardinality(s.valueBundles)
+}
+
+func (s *valueScorer) clone() tipScorer {
+	return &valueScorer{valueBundles: s.valueBundles.Clone()}
+}
+
+func (s *valueScorer) reset() {
+	s.valueBundles = bitset.New(0)
+}
+
+// inputAddressScorer rates the tips by the amount of distinct input addresses of the referenced bundles.
+// addresses are only used once, so this doesn't identify the issuers of the bundles,
+// e.g. two consecutive transfers of the same seed are spent from different addresses.
+// a bundle is counted by its lexicographically smallest input address, zero value bundles have no input address
+// and are all counted as one.
+type inputAddressScorer struct {
+	inputAddresses map[string]*bitset.BitSet
+}
+
+func newInputAddressScorer() *inputAddressScorer {
+	s := &inputAddressScorer{}
+	s.reset()
+	return s
+}
+
+// returns the input address the given bundle is counted by.
+func bundleInputAddress(bndl *tangle.Bundle) string {
+	var inputs []string
+	for addr, change := range bndl.GetLedgerChanges() {
+		if change < 0 {
+			inputs = append(inputs, addr)
+		}
+	}
+
+	if len(inputs) == 0 {
+		return ""
+	}
+
+	// the ledger changes are a map, so the input addresses are sorted to always choose the same one
+	sort.Strings(inputs)
+	return inputs[0]
+}
+
+func (s *inputAddressScorer) track(idx uint, bndl *tangle.Bundle) {
+	inputAddress := bundleInputAddress(bndl)
+
+	bundles, exists := s.inputAddresses[inputAddress]
+	if !exists {
+		bundles = bitset.New(idx + 1)
+		s.inputAddresses[inputAddress] = bundles
+	}
+	bundles.Set(idx)
+}
+
+func (s *inputAddressScorer) score(refs *bitset.BitSet) uint {
+	var inputAddresses uint
+	for _, bundles := range s.inputAddresses {
+		if refs.IntersectionCardinality(bundles) > 0 {
+			inputAddresses++
+		}
+	}
+	return inputAddresses
+}
+
+func (s *inputAddressScorer) clone() tipScorer {
+	inputAddresses := make(map[string]*bitset.BitSet, len(s.inputAddresses))
+	for inputAddress, bundles := range s.inputAddresses {
+		inputAddresses[inputAddress] = bundles.Clone()
+	}
+	return &inputAddressScorer{inputAddresses: inputAddresses}
+}
+
+func (s *inputAddressScorer) reset() {
+	s.inputAddresses = make(map[string]*bitset.BitSet)
+}
@@ -0,0 +1,53 @@
+package mselection
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+const (
+	// StrategyHeaviest selects the tips which reference the most unconfirmed bundles.
+	StrategyHeaviest = "heaviest"
+	// StrategyOldest selects the tips which reference the oldest unconfirmed bundles.
+	StrategyOldest = "oldest"
+	// StrategyValue selects the tips which reference the most unconfirmed value transfers.
+	StrategyValue = "value"
+	// StrategyInputAddress selects the tips which reference the unconfirmed bundles of the most distinct input addresses.
+	StrategyInputAddress = "inputaddress"
+)
+
+// MilestoneSelector selects the tips which are referenced by the checkpoints and milestones of the coordinator.
+type MilestoneSelector interface {
+	// OnNewSolidBundle adds a new bundle to be processed by the selector.
+	// The bundle must be solid and OnNewSolidBundle must be called in the order of solidification.
+	// The bundle must also not be below max depth.
+	OnNewSolidBundle(bndl *tangle.Bundle) (trackedTailsCount int)
+	// SelectTips selects the tips for the next checkpoint and resets the selector if tips were found.
+	SelectTips(minRequiredTips int) (hornet.Hashes, error)
+	// GetTrackedTailsCount returns the amount of known bundle tails.
+	GetTrackedTailsCount() (trackedTails int)
+}
+
+// NewSelector creates a new milestone selector with the given strategy.
+func NewSelector(strategy string, minUnconfirmedTransactionsThreshold int, maxTipsPerCheckpoint int, randomTipsPerCheckpoint int, selectionDeadline time.Duration) (MilestoneSelector, error) {
+	var scorer tipScorer
+
+	switch strings.ToLower(strategy) {
+	case StrategyHeaviest:
+		scorer = &heaviestScorer{}
+	case StrategyOldest:
+		scorer = &oldestScorer{}
+	case StrategyValue:
+		scorer = newValueScorer()
+	case StrategyInputAddress:
+		scorer = newInputAddressScorer()
+	default:
+		return nil, fmt.Errorf("unknown milestone selection strategy: %s", strategy)
+	}
+
+	return newHeaviestSelector(scorer, minUnconfirmedTransactionsThreshold, maxTipsPerCheckpoint, randomTipsPerCheckpoint, selectionDeadline), nil
+}
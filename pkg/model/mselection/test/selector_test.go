@@ -0,0 +1,118 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	_ "golang.org/x/crypto/blake2b"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/mselection"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/testsuite/utils"
+)
+
+const (
+	seed1 = "JBN9ZRCOH9YRUGSWIQNZWAIFEZUBDUGTFPVRKXWPAUCEQQFS9NHPQLXCKZKRHVCCUZNF9CZZWKXRZVCWZ"
+	seed2 = "JBNAZRCOH9YRUGSWIQNZWAIFEZUBDUGTFPVRKXWPAUCEQQFS9NHPQLXCKZKRHVCCUZNF9CZZWKXRZVCWZ"
+	seed3 = "JBNBZRCOH9YRUGSWIQNZWAIFEZUBDUGTFPVRKXWPAUCEQQFS9NHPQLXCKZKRHVCCUZNF9CZZWKXRZVCWZ"
+	seed4 = "DBNBZRCOH9YRUGSWIQNZWAIFEZUBDUGTFPVRKXWPAUCEQQFS9NHPQLXCKZKRHVCCUZNF9CZZWKXRZVCWZ"
+
+	showConfirmationGraphs = false
+)
+
+// confirms the same tangle with the given milestone selection strategy.
+// every strategy picks a single tip, so the strategies can be compared by the selected branch.
+func testSelectionStrategy(t *testing.T, strategy string, expectedTip string, expectedTxsValue int) {
+
+	balances := make(map[string]uint64)
+	balances[string(utils.GenerateAddress(t, seed1, 0))] = 1000
+	balances[string(utils.GenerateAddress(t, seed3, 0))] = 1000
+
+	te := testsuite.SetupTestEnvironment(t, balances, 3, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	ms0 := te.Milestones[0].GetBundle().GetTailHash()
+	ms1 := te.Milestones[1].GetBundle().GetTailHash()
+	ms2 := te.Milestones[2].GetBundle().GetTailHash()
+
+	bundles := make(map[string]*tangle.CachedBundle)
+	var solidOrder []*tangle.CachedBundle
+	attach := func(name string, trunk *tangle.CachedBundle, branch *tangle.CachedBundle, cachedBndl func(trunk, branch []byte) *tangle.CachedBundle) {
+		trunkHash, branchHash := ms0, ms1
+		if trunk != nil {
+			trunkHash = trunk.GetBundle().GetTailHash()
+		}
+		if branch != nil {
+			branchHash = branch.GetBundle().GetTailHash()
+		}
+		bundles[name] = cachedBndl(trunkHash, branchHash)
+		solidOrder = append(solidOrder, bundles[name])
+	}
+	zeroValue := func(tag string) func(trunk, branch []byte) *tangle.CachedBundle {
+		return func(trunk, branch []byte) *tangle.CachedBundle {
+			return te.AttachAndStoreBundle(trunk, branch, utils.ZeroValueTx(t, tag))
+		}
+	}
+
+	// a single zero value bundle
+	attach("O", nil, nil, zeroValue("O"))
+
+	// a chain of zero value bundles
+	attach("S1", nil, nil, zeroValue("S"))
+	attach("S2", bundles["S1"], bundles["S1"], zeroValue("S"))
+	attach("S3", bundles["S2"], bundles["S2"], zeroValue("S"))
+	attach("S4", bundles["S3"], bundles["S3"], zeroValue("S"))
+
+	// two value transfers of seed1
+	attach("V1", nil, nil, func(trunk, branch []byte) *tangle.CachedBundle {
+		return te.AttachAndStoreBundle(trunk, ms2, utils.ValueTx(t, "V", seed1, 0, 1000, seed2, 0, 100))
+	})
+	attach("V2", bundles["V1"], bundles["V1"], func(trunk, branch []byte) *tangle.CachedBundle {
+		return te.AttachAndStoreBundle(trunk, branch, utils.ValueTx(t, "V", seed1, 1, 900, seed2, 1, 200))
+	})
+
+	// a value transfer of seed3 with two zero value bundles on top
+	attach("V3", nil, nil, func(trunk, branch []byte) *tangle.CachedBundle {
+		return te.AttachAndStoreBundle(ms2, branch, utils.ValueTx(t, "X", seed3, 0, 1000, seed4, 0, 300))
+	})
+	attach("Z1", bundles["V3"], bundles["V3"], zeroValue("Z"))
+	attach("Z2", bundles["Z1"], bundles["Z1"], zeroValue("Z"))
+
+	selector, err := mselection.NewSelector(strategy, 0, 1, 0, time.Second)
+	require.NoError(t, err)
+
+	tips, conf := te.IssueAndConfirmMilestoneWithSelector(selector, solidOrder...)
+	require.Len(t, tips, 1)
+	require.Equal(t, bundles[expectedTip].GetBundle().GetTailHash(), tips[0])
+	require.Equal(t, expectedTxsValue, conf.TxsValue)
+	require.Equal(t, 0, conf.TxsConflicting)
+}
+
+func TestHeaviestSelectionStrategy(t *testing.T) {
+	// the longest zero value chain
+	testSelectionStrategy(t, mselection.StrategyHeaviest, "S4", 0)
+}
+
+func TestOldestSelectionStrategy(t *testing.T) {
+	// the bundle which was solid first
+	testSelectionStrategy(t, mselection.StrategyOldest, "O", 0)
+}
+
+func TestValueSelectionStrategy(t *testing.T) {
+	// the chain with two value transfers
+	testSelectionStrategy(t, mselection.StrategyValue, "V2", 8)
+}
+
+func TestInputAddressSelectionStrategy(t *testing.T) {
+	// the two value transfers of seed1 are spent from two input addresses, the same amount as the chain of seed3
+	// with its zero value bundles, but the chain of seed3 references more transactions
+	testSelectionStrategy(t, mselection.StrategyInputAddress, "Z2", 4)
+}
+
+func TestUnknownSelectionStrategy(t *testing.T) {
+	_, err := mselection.NewSelector("unknown", 0, 1, 0, time.Second)
+	require.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package tangle
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+const (
+	// the state of a referenced tail in the stored confirmation.
+	confirmationTailIncluded            byte = 0
+	confirmationTailExcludedConflicting byte = 1
+	confirmationTailExcludedZeroValue   byte = 2
+)
+
+// MilestoneConfirmation is the persisted result of the white-flag confirmation of a milestone.
+type MilestoneConfirmation struct {
+	// The index of the milestone that got confirmed.
+	MilestoneIndex milestone.Index
+	// The transaction hash of the tail transaction of the milestone that got confirmed.
+	MilestoneHash hornet.Hash
+	// The tails of bundles which mutated the ledger in the order in which they were applied.
+	TailsIncluded hornet.Hashes
+	// The tails of bundles which were excluded as they were conflicting with the mutations.
+	TailsExcludedConflicting hornet.Hashes
+	// The tails which were excluded because they were part of a zero or spam value transfer.
+	TailsExcludedZeroValue hornet.Hashes
+	// The tails which were referenced by the milestone in the order in which they were traversed.
+	TailsReferenced hornet.Hashes
+	// The merkle tree root hash of all included tails.
+	MerkleTreeHash []byte
+}
+
+var (
+	confirmationsStore kvstore.KVStore
+)
+
+func configureConfirmationsStore(store kvstore.KVStore) {
+	confirmationsStore = store.WithRealm([]byte{StorePrefixConfirmations})
+}
+
+// the included and excluded tails are subsequences of the referenced tails,
+// so only the referenced tails are stored together with their state.
+func bytesFromMilestoneConfirmation(conf *MilestoneConfirmation) ([]byte, error) {
+
+	states := make(map[string]byte, len(conf.TailsReferenced))
+	for _, tail := range conf.TailsIncluded {
+		states[string(tail)] = confirmationTailIncluded
+	}
+	for _, tail := range conf.TailsExcludedConflicting {
+		states[string(tail)] = confirmationTailExcludedConflicting
+	}
+	for _, tail := range conf.TailsExcludedZeroValue {
+		states[string(tail)] = confirmationTailExcludedZeroValue
+	}
+
+	if len(states) != len(conf.TailsReferenced) {
+		return nil, fmt.Errorf("the referenced tails don't match the included and excluded tails: %d != %d", len(conf.TailsReferenced), len(states))
+	}
+
+	value := make([]byte, 0, 49+1+len(conf.MerkleTreeHash)+4+len(conf.TailsReferenced)*(1+49))
+	value = append(value, conf.MilestoneHash[:49]...)
+	value = append(value, byte(len(conf.MerkleTreeHash)))
+	value = append(value, conf.MerkleTreeHash...)
+
+	count := make([]byte, 4)
+	binary.LittleEndian.PutUint32(count, uint32(len(conf.TailsReferenced)))
+	value = append(value, count...)
+
+	for _, tail := range conf.TailsReferenced {
+		state, exists := states[string(tail)]
+		if !exists {
+			return nil, fmt.Errorf("referenced tail %s is neither included nor excluded", tail.Trytes())
+		}
+		value = append(value, state)
+		value = append(value, tail[:49]...)
+	}
+
+	return value, nil
+}
+
+// referencedCountFromBytes returns the amount of referenced tails in the stored confirmation
+// and the offset of the first referenced tail.
+func referencedCountFromBytes(value []byte) (int, int, error) {
+
+	if len(value) < 49+1 {
+		return 0, 0, fmt.Errorf("invalid milestone confirmation length: %d", len(value))
+	}
+
+	merkleTreeHashLength := int(value[49])
+	offset := 49 + 1 + merkleTreeHashLength
+	if len(value) < offset+4 {
+		return 0, 0, fmt.Errorf("invalid milestone confirmation length: %d", len(value))
+	}
+
+	count := int(binary.LittleEndian.Uint32(value[offset : offset+4]))
+	offset += 4
+	if len(value) != offset+count*(1+49) {
+		return 0, 0, fmt.Errorf("invalid milestone confirmation length: %d", len(value))
+	}
+
+	return count, offset, nil
+}
+
+func milestoneConfirmationFromBytes(index milestone.Index, value []byte) (*MilestoneConfirmation, error) {
+
+	count, offset, err := referencedCountFromBytes(value)
+	if err != nil {
+		return nil, err
+	}
+	merkleTreeHashLength := int(value[49])
+
+	conf := &MilestoneConfirmation{
+		MilestoneIndex:           index,
+		MilestoneHash:            hornet.Hash(value[:49]),
+		TailsIncluded:            make(hornet.Hashes, 0),
+		TailsExcludedConflicting: make(hornet.Hashes, 0),
+		TailsExcludedZeroValue:   make(hornet.Hashes, 0),
+		TailsReferenced:          make(hornet.Hashes, 0, count),
+		MerkleTreeHash:           value[49+1 : 49+1+merkleTreeHashLength],
+	}
+
+	for i := 0; i < count; i++ {
+		state := value[offset]
+		tail := hornet.Hash(value[offset+1 : offset+1+49])
+		offset += 1 + 49
+
+		switch state {
+		case confirmationTailIncluded:
+			conf.TailsIncluded = append(conf.TailsIncluded, tail)
+		case confirmationTailExcludedConflicting:
+			conf.TailsExcludedConflicting = append(conf.TailsExcludedConflicting, tail)
+		case confirmationTailExcludedZeroValue:
+			conf.TailsExcludedZeroValue = append(conf.TailsExcludedZeroValue, tail)
+		default:
+			return nil, fmt.Errorf("invalid state of referenced tail %s: %d", tail.Trytes(), state)
+		}
+		conf.TailsReferenced = append(conf.TailsReferenced, tail)
+	}
+
+	return conf, nil
+}
+
+// StoreMilestoneConfirmation stores the result of the white-flag confirmation of a milestone.
+func StoreMilestoneConfirmation(conf *MilestoneConfirmation) error {
+
+	value, err := bytesFromMilestoneConfirmation(conf)
+	if err != nil {
+		return err
+	}
+
+	if err := confirmationsStore.Set(databaseKeyForMilestoneIndex(conf.MilestoneIndex), value); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store milestone confirmation")
+	}
+
+	return nil
+}
+
+// GetMilestoneConfirmation returns the result of the white-flag confirmation of the given milestone.
+// Returns nil if the confirmation is not known.
+func GetMilestoneConfirmation(index milestone.Index) (*MilestoneConfirmation, error) {
+
+	value, err := confirmationsStore.Get(databaseKeyForMilestoneIndex(index))
+	if err != nil {
+		if err == kvstore.ErrKeyNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to load milestone confirmation")
+	}
+
+	return milestoneConfirmationFromBytes(index, value)
+}
+
+// GetMilestoneConfirmationReferencedCount returns the amount of tails referenced by the milestone with the given index
+// without decoding the whole confirmation, and whether a confirmation of the milestone is stored.
+func GetMilestoneConfirmationReferencedCount(index milestone.Index) (int, bool, error) {
+
+	value, err := confirmationsStore.Get(databaseKeyForMilestoneIndex(index))
+	if err != nil {
+		if err == kvstore.ErrKeyNotFound {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(NewDatabaseError(err), "failed to load milestone confirmation")
+	}
+
+	count, _, err := referencedCountFromBytes(value)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return count, true, nil
+}
+
+// DeleteMilestoneConfirmation deletes the result of the white-flag confirmation of the given milestone.
+func DeleteMilestoneConfirmation(index milestone.Index) error {
+
+	if err := confirmationsStore.Delete(databaseKeyForMilestoneIndex(index)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete milestone confirmation")
+	}
+
+	return nil
+}
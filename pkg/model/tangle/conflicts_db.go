@@ -0,0 +1,171 @@
+package tangle
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+// ConflictReason is the reason why a bundle was excluded as conflicting by a white-flag confirmation.
+type ConflictReason byte
+
+const (
+	// ConflictNone means the bundle is not conflicting.
+	ConflictNone ConflictReason = iota
+	// ConflictInsufficientBalance means the input address didn't have enough balance in the ledger state of the previous milestone.
+	ConflictInsufficientBalance
+	// ConflictDoubleSpend means the balance of the input address was sufficient in the ledger state of the previous milestone,
+	// but it was already spent by another bundle which was applied before in the same milestone cone.
+	ConflictDoubleSpend
+	// ConflictExceedsTotalSupply means the balance of an address would exceed the total supply.
+	ConflictExceedsTotalSupply
+)
+
+// String returns the name of the conflict reason.
+func (r ConflictReason) String() string {
+	switch r {
+	case ConflictNone:
+		return "none"
+	case ConflictInsufficientBalance:
+		return "insufficientBalance"
+	case ConflictDoubleSpend:
+		return "doubleSpend"
+	case ConflictExceedsTotalSupply:
+		return "exceedsTotalSupply"
+	default:
+		return fmt.Sprintf("unknown(%d)", r)
+	}
+}
+
+// Conflict describes why the bundle of a tail transaction was excluded as conflicting by a white-flag confirmation.
+type Conflict struct {
+	// The tail transaction hash of the conflicting bundle.
+	TailHash hornet.Hash
+	// The reason why the bundle is conflicting.
+	Reason ConflictReason
+	// The address which caused the conflict.
+	Address hornet.Hash
+	// The tail transaction hash of the bundle which spent the balance of the address before.
+	// Only set for double spends.
+	ConflictingTailHash hornet.Hash
+}
+
+var (
+	conflictsStore kvstore.KVStore
+)
+
+func configureConflictsStore(store kvstore.KVStore) {
+	conflictsStore = store.WithRealm([]byte{StorePrefixConflicts})
+}
+
+func databaseKeyForConflict(milestoneIndex milestone.Index, tailHash hornet.Hash) []byte {
+	return append(databaseKeyForMilestoneIndex(milestoneIndex), tailHash[:49]...)
+}
+
+func bytesFromConflict(conflict *Conflict) []byte {
+	value := make([]byte, 0, 1+49+49)
+	value = append(value, byte(conflict.Reason))
+	value = append(value, conflict.Address[:49]...)
+	if len(conflict.ConflictingTailHash) > 0 {
+		value = append(value, conflict.ConflictingTailHash[:49]...)
+	}
+	return value
+}
+
+func conflictFromBytes(tailHash hornet.Hash, value []byte) (*Conflict, error) {
+	if len(value) != 1+49 && len(value) != 1+49+49 {
+		return nil, fmt.Errorf("invalid conflict length: %d", len(value))
+	}
+
+	conflict := &Conflict{
+		TailHash: tailHash,
+		Reason:   ConflictReason(value[0]),
+		Address:  hornet.Hash(value[1:50]),
+	}
+	if len(value) == 1+49+49 {
+		conflict.ConflictingTailHash = hornet.Hash(value[50:99])
+	}
+
+	return conflict, nil
+}
+
+// StoreConflictsForMilestone stores the reasons why bundles were excluded as conflicting by the given milestone.
+// Previously stored conflicts of the milestone (e.g. from an aborted revalidation) are replaced.
+func StoreConflictsForMilestone(index milestone.Index, conflicts []*Conflict) error {
+
+	batch := conflictsStore.Batched()
+
+	// previously stored conflicts of the milestone are replaced within the same batch
+	if err := conflictsStore.IterateKeys(databaseKeyForMilestoneIndex(index), func(key kvstore.Key) bool {
+		batch.Delete(key)
+		return true
+	}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to load conflicts")
+	}
+
+	for _, conflict := range conflicts {
+		batch.Set(databaseKeyForConflict(index, conflict.TailHash), bytesFromConflict(conflict))
+	}
+
+	if err := batch.Commit(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store conflicts")
+	}
+
+	return nil
+}
+
+// GetConflict returns the reason why the bundle of the given tail transaction was excluded as conflicting by the given milestone.
+// Returns nil if no conflict is known.
+func GetConflict(index milestone.Index, tailHash hornet.Hash) (*Conflict, error) {
+
+	value, err := conflictsStore.Get(databaseKeyForConflict(index, tailHash))
+	if err != nil {
+		if err == kvstore.ErrKeyNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to load conflict")
+	}
+
+	return conflictFromBytes(tailHash, value)
+}
+
+// GetConflictsForMilestone returns the reasons why bundles were excluded as conflicting by the given milestone.
+func GetConflictsForMilestone(index milestone.Index) ([]*Conflict, error) {
+
+	var conflicts []*Conflict
+	var innerErr error
+
+	keyPrefix := databaseKeyForMilestoneIndex(index)
+	if err := conflictsStore.Iterate(keyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		conflict, err := conflictFromBytes(hornet.Hash(key[len(keyPrefix):len(keyPrefix)+49]), value)
+		if err != nil {
+			innerErr = err
+			return false
+		}
+		conflicts = append(conflicts, conflict)
+		return true
+	}); err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to load conflicts")
+	}
+
+	if innerErr != nil {
+		return nil, innerErr
+	}
+
+	return conflicts, nil
+}
+
+// DeleteConflictsForMilestone deletes the conflict reasons of the given milestone.
+func DeleteConflictsForMilestone(index milestone.Index) error {
+
+	if err := conflictsStore.DeletePrefix(databaseKeyForMilestoneIndex(index)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete conflicts")
+	}
+
+	return nil
+}
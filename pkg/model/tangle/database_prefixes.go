@@ -18,4 +18,7 @@ const (
 	StorePrefixUnconfirmedTransactions byte = 14
 	StorePrefixSpentAddresses          byte = 15
 	StorePrefixAutopeering             byte = 16
+	StorePrefixConflicts               byte = 17
+	StorePrefixConfirmations           byte = 18
+	StorePrefixRetainedTransactions    byte = 19
 )
@@ -0,0 +1,84 @@
+package tangle
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+var (
+	pruningStateKey = []byte("pruningState")
+
+	ErrParsePruningStateFailed = errors.New("Parsing of pruning state failed")
+)
+
+// PruningState holds the progress of a running pruning.
+// It is stored before the first milestone is pruned and removed after the pruning finished,
+// so an interrupted pruning can be resumed with the same solid entry points.
+type PruningState struct {
+	StartIndex  milestone.Index
+	TargetIndex milestone.Index
+	Timestamp   int64
+}
+
+func PruningStateFromBytes(bytes []byte) (*PruningState, error) {
+
+	if len(bytes) != 16 {
+		return nil, errors.Wrapf(ErrParsePruningStateFailed, "Invalid length %d != 16", len(bytes))
+	}
+
+	return &PruningState{
+		StartIndex:  milestone.Index(binary.LittleEndian.Uint32(bytes[:4])),
+		TargetIndex: milestone.Index(binary.LittleEndian.Uint32(bytes[4:8])),
+		Timestamp:   int64(binary.LittleEndian.Uint64(bytes[8:16])),
+	}, nil
+}
+
+func (s *PruningState) GetBytes() []byte {
+	bytes := make([]byte, 16)
+	binary.LittleEndian.PutUint32(bytes[:4], uint32(s.StartIndex))
+	binary.LittleEndian.PutUint32(bytes[4:8], uint32(s.TargetIndex))
+	binary.LittleEndian.PutUint64(bytes[8:16], uint64(s.Timestamp))
+	return bytes
+}
+
+// StorePruningState persists the progress of a running pruning.
+func StorePruningState(state *PruningState) error {
+
+	if err := snapshotStore.Set(pruningStateKey, state.GetBytes()); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store pruning state")
+	}
+
+	return nil
+}
+
+// ReadPruningState returns the state of an unfinished pruning, or nil if there is none.
+func ReadPruningState() (*PruningState, error) {
+	value, err := snapshotStore.Get(pruningStateKey)
+	if err != nil {
+		if err != kvstore.ErrKeyNotFound {
+			return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve pruning state")
+		}
+		return nil, nil
+	}
+
+	state, err := PruningStateFromBytes(value)
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to convert pruning state")
+	}
+	return state, nil
+}
+
+// DeletePruningState removes the state of a finished pruning.
+func DeletePruningState() error {
+
+	if err := snapshotStore.Delete(pruningStateKey); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete pruning state")
+	}
+
+	return nil
+}
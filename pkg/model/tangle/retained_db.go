@@ -0,0 +1,83 @@
+package tangle
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+var (
+	retainedTransactionsStore kvstore.KVStore
+)
+
+func configureRetainedTransactionsStore(store kvstore.KVStore) {
+	retainedTransactionsStore = store.WithRealm([]byte{StorePrefixRetainedTransactions})
+}
+
+func databaseKeyForRetainedTransaction(milestoneIndex milestone.Index, txHash hornet.Hash) []byte {
+	return append(databaseKeyForMilestoneIndex(milestoneIndex), txHash[:49]...)
+}
+
+// StoreRetainedTransactions stores the hashes of the transactions which were kept while the given milestone was pruned.
+func StoreRetainedTransactions(index milestone.Index, txHashes hornet.Hashes) error {
+
+	batch := retainedTransactionsStore.Batched()
+
+	for _, txHash := range txHashes {
+		batch.Set(databaseKeyForRetainedTransaction(index, txHash), []byte{})
+	}
+
+	if err := batch.Commit(); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store retained transactions")
+	}
+
+	return nil
+}
+
+// GetRetainedTransactions returns the hashes of the transactions which were kept while the given milestone was pruned.
+func GetRetainedTransactions(index milestone.Index) (hornet.Hashes, error) {
+
+	var txHashes hornet.Hashes
+
+	keyPrefix := databaseKeyForMilestoneIndex(index)
+	if err := retainedTransactionsStore.IterateKeys(keyPrefix, func(key kvstore.Key) bool {
+		txHashes = append(txHashes, hornet.Hash(key[len(keyPrefix):len(keyPrefix)+49]))
+		return true
+	}); err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to load retained transactions")
+	}
+
+	return txHashes, nil
+}
+
+// GetRetainedTransactionsMilestoneIndexes returns the indexes of all milestones whose pruning kept transactions.
+func GetRetainedTransactionsMilestoneIndexes() ([]milestone.Index, error) {
+
+	indexes := make(map[milestone.Index]struct{})
+	if err := retainedTransactionsStore.IterateKeys([]byte{}, func(key kvstore.Key) bool {
+		indexes[milestoneIndexFromDatabaseKey(key[:4])] = struct{}{}
+		return true
+	}); err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to load retained transactions")
+	}
+
+	result := make([]milestone.Index, 0, len(indexes))
+	for index := range indexes {
+		result = append(result, index)
+	}
+
+	return result, nil
+}
+
+// DeleteRetainedTransactions deletes the hashes of the transactions which were kept while the given milestone was pruned.
+func DeleteRetainedTransactions(index milestone.Index) error {
+
+	if err := retainedTransactionsStore.DeletePrefix(databaseKeyForMilestoneIndex(index)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete retained transactions")
+	}
+
+	return nil
+}
@@ -67,6 +67,9 @@ func ConfigureStorages(tangleStore kvstore.KVStore, snapshotStore kvstore.KVStor
 	configureMilestoneStorage(tangleStore, caches.Milestones)
 	configureUnconfirmedTxStorage(tangleStore, caches.UnconfirmedTx)
 	configureLedgerStore(tangleStore)
+	configureConflictsStore(tangleStore)
+	configureConfirmationsStore(tangleStore)
+	configureRetainedTransactionsStore(tangleStore)
 
 	configureSnapshotStore(snapshotStore)
 
@@ -158,3 +161,23 @@ func GetDatabaseSizes() (tangle int64, snapshot int64, spent int64) {
 
 	return
 }
+
+// GetDatabaseUsedSizes returns the size of the different databases without the freed pages,
+// since the database files don't shrink after data was deleted, but the freed pages are reused.
+func GetDatabaseUsedSizes() (tangle int64, snapshot int64, spent int64) {
+
+	tangle, snapshot, spent = GetDatabaseSizes()
+
+	usedSize := func(size int64, db *bbolt.DB) int64 {
+		if db == nil {
+			return size
+		}
+
+		if used := size - int64(db.Stats().FreeAlloc); used > 0 {
+			return used
+		}
+		return 0
+	}
+
+	return usedSize(tangle, tangleDb), usedSize(snapshot, snapshotDb), usedSize(spent, spentDb)
+}
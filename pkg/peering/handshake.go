@@ -23,11 +23,20 @@ func (m *Manager) setupHandshakeEventHandlers(p *peer.Peer) {
 	p.Protocol.Events.Received[handshake.MessageTypeHandshake].Attach(events.NewClosure(func(data []byte) {
 		handshakeMsg, err := handshake.ParseHandshake(data)
 		if err != nil {
+			if !p.IsInbound() {
+				m.recordHistoryEvent(p.ID, peer.HistoryEventHandshakeFailed, err.Error())
+			}
 			p.Protocol.Events.Error.Trigger(err)
 			return
 		}
 
 		if err := m.verifyHandshake(p, handshakeMsg); err != nil {
+			id := p.ID
+			if p.IsInbound() {
+				// the ID of inbound peers is derived from the advertised server socket port
+				id = peer.NewID(p.PrimaryAddress.String(), handshakeMsg.ServerSocketPort)
+			}
+			m.recordHistoryEvent(id, peer.HistoryEventHandshakeFailed, err.Error())
 			p.Protocol.Events.Error.Trigger(err)
 		}
 	}))
@@ -43,6 +52,7 @@ func (m *Manager) setupHandshakeEventHandlers(p *peer.Peer) {
 		// first receive timestamp has to be set here, otherwise we could falsely drop the peer if the heartbeat is checked
 		p.HeartbeatReceivedTime = time.Now()
 
+		m.recordHistoryEvent(p.ID, peer.HistoryEventConnected, p.ConnectionOrigin.String())
 		m.Events.PeerConnected.Trigger(p)
 	}))
 }
@@ -92,6 +102,11 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 		}
 	}
 
+	// check whether the transport matches the pinned public key and the transport policy
+	if err := m.verifyTransport(p, handshakeMsg); err != nil {
+		return err
+	}
+
 	// drop the connection if it's not an autopeer and in the meantime
 	// the available peering slots were filled
 	if p.Autopeering == nil && m.SlotsFilled() {
@@ -122,9 +137,19 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 	if !m.Opts.AcceptAnyPeer && !whitelisted {
 		m.Unlock()
 		m.Blacklist(p.PrimaryAddress.String())
+		m.recordHistoryEvent(p.ID, peer.HistoryEventBlacklisted, ErrUnknownPeerID.Error())
 		return errors.Wrapf(ErrUnknownPeerID, p.ID)
 	}
 
+	// limit the amount of autopeered and accepted unknown peers of the same subnet
+	if subnetLimited(p, whitelisted) && m.subnetLimitReached(p) {
+		m.Unlock()
+		if p.IsInbound() {
+			m.reject(p.PrimaryAddress.String(), RejectionReasonSubnetLimit)
+		}
+		return errors.Wrapf(ErrSubnetLimitReached, "subnet of %s", p.ID)
+	}
+
 	// we mark this peer to be put back into the reconnect pool
 	// if it was whitelisted, which therefore means that we want to keep
 	// a connection to this peer.
@@ -137,7 +162,12 @@ func (m *Manager) verifyHandshake(p *peer.Peer, handshakeMsg *handshake.Handshak
 
 	m.Unlock()
 
-	p.Protocol.FeatureSet = byte(version)
+	// the score is kept across reconnects of the peer
+	m.restoreScore(p)
+
+	// the optional feature sets supported by both sides are added to the negotiated protocol version
+	p.Protocol.FeatureSet = byte(version) | handshakeMsg.SupportedOptionalFeatureSets(protocol.SupportedOptionalFeatureSets)
+	p.Protocol.Extensions = handshakeMsg.Extensions & protocol.SupportedExtensions
 	p.Protocol.Handshaked()
 	return nil
 }
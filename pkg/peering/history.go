@@ -0,0 +1,77 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// the maximum amount of peers for which a history is kept.
+	// inbound connections of unknown peers also create histories, so the amount has to be bounded.
+	maxPeerHistories = 1000
+)
+
+// records the given event in the connection lifecycle of the peer with the given ID.
+// must not be called while holding the manager's lock, as the PeerHistoryEvent handlers may access the manager.
+func (m *Manager) recordHistoryEvent(id string, eventType peer.HistoryEventType, reason string) {
+	event := &peer.HistoryEvent{
+		ID:        id,
+		Type:      eventType,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	}
+
+	m.historiesMu.Lock()
+	history, exists := m.histories[id]
+	if !exists {
+		if len(m.histories) >= maxPeerHistories {
+			m.evictOldestHistory()
+		}
+		history = peer.NewHistory(m.Opts.HistorySize)
+		m.histories[id] = history
+	}
+	history.Add(event)
+	m.historiesMu.Unlock()
+
+	m.Events.PeerHistoryEvent.Trigger(event)
+}
+
+// removes the history whose latest event is the oldest.
+// the caller must hold the historiesMu.
+func (m *Manager) evictOldestHistory() {
+	var oldestID string
+	var oldest time.Time
+	for id, history := range m.histories {
+		if latest := history.Latest(); oldestID == "" || latest.Before(oldest) {
+			oldestID = id
+			oldest = latest
+		}
+	}
+	delete(m.histories, oldestID)
+}
+
+// PeerHistory returns the recorded events in the connection lifecycle of the peer with the given ID,
+// from the oldest to the newest. Returns nil if no events were recorded for the peer.
+func (m *Manager) PeerHistory(id string) []*peer.HistoryEvent {
+	m.historiesMu.Lock()
+	history, exists := m.histories[id]
+	m.historiesMu.Unlock()
+
+	if !exists {
+		return nil
+	}
+	return history.Events()
+}
+
+// PeerHistories returns the recorded events in the connection lifecycle of all peers by their ID.
+func (m *Manager) PeerHistories() map[string][]*peer.HistoryEvent {
+	m.historiesMu.Lock()
+	defer m.historiesMu.Unlock()
+
+	histories := make(map[string][]*peer.HistoryEvent, len(m.histories))
+	for id, history := range m.histories {
+		histories[id] = history.Events()
+	}
+	return histories
+}
@@ -0,0 +1,45 @@
+package peering
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+func TestHistoryEventHandlersMayAccessManager(t *testing.T) {
+	// a closed port, so that the connection attempt fails
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	m := NewManager(Options{}, &config.PeerConfig{ID: addr})
+
+	eventTypes := make(chan peer.HistoryEventType, 10)
+	m.Events.PeerHistoryEvent.Attach(events.NewClosure(func(event *peer.HistoryEvent) {
+		// the handlers are not called while the manager is locked
+		m.PeerInfos()
+		eventTypes <- event.Type
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		m.Reconnect()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reconnecting blocked")
+	}
+
+	require.Equal(t, peer.HistoryEventHandshaking, <-eventTypes)
+	require.Equal(t, peer.HistoryEventConnectionFailed, <-eventTypes)
+}
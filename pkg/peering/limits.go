@@ -0,0 +1,231 @@
+package peering
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/events"
+	"go.uber.org/atomic"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// the window in which the connection attempts per IP address are counted.
+	connectionRateWindow = time.Minute
+	// the prefix length of the IPv4 subnets used for the subnet diversity rules.
+	ipv4SubnetPrefixLength = 24
+	// the prefix length of the IPv6 subnets used for the subnet diversity rules.
+	ipv6SubnetPrefixLength = 48
+)
+
+// RejectionReason defines why an inbound connection was rejected.
+type RejectionReason string
+
+const (
+	// RejectionReasonBlacklisted denotes that the IP address of the connection was blacklisted.
+	RejectionReasonBlacklisted RejectionReason = "blacklisted"
+	// RejectionReasonConnectionRate denotes that the IP address exceeded the allowed connection rate.
+	RejectionReasonConnectionRate RejectionReason = "connectionRate"
+	// RejectionReasonMaxHandshaking denotes that the maximum amount of concurrent handshakes was reached.
+	RejectionReasonMaxHandshaking RejectionReason = "maxHandshaking"
+	// RejectionReasonHandshakeTimeout denotes that the handshake didn't complete in time.
+	RejectionReasonHandshakeTimeout RejectionReason = "handshakeTimeout"
+	// RejectionReasonSubnetLimit denotes that too many peers of the same subnet are connected.
+	RejectionReasonSubnetLimit RejectionReason = "subnetLimit"
+)
+
+// RejectionReasons are all reasons why inbound connections are rejected.
+var RejectionReasons = []RejectionReason{
+	RejectionReasonBlacklisted,
+	RejectionReasonConnectionRate,
+	RejectionReasonMaxHandshaking,
+	RejectionReasonHandshakeTimeout,
+	RejectionReasonSubnetLimit,
+}
+
+// InboundLimitOptions define the limits for connections to protect against connection floods.
+// A zero value disables the corresponding limit.
+type InboundLimitOptions struct {
+	// The max amount of inbound connections which are handshaking at the same time.
+	MaxHandshaking int
+	// The max amount of inbound connections per IP address and minute.
+	MaxConnectionsPerIPPerMinute int
+	// The time in which a connection has to complete the handshake.
+	HandshakeTimeout time.Duration
+	// The max amount of autopeered and accepted unknown peers in the same subnet.
+	MaxPeersPerSubnet int
+}
+
+func RejectionCaller(handler interface{}, params ...interface{}) {
+	handler.(func(addr string, reason string))(params[0].(string), params[1].(string))
+}
+
+// counts the connection attempts of an IP address in the current window.
+type connectionRate struct {
+	windowStart time.Time
+	count       int
+}
+
+// inboundLimiter enforces the inbound connection limits.
+type inboundLimiter struct {
+	handshaking atomic.Int32
+	rejections  map[RejectionReason]*atomic.Uint32
+
+	ratesMu sync.Mutex
+	rates   map[string]*connectionRate
+}
+
+func newInboundLimiter() *inboundLimiter {
+	limiter := &inboundLimiter{
+		rejections: make(map[RejectionReason]*atomic.Uint32, len(RejectionReasons)),
+		rates:      make(map[string]*connectionRate),
+	}
+	for _, reason := range RejectionReasons {
+		limiter.rejections[reason] = atomic.NewUint32(0)
+	}
+	return limiter
+}
+
+// counts a rejected connection and fires the InboundConnectionRejected event.
+func (m *Manager) reject(addr string, reason RejectionReason) {
+	m.inboundLimiter.rejections[reason].Inc()
+	m.Events.InboundConnectionRejected.Trigger(addr, string(reason))
+}
+
+// InboundRejections returns the amount of rejected connections by their reason.
+func (m *Manager) InboundRejections() map[RejectionReason]uint32 {
+	rejections := make(map[RejectionReason]uint32, len(m.inboundLimiter.rejections))
+	for reason, count := range m.inboundLimiter.rejections {
+		rejections[reason] = count.Load()
+	}
+	return rejections
+}
+
+// HandshakingCount returns the amount of inbound connections which are currently handshaking.
+func (m *Manager) HandshakingCount() int {
+	return int(m.inboundLimiter.handshaking.Load())
+}
+
+// tells whether the given IP address is allowed to connect, given the connection rate limit.
+func (m *Manager) allowConnectionAttempt(ip string) bool {
+	maxConnections := m.Opts.InboundLimits.MaxConnectionsPerIPPerMinute
+	if maxConnections <= 0 {
+		return true
+	}
+
+	limiter := m.inboundLimiter
+	limiter.ratesMu.Lock()
+	defer limiter.ratesMu.Unlock()
+
+	now := time.Now()
+
+	// drop the expired windows, so that the map doesn't grow during a flood from many addresses
+	if len(limiter.rates) > 1000 {
+		for rateIP, rate := range limiter.rates {
+			if now.Sub(rate.windowStart) > connectionRateWindow {
+				delete(limiter.rates, rateIP)
+			}
+		}
+	}
+
+	rate, exists := limiter.rates[ip]
+	if !exists || now.Sub(rate.windowStart) > connectionRateWindow {
+		rate = &connectionRate{windowStart: now}
+		limiter.rates[ip] = rate
+	}
+
+	rate.count++
+	return rate.count <= maxConnections
+}
+
+// acquires a slot for an inbound handshake. returns false if all slots are taken.
+func (m *Manager) acquireHandshakeSlot() bool {
+	maxHandshaking := int32(m.Opts.InboundLimits.MaxHandshaking)
+	if m.inboundLimiter.handshaking.Inc() > maxHandshaking && maxHandshaking > 0 {
+		m.inboundLimiter.handshaking.Dec()
+		return false
+	}
+	return true
+}
+
+// releases the handshake slot of the given inbound peer as soon as the handshake completed or the connection was closed.
+func (m *Manager) releaseHandshakeSlotOnCompletion(p *peer.Peer) {
+	var releaseOnce sync.Once
+	release := events.NewClosure(func() {
+		releaseOnce.Do(func() {
+			m.inboundLimiter.handshaking.Dec()
+		})
+	})
+	p.Protocol.Events.HandshakeCompleted.Attach(release)
+	p.Conn.Events.Close.Attach(release)
+}
+
+// closes the connection to the given peer if it didn't complete the handshake within the timeout.
+func (m *Manager) enforceHandshakeTimeout(p *peer.Peer) {
+	timeout := m.Opts.InboundLimits.HandshakeTimeout
+	if timeout <= 0 {
+		return
+	}
+
+	timer := time.AfterFunc(timeout, func() {
+		if p.Handshaked() || p.Disconnected {
+			return
+		}
+
+		if p.IsInbound() {
+			// the ID of inbound peers is only known after the handshake, so no history is recorded
+			m.reject(p.PrimaryAddress.String(), RejectionReasonHandshakeTimeout)
+		} else {
+			m.recordHistoryEvent(p.ID, peer.HistoryEventHandshakeFailed, ErrHandshakeTimeout.Error())
+		}
+		p.SetDisconnectReason(ErrHandshakeTimeout.Error())
+		_ = p.Conn.Close()
+	})
+
+	stop := events.NewClosure(func() { timer.Stop() })
+	p.Protocol.Events.HandshakeCompleted.Attach(stop)
+	p.Conn.Events.Close.Attach(stop)
+}
+
+// returns the subnet of the given IP address used for the subnet diversity rules.
+func subnet(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(ipv4SubnetPrefixLength, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(ipv6SubnetPrefixLength, 128)).String()
+}
+
+// tells whether the peer is subject to the subnet diversity rules.
+// only autopeered peers and accepted unknown peers are limited, static peers are chosen by the operator.
+func subnetLimited(p *peer.Peer, whitelisted bool) bool {
+	return p.Autopeering != nil || !whitelisted
+}
+
+// tells whether the subnet of the given peer already reached the max amount of connected peers.
+// the caller must hold the manager lock.
+func (m *Manager) subnetLimitReached(p *peer.Peer) bool {
+	maxPeersPerSubnet := m.Opts.InboundLimits.MaxPeersPerSubnet
+	if maxPeersPerSubnet <= 0 || p.PrimaryAddress == nil {
+		return false
+	}
+
+	peerSubnet := subnet(p.PrimaryAddress)
+	count := 0
+	for _, connectedPeer := range m.connected {
+		if connectedPeer == p || connectedPeer.PrimaryAddress == nil || !connectedPeer.Handshaked() {
+			continue
+		}
+
+		// peers are put back into the reconnect pool if they were whitelisted
+		if !subnetLimited(connectedPeer, connectedPeer.MoveBackToReconnectPool) {
+			continue
+		}
+
+		if subnet(connectedPeer.PrimaryAddress) == peerSubnet {
+			count++
+		}
+	}
+	return count >= maxPeersPerSubnet
+}
@@ -0,0 +1,39 @@
+package peering
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboundLimits(t *testing.T) {
+	m := NewManager(Options{
+		InboundLimits: InboundLimitOptions{
+			MaxHandshaking:               2,
+			MaxConnectionsPerIPPerMinute: 3,
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		require.True(t, m.allowConnectionAttempt("10.0.0.1"))
+	}
+	require.False(t, m.allowConnectionAttempt("10.0.0.1"))
+	require.True(t, m.allowConnectionAttempt("10.0.0.2"))
+
+	require.True(t, m.acquireHandshakeSlot())
+	require.True(t, m.acquireHandshakeSlot())
+	require.False(t, m.acquireHandshakeSlot())
+	require.Equal(t, 2, m.HandshakingCount())
+
+	m.reject("10.0.0.1", RejectionReasonConnectionRate)
+	require.EqualValues(t, 1, m.InboundRejections()[RejectionReasonConnectionRate])
+	require.EqualValues(t, 0, m.InboundRejections()[RejectionReasonSubnetLimit])
+}
+
+func TestSubnet(t *testing.T) {
+	require.Equal(t, subnet(net.ParseIP("10.0.0.1")), subnet(net.ParseIP("10.0.0.254")))
+	require.NotEqual(t, subnet(net.ParseIP("10.0.0.1")), subnet(net.ParseIP("10.0.1.1")))
+	require.Equal(t, subnet(net.ParseIP("2001:db8:1::1")), subnet(net.ParseIP("2001:db8:1:ffff::1")))
+	require.NotEqual(t, subnet(net.ParseIP("2001:db8:1::1")), subnet(net.ParseIP("2001:db8:2::1")))
+}
@@ -0,0 +1,91 @@
+package peer
+
+import (
+	"sync"
+	"time"
+)
+
+// HistoryEventType defines the type of an event in the connection lifecycle of a peer.
+type HistoryEventType string
+
+const (
+	// HistoryEventHandshaking denotes that a connection to the peer is initiated and handshaked.
+	HistoryEventHandshaking HistoryEventType = "handshaking"
+	// HistoryEventConnectionFailed denotes that no connection to the peer could be established.
+	HistoryEventConnectionFailed HistoryEventType = "connectionFailed"
+	// HistoryEventHandshakeFailed denotes that the handshake with the peer failed.
+	HistoryEventHandshakeFailed HistoryEventType = "handshakeFailed"
+	// HistoryEventConnected denotes that the handshaking phase with the peer was successfully executed.
+	HistoryEventConnected HistoryEventType = "connected"
+	// HistoryEventDisconnected denotes that the connection to the peer was closed.
+	HistoryEventDisconnected HistoryEventType = "disconnected"
+	// HistoryEventBlacklisted denotes that the IP address of the peer was blacklisted.
+	HistoryEventBlacklisted HistoryEventType = "blacklisted"
+)
+
+// HistoryEvent is an event in the connection lifecycle of a peer.
+type HistoryEvent struct {
+	// The ID of the peer.
+	ID string `json:"id"`
+	// The type of the event.
+	Type HistoryEventType `json:"type"`
+	// The reason of the event, e.g. the error which caused a disconnect.
+	Reason string `json:"reason,omitempty"`
+	// The time the event occurred.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func HistoryEventCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*HistoryEvent))(params[0].(*HistoryEvent))
+}
+
+// NewHistory creates a new History which keeps the given amount of events.
+func NewHistory(size int) *History {
+	return &History{size: size}
+}
+
+// History is a bounded log of the events in the connection lifecycle of a peer.
+// If the history is full, the oldest event is dropped.
+type History struct {
+	mu     sync.RWMutex
+	size   int
+	events []*HistoryEvent
+}
+
+// Add adds the given event to the history.
+func (h *History) Add(event *HistoryEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.size <= 0 {
+		return
+	}
+
+	if len(h.events) >= h.size {
+		// drop the oldest events without keeping the underlying array forever
+		events := make([]*HistoryEvent, 0, h.size)
+		h.events = append(events, h.events[len(h.events)-h.size+1:]...)
+	}
+	h.events = append(h.events, event)
+}
+
+// Events returns a copy of the recorded events, from the oldest to the newest.
+func (h *History) Events() []*HistoryEvent {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	events := make([]*HistoryEvent, len(h.events))
+	copy(events, h.events)
+	return events
+}
+
+// Latest returns the time of the latest recorded event.
+func (h *History) Latest() time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.events) == 0 {
+		return time.Time{}
+	}
+	return h.events[len(h.events)-1].Timestamp
+}
@@ -0,0 +1,46 @@
+package peer
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryBounded(t *testing.T) {
+
+	history := NewHistory(3)
+	require.Empty(t, history.Events())
+	require.True(t, history.Latest().IsZero())
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		history.Add(&HistoryEvent{ID: "127.0.0.1:15600", Type: HistoryEventConnected, Timestamp: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	// only the latest events are kept
+	events := history.Events()
+	require.Len(t, events, 3)
+	for i, event := range events {
+		require.Equal(t, start.Add(time.Duration(i+2)*time.Second), event.Timestamp)
+	}
+	require.Equal(t, start.Add(4*time.Second), history.Latest())
+}
+
+func TestDisconnectReasonKeepsFirst(t *testing.T) {
+	p := &Peer{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.SetDisconnectReason("concurrent")
+		}()
+	}
+	wg.Wait()
+
+	p.SetDisconnectReason("later")
+	require.Equal(t, "concurrent", p.DisconnectReason())
+}
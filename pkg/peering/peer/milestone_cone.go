@@ -0,0 +1,94 @@
+package peer
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+const (
+	// MilestoneConeRequestTimeout defines how long a requested milestone cone is awaited from a peer.
+	MilestoneConeRequestTimeout = 60 * time.Second
+)
+
+var (
+	// ErrMilestoneConeNotRequested is returned when a peer sends a milestone cone which wasn't requested from it.
+	ErrMilestoneConeNotRequested = errors.New("milestone cone not requested")
+)
+
+// MilestoneCone holds the transactions of a milestone cone which were received from a peer.
+type MilestoneCone struct {
+	MilestoneIndex milestone.Index
+	// The compressed transaction bytes.
+	Transactions [][]byte
+}
+
+// keeps track of the milestone cones requested from a peer and collects the received chunks.
+type milestoneCones struct {
+	sync.Mutex
+	// the requested milestone cones and until when they are awaited.
+	requested map[milestone.Index]time.Time
+	// the milestone cone whose chunks are currently received.
+	receiving *MilestoneCone
+}
+
+// AddRequestedMilestoneCones marks the cones of the milestones in the given range as requested from the peer.
+func (p *Peer) AddRequestedMilestoneCones(startIndex milestone.Index, endIndex milestone.Index) {
+	p.milestoneCones.Lock()
+	defer p.milestoneCones.Unlock()
+
+	if p.milestoneCones.requested == nil {
+		p.milestoneCones.requested = make(map[milestone.Index]time.Time)
+	}
+
+	now := time.Now()
+
+	// forget the cones which were not delivered in time
+	for msIndex, deadline := range p.milestoneCones.requested {
+		if now.After(deadline) {
+			delete(p.milestoneCones.requested, msIndex)
+		}
+	}
+
+	for msIndex := startIndex; msIndex <= endIndex; msIndex++ {
+		p.milestoneCones.requested[msIndex] = now.Add(MilestoneConeRequestTimeout)
+	}
+}
+
+// AddMilestoneConeChunk adds the given chunk to the milestone cone which is received from the peer.
+// The chunks have to be added in the order they were received. Returns the milestone cone once its last chunk was added.
+func (p *Peer) AddMilestoneConeChunk(chunk *sting.MilestoneConeChunk) (*MilestoneCone, error) {
+	p.milestoneCones.Lock()
+	defer p.milestoneCones.Unlock()
+
+	cone := p.milestoneCones.receiving
+	if cone == nil || cone.MilestoneIndex != chunk.MilestoneIndex {
+		// the chunks of a milestone cone are sent in a row, so an incomplete cone won't be completed anymore
+		p.milestoneCones.receiving = nil
+
+		deadline, requested := p.milestoneCones.requested[chunk.MilestoneIndex]
+		if !requested || time.Now().After(deadline) {
+			return nil, ErrMilestoneConeNotRequested
+		}
+		delete(p.milestoneCones.requested, chunk.MilestoneIndex)
+
+		cone = &MilestoneCone{MilestoneIndex: chunk.MilestoneIndex}
+		p.milestoneCones.receiving = cone
+	}
+
+	if len(cone.Transactions)+len(chunk.Transactions) > sting.MaxMilestoneConeTransactions {
+		p.milestoneCones.receiving = nil
+		return nil, sting.ErrMilestoneConeTooBig
+	}
+	cone.Transactions = append(cone.Transactions, chunk.Transactions...)
+
+	if !chunk.Last {
+		return nil, nil
+	}
+
+	p.milestoneCones.receiving = nil
+	return cone, nil
+}
@@ -0,0 +1,40 @@
+package peer
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+func TestMilestoneConeChunks(t *testing.T) {
+	p := &Peer{}
+
+	// cones which weren't requested are not accepted
+	_, err := p.AddMilestoneConeChunk(&sting.MilestoneConeChunk{MilestoneIndex: 5, Last: true})
+	require.True(t, errors.Is(err, ErrMilestoneConeNotRequested))
+
+	p.AddRequestedMilestoneCones(10, 11)
+
+	cone, err := p.AddMilestoneConeChunk(&sting.MilestoneConeChunk{MilestoneIndex: 10, Transactions: [][]byte{{1}, {2}}})
+	require.NoError(t, err)
+	require.Nil(t, cone)
+
+	cone, err = p.AddMilestoneConeChunk(&sting.MilestoneConeChunk{MilestoneIndex: 10, Last: true, Transactions: [][]byte{{3}}})
+	require.NoError(t, err)
+	require.Equal(t, &MilestoneCone{MilestoneIndex: 10, Transactions: [][]byte{{1}, {2}, {3}}}, cone)
+
+	// every cone is only accepted once
+	_, err = p.AddMilestoneConeChunk(&sting.MilestoneConeChunk{MilestoneIndex: 10, Last: true})
+	require.True(t, errors.Is(err, ErrMilestoneConeNotRequested))
+
+	// the size of a cone is limited
+	chunk := &sting.MilestoneConeChunk{MilestoneIndex: 11, Transactions: make([][]byte, sting.MaxMilestoneConeTransactions)}
+	_, err = p.AddMilestoneConeChunk(chunk)
+	require.NoError(t, err)
+
+	_, err = p.AddMilestoneConeChunk(&sting.MilestoneConeChunk{MilestoneIndex: 11, Last: true, Transactions: [][]byte{{1}}})
+	require.True(t, errors.Is(err, sting.ErrMilestoneConeTooBig))
+}
@@ -1,9 +1,12 @@
 package peer
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/atomic"
@@ -31,8 +34,16 @@ const (
 	Outbound
 )
 
+// String returns the name of the connection origin.
+func (origin ConnectionOrigin) String() string {
+	if origin == Inbound {
+		return "inbound"
+	}
+	return "outbound"
+}
+
 const (
-	// SendQueueSize defines the size of the send queue of every created peer.
+	// SendQueueSize defines the size of the send queue per priority of every created peer.
 	SendQueueSize = 1500
 	// CheckStaledAutopeerInterval is the interval autopeered neighbors
 	// are checked whether they are staled.
@@ -47,6 +58,10 @@ func OriginAddressCaller(handler interface{}, params ...interface{}) {
 	handler.(func(*iputils.OriginAddress))(params[0].(*iputils.OriginAddress))
 }
 
+func DurationCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*Peer, time.Duration))(params[0].(*Peer), params[1].(time.Duration))
+}
+
 func IdentityCaller(handler interface{}, params ...interface{}) {
 	handler.(func(identity.ID))(params[0].(identity.ID))
 }
@@ -62,7 +77,8 @@ func NewInboundPeer(remoteAddr net.Addr) *Peer {
 		PrimaryAddress:   primaryAddr,
 		Addresses:        addresses,
 		ConnectionOrigin: Inbound,
-		SendQueue:        make(chan []byte, SendQueueSize),
+		SendQueue:        NewSendQueue(SendQueueSize),
+		Score:            NewScore(),
 		Events: Events{
 			HeartbeatUpdated: events.NewEvent(sting.HeartbeatCaller),
 		},
@@ -78,7 +94,8 @@ func NewOutboundPeer(originAddr *iputils.OriginAddress, primaryAddr net.IP, port
 		Addresses:               addresses,
 		MoveBackToReconnectPool: true,
 		ConnectionOrigin:        Outbound,
-		SendQueue:               make(chan []byte, SendQueueSize),
+		SendQueue:               NewSendQueue(SendQueueSize),
+		Score:                   NewScore(),
 		Events: Events{
 			HeartbeatUpdated: events.NewEvent(sting.HeartbeatCaller),
 		},
@@ -104,8 +121,12 @@ type Peer struct {
 	Addresses *iputils.IPAddresses
 	// The protocol instance under which this peer operates.
 	Protocol *protocol.Protocol
+	// The identity key the peer presented on an encrypted connection (nil if the connection is unencrypted).
+	PublicKey ed25519.PublicKey
 	// Metrics about the peer.
 	Metrics Metrics
+	// The reputation of the peer.
+	Score *Score
 	// Whether the connection for this peer was handled inbound or was created outbound.
 	ConnectionOrigin ConnectionOrigin
 	// Whether to place this peer back into the reconnect pool when the connection is closed.
@@ -120,11 +141,25 @@ type Peer struct {
 	HeartbeatSentTime time.Time
 	// Holds the autopeering info if this peer was added via autopeering.
 	Autopeering *peer.Peer
-	// A channel which contains messages to be sent to the given peer.
-	SendQueue chan []byte
+	// The prioritized queue which contains messages to be sent to the given peer.
+	SendQueue *SendQueue
+	// Limits the upload rate to the peer (nil = unlimited).
+	UploadLimiter *utils.RateLimiter
+	// Limits the download rate from the peer (nil = unlimited).
+	DownloadLimiter *utils.RateLimiter
+	// Limits the amount of milestone cones served to the peer per second (nil = unlimited).
+	MilestoneConeLimiter *utils.RateLimiter
+	// The milestone cones requested from the peer.
+	milestoneCones milestoneCones
+	// The addresses whose transactions the light peer wants to receive.
+	watchedAddresses watchedAddresses
 	// Whether this peer is marked as disconnected.
 	// Used to suppress errors stemming from connection closure.
 	Disconnected bool
+	// The reason why the connection to the peer was closed.
+	disconnectReason atomic.String
+	// Ensures that only the first disconnect reason is kept.
+	disconnectReasonOnce sync.Once
 	// Events happening on the peer.
 	Events Events
 	// The last amount of sent transactions at the last autopeer stale check
@@ -138,6 +173,27 @@ func (p *Peer) IsInbound() bool {
 	return p.ConnectionOrigin == Inbound
 }
 
+// SetDisconnectReason sets the reason why the connection to the peer gets closed.
+// Only the first reason is kept, as subsequent errors are mostly caused by the first one.
+func (p *Peer) SetDisconnectReason(reason string) {
+	p.disconnectReasonOnce.Do(func() {
+		p.disconnectReason.Store(reason)
+	})
+}
+
+// DisconnectReason returns the reason why the connection to the peer was closed.
+func (p *Peer) DisconnectReason() string {
+	if reason := p.disconnectReason.Load(); reason != "" {
+		return reason
+	}
+	return "connection closed"
+}
+
+// IsEncrypted tells whether the connection to the peer is encrypted and authenticated.
+func (p *Peer) IsEncrypted() bool {
+	return p.PublicKey != nil
+}
+
 // CheckStaledAutopeer checks if the maximum percentage of dropped packages is exceeded.
 func (p *Peer) CheckStaledAutopeer(maxPercentage int) (bool, float32) {
 	if maxPercentage == 0 {
@@ -175,13 +231,18 @@ func (p *Peer) CheckStaledAutopeer(maxPercentage int) (bool, float32) {
 }
 
 // EnqueueForSending enqueues the given data to be sent to the peer.
+// The priority is derived from the message type if none is given.
 // If it can't because the send queue is over capacity, the message gets dropped.
-func (p *Peer) EnqueueForSending(data []byte) {
-	select {
-	case p.SendQueue <- data:
-	default:
+func (p *Peer) EnqueueForSending(data []byte, priority ...SendPriority) {
+	prio := messageSendPriority(data)
+	if len(priority) > 0 {
+		prio = priority[0]
+	}
+
+	if !p.SendQueue.Enqueue(data, prio) {
 		metrics.SharedServerMetrics.DroppedMessages.Inc()
 		p.Metrics.DroppedPackets.Inc()
+		p.Score.DroppedPacket()
 	}
 }
 
@@ -217,9 +278,29 @@ func (p *Peer) Info() *Info {
 		info.Autopeered = true
 		info.AutopeeringID = p.Autopeering.ID().String()
 	}
+	if p.IsEncrypted() {
+		info.ConnectionType = "tls"
+		info.PublicKey = hex.EncodeToString(p.PublicKey)
+	}
+	if p.Score != nil {
+		info.Score = p.Score.Value()
+	}
+	if p.SendQueue != nil {
+		info.SendQueueSizes = make(map[string]int, len(SendPriorities))
+		for _, prio := range SendPriorities {
+			info.SendQueueSizes[prio.String()] = p.SendQueue.Len(prio)
+		}
+	}
 	return info
 }
 
+// IsLight tells whether the peer signaled in its latest heartbeat message that it runs in light mode.
+// Light peers don't relay transactions and are only sent the broadcasted transactions of their watched addresses.
+func (p *Peer) IsLight() bool {
+	heartbeat := p.LatestHeartbeat
+	return heartbeat != nil && heartbeat.Light
+}
+
 // HasDataFor tells whether the peer given the latest heartbeat message, has the cone data for the given milestone.
 // Returns false if no heartbeat message was received yet.
 func (p *Peer) HasDataFor(index milestone.Index) bool {
@@ -283,28 +364,32 @@ type Metrics struct {
 
 // Info acts as a static snapshot of information about a peer.
 type Info struct {
-	Peer                           *Peer  `json:"-"`
-	Address                        string `json:"address"`
-	Port                           uint16 `json:"port,omitempty"`
-	Domain                         string `json:"domain,omitempty"`
-	DomainWithPort                 string `json:"-"`
-	Alias                          string `json:"alias,omitempty"`
-	PreferIPv6                     bool   `json:"-"`
-	NumberOfAllTransactions        uint32 `json:"numberOfAllTransactions"`
-	NumberOfNewTransactions        uint32 `json:"numberOfNewTransactions"`
-	NumberOfKnownTransactions      uint32 `json:"numberOfKnownTransactions"`
-	NumberOfStaleTransactions      uint32 `json:"numberOfStaleTransactions"`
-	NumberOfReceivedTransactionReq uint32 `json:"numberOfReceivedTransactionReq"`
-	NumberOfReceivedMilestoneReq   uint32 `json:"numberOfReceivedMilestoneReq"`
-	NumberOfReceivedHeartbeats     uint32 `json:"numberOfReceivedHeartbeats"`
-	NumberOfSentPackets            uint32 `json:"numberOfSentPackets"`
-	NumberOfSentTransactions       uint32 `json:"numberOfSentTransactions"`
-	NumberOfSentTransactionsReq    uint32 `json:"numberOfSentTransactionsReq"`
-	NumberOfSentMilestoneReq       uint32 `json:"numberOfSentMilestoneReq"`
-	NumberOfSentHeartbeats         uint32 `json:"numberOfSentHeartbeats"`
-	NumberOfDroppedSentPackets     uint32 `json:"numberOfDroppedSentPackets"`
-	ConnectionType                 string `json:"connectionType"`
-	Connected                      bool   `json:"connected"`
-	Autopeered                     bool   `json:"autopeered"`
-	AutopeeringID                  string `json:"autopeeringId,omitempty"`
+	Peer                           *Peer          `json:"-"`
+	Address                        string         `json:"address"`
+	Port                           uint16         `json:"port,omitempty"`
+	Domain                         string         `json:"domain,omitempty"`
+	DomainWithPort                 string         `json:"-"`
+	Alias                          string         `json:"alias,omitempty"`
+	PreferIPv6                     bool           `json:"-"`
+	NumberOfAllTransactions        uint32         `json:"numberOfAllTransactions"`
+	NumberOfNewTransactions        uint32         `json:"numberOfNewTransactions"`
+	NumberOfKnownTransactions      uint32         `json:"numberOfKnownTransactions"`
+	NumberOfStaleTransactions      uint32         `json:"numberOfStaleTransactions"`
+	NumberOfReceivedTransactionReq uint32         `json:"numberOfReceivedTransactionReq"`
+	NumberOfReceivedMilestoneReq   uint32         `json:"numberOfReceivedMilestoneReq"`
+	NumberOfReceivedHeartbeats     uint32         `json:"numberOfReceivedHeartbeats"`
+	NumberOfSentPackets            uint32         `json:"numberOfSentPackets"`
+	NumberOfSentTransactions       uint32         `json:"numberOfSentTransactions"`
+	NumberOfSentTransactionsReq    uint32         `json:"numberOfSentTransactionsReq"`
+	NumberOfSentMilestoneReq       uint32         `json:"numberOfSentMilestoneReq"`
+	NumberOfSentHeartbeats         uint32         `json:"numberOfSentHeartbeats"`
+	NumberOfDroppedSentPackets     uint32         `json:"numberOfDroppedSentPackets"`
+	ConnectionType                 string         `json:"connectionType"`
+	Connected                      bool           `json:"connected"`
+	Autopeered                     bool           `json:"autopeered"`
+	AutopeeringID                  string         `json:"autopeeringId,omitempty"`
+	PublicKey                      string         `json:"publicKey,omitempty"`
+	PinnedPublicKey                string         `json:"pinnedPublicKey,omitempty"`
+	Score                          float64        `json:"score"`
+	SendQueueSizes                 map[string]int `json:"sendQueueSizes,omitempty"`
 }
@@ -0,0 +1,209 @@
+package peer
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// the reward for each new transaction received from the peer.
+	newTransactionReward = 0.01
+	// the maximum reward for new transactions.
+	maxNewTransactionsReward = 50.0
+	// the penalty for each stale transaction received from the peer.
+	staleTransactionPenalty = 0.1
+	// the penalty for each invalid transaction received from the peer.
+	invalidTransactionPenalty = 50.0
+	// the penalty for each packet which couldn't be sent to the peer.
+	droppedPacketPenalty = 0.01
+	// the maximum penalty for dropped packets.
+	maxDroppedPacketsPenalty = 25.0
+	// the penalty for each check in which the latest heartbeat of the peer was outdated.
+	missedHeartbeatPenalty = 10.0
+	// the request latency up to which no penalty is given.
+	requestLatencyTolerance = time.Second
+	// the penalty for each second of average request latency above the tolerance.
+	requestLatencyPenaltyPerSecond = 5.0
+	// the maximum penalty for the request latency.
+	maxRequestLatencyPenalty = 25.0
+	// the weight of a new sample in the moving average of the request latency.
+	requestLatencySampleWeight = 0.1
+	// the request latency samples are capped to this value.
+	maxRequestLatencySample = 10 * time.Second
+
+	// MaxScore is the highest score a peer can reach.
+	MaxScore = 100.0
+	// MinScore is the lowest score a peer can reach.
+	MinScore = -100.0
+)
+
+// NewScore creates a new neutral score.
+func NewScore() *Score {
+	return &Score{}
+}
+
+// Score is the reputation of a peer built from several signals about its behavior.
+// The score is kept across reconnects of the peer.
+type Score struct {
+	mu sync.RWMutex
+	ScoreSignals
+}
+
+// ScoreSignals are the recorded signals a score is computed from.
+type ScoreSignals struct {
+	// The decayed number of received transactions which were new.
+	NewTransactions float64 `json:"newTransactions"`
+	// The decayed number of received transactions of which their timestamp was stale.
+	StaleTransactions float64 `json:"staleTransactions"`
+	// The decayed number of received invalid transactions.
+	InvalidTransactions float64 `json:"invalidTransactions"`
+	// The decayed number of packets which couldn't be sent to the peer.
+	DroppedPackets float64 `json:"droppedPackets"`
+	// The decayed number of checks in which the latest heartbeat of the peer was outdated.
+	MissedHeartbeats float64 `json:"missedHeartbeats"`
+	// The moving average of the time it took the peer to answer requests.
+	RequestLatency time.Duration `json:"requestLatency"`
+	// The number of times the peer was blacklisted in a row.
+	Blacklistings int `json:"blacklistings"`
+	// The time the peer was blacklisted the last time.
+	LastBlacklisted time.Time `json:"lastBlacklisted"`
+}
+
+// MarshalJSON returns the JSON encoding of the recorded signals.
+func (s *Score) MarshalJSON() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return json.Marshal(s.ScoreSignals)
+}
+
+// UnmarshalJSON restores the recorded signals from their JSON encoding.
+func (s *Score) UnmarshalJSON(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.Unmarshal(data, &s.ScoreSignals)
+}
+
+// NewTransaction records a new transaction received from the peer.
+func (s *Score) NewTransaction() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.NewTransactions++
+}
+
+// StaleTransaction records a stale transaction received from the peer.
+func (s *Score) StaleTransaction() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.StaleTransactions++
+}
+
+// InvalidTransaction records an invalid transaction received from the peer.
+func (s *Score) InvalidTransaction() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.InvalidTransactions++
+}
+
+// DroppedPacket records a packet which couldn't be sent to the peer.
+func (s *Score) DroppedPacket() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.DroppedPackets++
+}
+
+// MissedHeartbeat records that the latest heartbeat of the peer was outdated.
+func (s *Score) MissedHeartbeat() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MissedHeartbeats++
+}
+
+// RequestAnswered records the time it took the peer to answer a request.
+func (s *Score) RequestAnswered(latency time.Duration) {
+	if latency > maxRequestLatencySample {
+		latency = maxRequestLatencySample
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.RequestLatency == 0 {
+		s.RequestLatency = latency
+		return
+	}
+	s.RequestLatency = time.Duration((1-requestLatencySampleWeight)*float64(s.RequestLatency) + requestLatencySampleWeight*float64(latency))
+}
+
+// Blacklisted records that the peer was blacklisted and returns the duration of the blacklisting.
+// The duration doubles with every blacklisting in a row, up to the given maximum.
+// The backoff is reset if the peer wasn't blacklisted within twice the maximum duration.
+func (s *Score) Blacklisted(baseDuration time.Duration, maxDuration time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.LastBlacklisted) > 2*maxDuration {
+		s.Blacklistings = 0
+	}
+	s.Blacklistings++
+	s.LastBlacklisted = time.Now()
+
+	duration := baseDuration
+	for i := 1; i < s.Blacklistings && duration < maxDuration; i++ {
+		duration *= 2
+	}
+	if duration > maxDuration {
+		duration = maxDuration
+	}
+	return duration
+}
+
+// BlacklistedWithin tells whether the peer was blacklisted within the given duration.
+func (s *Score) BlacklistedWithin(duration time.Duration) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Blacklistings > 0 && time.Since(s.LastBlacklisted) <= duration
+}
+
+// Restore replaces the recorded signals with the ones of the given score.
+func (s *Score) Restore(other *Score) {
+	other.mu.RLock()
+	signals := other.ScoreSignals
+	other.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ScoreSignals = signals
+}
+
+// Decay reduces the weight of the recorded signals by the given factor,
+// so that the score reflects the recent behavior of the peer.
+func (s *Score) Decay(factor float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.NewTransactions *= factor
+	s.StaleTransactions *= factor
+	s.InvalidTransactions *= factor
+	s.DroppedPackets *= factor
+	s.MissedHeartbeats *= factor
+}
+
+// Value returns the current score of the peer in the range of MinScore to MaxScore.
+func (s *Score) Value() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value := math.Min(s.NewTransactions*newTransactionReward, maxNewTransactionsReward)
+	value -= s.StaleTransactions * staleTransactionPenalty
+	value -= s.InvalidTransactions * invalidTransactionPenalty
+	value -= math.Min(s.DroppedPackets*droppedPacketPenalty, maxDroppedPacketsPenalty)
+	value -= s.MissedHeartbeats * missedHeartbeatPenalty
+
+	if s.RequestLatency > requestLatencyTolerance {
+		value -= math.Min((s.RequestLatency-requestLatencyTolerance).Seconds()*requestLatencyPenaltyPerSecond, maxRequestLatencyPenalty)
+	}
+
+	return math.Max(MinScore, math.Min(MaxScore, value))
+}
@@ -0,0 +1,81 @@
+package peer
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreValue(t *testing.T) {
+
+	score := NewScore()
+	require.Equal(t, 0.0, score.Value())
+
+	for i := 0; i < 1000; i++ {
+		score.NewTransaction()
+	}
+	require.InDelta(t, 10.0, score.Value(), 0.001)
+
+	score.StaleTransaction()
+	score.DroppedPacket()
+	require.Less(t, score.Value(), 10.0)
+
+	score.InvalidTransaction()
+	require.Less(t, score.Value(), 0.0)
+
+	score.InvalidTransaction()
+	score.InvalidTransaction()
+	require.Equal(t, MinScore, score.Value())
+
+	// the penalties fade out over time
+	for i := 0; i < 200; i++ {
+		score.Decay(0.95)
+	}
+	require.InDelta(t, 0.0, score.Value(), 0.01)
+}
+
+func TestScoreRequestLatency(t *testing.T) {
+
+	score := NewScore()
+	score.RequestAnswered(500 * time.Millisecond)
+	require.Equal(t, 0.0, score.Value())
+
+	for i := 0; i < 100; i++ {
+		score.RequestAnswered(time.Minute)
+	}
+	require.Equal(t, -maxRequestLatencyPenalty, score.Value())
+}
+
+func TestScoreBlacklistBackoff(t *testing.T) {
+
+	score := NewScore()
+	require.Equal(t, time.Minute, score.Blacklisted(time.Minute, time.Hour))
+	require.Equal(t, 2*time.Minute, score.Blacklisted(time.Minute, time.Hour))
+	require.Equal(t, 4*time.Minute, score.Blacklisted(time.Minute, time.Hour))
+
+	for i := 0; i < 10; i++ {
+		score.Blacklisted(time.Minute, time.Hour)
+	}
+	require.Equal(t, time.Hour, score.Blacklisted(time.Minute, time.Hour))
+
+	// the backoff is reset after a long time without blacklistings
+	score.LastBlacklisted = time.Now().Add(-3 * time.Hour)
+	require.Equal(t, time.Minute, score.Blacklisted(time.Minute, time.Hour))
+}
+
+func TestScoreJSON(t *testing.T) {
+
+	score := NewScore()
+	score.NewTransaction()
+	score.InvalidTransaction()
+	score.RequestAnswered(2 * time.Second)
+
+	data, err := json.Marshal(map[string]*Score{"127.0.0.1:15600": score})
+	require.NoError(t, err)
+
+	restored := make(map[string]*Score)
+	require.NoError(t, json.Unmarshal(data, &restored))
+	require.Equal(t, score.Value(), restored["127.0.0.1:15600"].Value())
+}
@@ -0,0 +1,122 @@
+package peer
+
+import (
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+// SendPriority defines the priority of a message in the send queue of a peer.
+type SendPriority byte
+
+const (
+	// SendPriorityControl is used for heartbeats and requests.
+	SendPriorityControl SendPriority = iota
+	// SendPriorityMilestone is used for milestones and milestone cones.
+	SendPriorityMilestone
+	// SendPriorityResponse is used for transactions requested by the peer.
+	SendPriorityResponse
+	// SendPriorityBroadcast is used for broadcasted transactions.
+	SendPriorityBroadcast
+
+	sendPriorityCount
+)
+
+// String returns the name of the priority.
+func (prio SendPriority) String() string {
+	switch prio {
+	case SendPriorityControl:
+		return "control"
+	case SendPriorityMilestone:
+		return "milestone"
+	case SendPriorityResponse:
+		return "response"
+	case SendPriorityBroadcast:
+		return "broadcast"
+	default:
+		return "unknown"
+	}
+}
+
+// SendPriorities are all priorities ordered from the highest to the lowest.
+var SendPriorities = []SendPriority{SendPriorityControl, SendPriorityMilestone, SendPriorityResponse, SendPriorityBroadcast}
+
+// returns the default priority of the given message.
+func messageSendPriority(data []byte) SendPriority {
+	if len(data) == 0 {
+		return SendPriorityControl
+	}
+
+	switch message.Type(data[0]) {
+	case sting.MessageTypeTransaction:
+		return SendPriorityBroadcast
+	case sting.MessageTypeMilestoneCone:
+		return SendPriorityMilestone
+	default:
+		return SendPriorityControl
+	}
+}
+
+// NewSendQueue creates a new SendQueue with the given capacity per priority.
+func NewSendQueue(size int) *SendQueue {
+	q := &SendQueue{signal: make(chan struct{}, 1)}
+	for i := range q.queues {
+		q.queues[i] = make(chan []byte, size)
+	}
+	return q
+}
+
+// SendQueue holds the messages to be sent to a peer.
+// Messages with a higher priority are always sent first.
+type SendQueue struct {
+	queues [sendPriorityCount]chan []byte
+	signal chan struct{}
+}
+
+// Enqueue adds the given message with the given priority to the queue.
+// Returns false if the queue for the priority is over capacity.
+func (q *SendQueue) Enqueue(data []byte, prio SendPriority) bool {
+	select {
+	case q.queues[prio] <- data:
+	default:
+		return false
+	}
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// Next returns the message with the highest priority and its priority.
+// It blocks until a message is available or the abort signal is triggered.
+func (q *SendQueue) Next(abortSignal <-chan struct{}) ([]byte, SendPriority, bool) {
+	for {
+		for _, prio := range SendPriorities {
+			if data, ok := q.TryNext(prio); ok {
+				return data, prio, true
+			}
+		}
+
+		select {
+		case <-q.signal:
+		case <-abortSignal:
+			return nil, 0, false
+		}
+	}
+}
+
+// TryNext returns the next message with the given priority without blocking.
+func (q *SendQueue) TryNext(prio SendPriority) ([]byte, bool) {
+	select {
+	case data := <-q.queues[prio]:
+		return data, true
+	default:
+		return nil, false
+	}
+}
+
+// Len returns the amount of queued messages with the given priority.
+func (q *SendQueue) Len(prio SendPriority) int {
+	return len(q.queues[prio])
+}
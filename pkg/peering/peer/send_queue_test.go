@@ -0,0 +1,38 @@
+package peer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendQueuePriorities(t *testing.T) {
+
+	q := NewSendQueue(2)
+
+	require.True(t, q.Enqueue([]byte{1}, SendPriorityBroadcast))
+	require.True(t, q.Enqueue([]byte{2}, SendPriorityResponse))
+	require.True(t, q.Enqueue([]byte{3}, SendPriorityMilestone))
+	require.True(t, q.Enqueue([]byte{4}, SendPriorityControl))
+	require.True(t, q.Enqueue([]byte{5}, SendPriorityBroadcast))
+
+	// the queue of a single priority is over capacity
+	require.False(t, q.Enqueue([]byte{6}, SendPriorityBroadcast))
+	require.Equal(t, 2, q.Len(SendPriorityBroadcast))
+
+	abortSignal := make(chan struct{})
+	expectedPriorities := []SendPriority{SendPriorityControl, SendPriorityMilestone, SendPriorityResponse, SendPriorityBroadcast, SendPriorityBroadcast}
+	for i, expected := range []byte{4, 3, 2, 1, 5} {
+		data, prio, ok := q.Next(abortSignal)
+		require.True(t, ok)
+		require.Equal(t, []byte{expected}, data)
+		require.Equal(t, expectedPriorities[i], prio)
+	}
+
+	_, ok := q.TryNext(SendPriorityBroadcast)
+	require.False(t, ok)
+
+	close(abortSignal)
+	_, _, ok = q.Next(abortSignal)
+	require.False(t, ok)
+}
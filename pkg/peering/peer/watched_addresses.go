@@ -0,0 +1,33 @@
+package peer
+
+import (
+	"sync"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+)
+
+// the addresses a light peer announced to be interested in.
+type watchedAddresses struct {
+	sync.RWMutex
+	addresses map[string]struct{}
+}
+
+// SetWatchedAddresses replaces the addresses whose transactions the light peer wants to receive.
+func (p *Peer) SetWatchedAddresses(addresses hornet.Hashes) {
+	watched := make(map[string]struct{}, len(addresses))
+	for _, addr := range addresses {
+		watched[string(addr)] = struct{}{}
+	}
+
+	p.watchedAddresses.Lock()
+	defer p.watchedAddresses.Unlock()
+	p.watchedAddresses.addresses = watched
+}
+
+// WatchesAddress tells whether the peer announced to be interested in the transactions of the given address.
+func (p *Peer) WatchesAddress(addr hornet.Hash) bool {
+	p.watchedAddresses.RLock()
+	defer p.watchedAddresses.RUnlock()
+	_, watched := p.watchedAddresses.addresses[string(addr)]
+	return watched
+}
@@ -1,6 +1,8 @@
 package peering
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
@@ -23,12 +25,16 @@ import (
 	"github.com/gohornet/hornet/pkg/protocol"
 	"github.com/gohornet/hornet/pkg/protocol/handshake"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/utils"
 )
 
 const (
 	isNeighborSyncedThreshold        = 2
 	updateNeighborsCountCooldownTime = time.Duration(2 * time.Second)
 	connectionWriteTimeout           = 5 * time.Second
+
+	disconnectReasonRemoved  = "peer was removed"
+	disconnectReasonShutdown = "node is shutting down"
 )
 
 var (
@@ -48,6 +54,10 @@ var (
 	ErrPeerAlreadyInReconnect = errors.New("peer is already in the reconnect pool")
 	// ErrManagerIsShutdown is returned when the manager is shutdown.
 	ErrManagerIsShutdown = errors.New("peering manager is shutdown")
+	// ErrHandshakeTimeout is returned when a peer didn't complete the handshake in time.
+	ErrHandshakeTimeout = errors.New("handshake timeout")
+	// ErrSubnetLimitReached is returned when too many peers of the same subnet are connected.
+	ErrSubnetLimitReached = errors.New("max peers per subnet reached")
 )
 
 // NewManager creates a new manager instance with the given Options and moves the given peers
@@ -63,6 +73,9 @@ func NewManager(opts Options, peers ...*config.PeerConfig) *Manager {
 			PeerMovedIntoReconnectPool:            events.NewEvent(peer.OriginAddressCaller),
 			PeerMovedFromConnectedToReconnectPool: events.NewEvent(peer.Caller),
 			PeerHandshakingOutgoing:               events.NewEvent(peer.Caller),
+			PeerPenalized:                         events.NewEvent(peer.DurationCaller),
+			PeerHistoryEvent:                      events.NewEvent(peer.HistoryEventCaller),
+			InboundConnectionRejected:             events.NewEvent(RejectionCaller),
 			Reconnecting:                          events.NewEvent(events.Int32Caller),
 			ReconnectRemovedAlreadyConnected:      events.NewEvent(peer.Caller),
 			AutopeeredPeerHandshaking:             events.NewEvent(peer.Caller),
@@ -75,8 +88,13 @@ func NewManager(opts Options, peers ...*config.PeerConfig) *Manager {
 		connected: map[string]*peer.Peer{},
 		reconnect: map[string]*reconnectinfo{},
 		whitelist: map[string]*autopeering.Peer{},
-		blacklist: map[string]struct{}{},
+		pinned:    map[string]ed25519.PublicKey{},
+		blacklist: map[string]time.Time{},
+		scores:    map[string]*peer.Score{},
+		histories: map[string]*peer.History{},
 		Opts:      opts,
+
+		inboundLimiter: newInboundLimiter(),
 	}
 	m.moveInitialPeersToReconnectPool(peers)
 	return m
@@ -103,11 +121,21 @@ type Manager struct {
 	// defines the set of allowed peer identities.
 	whitelist   map[string]*autopeering.Peer
 	whitelistMu sync.Mutex
-	// defines a set of blacklisted IP addresses.
-	blacklist   map[string]struct{}
+	// holds the pinned public keys of the peer identities (guarded by whitelistMu).
+	pinned map[string]ed25519.PublicKey
+	// defines a set of blacklisted IP addresses and until when they are blacklisted (zero = permanently).
+	blacklist   map[string]time.Time
 	blacklistMu sync.Mutex
+	// holds the scores of the known peers by their ID.
+	scores   map[string]*peer.Score
+	scoresMu sync.Mutex
+	// holds the connection lifecycle histories of the peers by their ID.
+	histories   map[string]*peer.History
+	historiesMu sync.Mutex
 	// used to enforce one handshake verification at a time.
 	handshakeVerifyMu sync.Mutex
+	// enforces the inbound connection limits.
+	inboundLimiter *inboundLimiter
 
 	// only used by ConnectedAndSyncedPeerCount
 	connectedNeighborsCount  uint8
@@ -120,6 +148,7 @@ type reconnectinfo struct {
 	OriginAddr  *iputils.OriginAddress `json:"origin_addr"`
 	CachedIPs   *iputils.IPAddresses   `json:"cached_ips"`
 	Autopeering *autopeering.Peer      `json:"peer"`
+	PublicKey   ed25519.PublicKey      `json:"public_key"`
 }
 
 // Options defines options for the Manager.
@@ -131,6 +160,20 @@ type Options struct {
 	AcceptAnyPeer bool
 	// Inbound connection bind address.
 	BindAddress string
+	// The identity used for encrypted connections (nil = encryption disabled).
+	Identity *Identity
+	// Whether to reject unencrypted connections.
+	RequireEncryption bool
+	// The options for the peer reputation.
+	Reputation ReputationOptions
+	// The max amount of bytes per second sent to a single peer (0 = unlimited).
+	MaxUploadBytesPerSecond int
+	// The max amount of bytes per second received from a single peer (0 = unlimited).
+	MaxDownloadBytesPerSecond int
+	// The max amount of events kept in the connection lifecycle history of a peer.
+	HistorySize int
+	// The limits for connections to protect against connection floods.
+	InboundLimits InboundLimitOptions
 }
 
 // Events defines events fired regarding peering.
@@ -153,6 +196,12 @@ type Events struct {
 	PeerHandshakingOutgoing *events.Event
 	// Fired when the handshaking phase of an incoming peer connection is initiated.
 	PeerHandshakingIncoming *events.Event
+	// Fired when a peer was disconnected and blacklisted temporarily because of its behavior.
+	PeerPenalized *events.Event
+	// Fired when an event in the connection lifecycle of a peer was recorded.
+	PeerHistoryEvent *events.Event
+	// Fired when an inbound connection was rejected because of the inbound limits.
+	InboundConnectionRejected *events.Event
 	// Fired when the handshaking phase with an outbound autopeered peer is initiated.
 	AutopeeredPeerHandshaking *events.Event
 	// Fired when an autopeered peer was added as a static neighbor.
@@ -209,15 +258,45 @@ func (m *Manager) IsStaticallyPeered(ips []string, port uint16) bool {
 // Blacklisted tells whether the given IP address is blacklisted.
 func (m *Manager) Blacklisted(ip string) bool {
 	m.blacklistMu.Lock()
-	_, blacklisted := m.blacklist[ip]
-	m.blacklistMu.Unlock()
-	return blacklisted
+	defer m.blacklistMu.Unlock()
+
+	until, blacklisted := m.blacklist[ip]
+	if !blacklisted {
+		return false
+	}
+
+	if !until.IsZero() && time.Now().After(until) {
+		// the temporary blacklisting expired
+		delete(m.blacklist, ip)
+		return false
+	}
+	return true
+}
+
+// temporarilyBlacklisted tells whether the given IP address is blacklisted temporarily.
+func (m *Manager) temporarilyBlacklisted(ip string) bool {
+	m.blacklistMu.Lock()
+	defer m.blacklistMu.Unlock()
+
+	// permanent blacklistings have a zero time
+	until, blacklisted := m.blacklist[ip]
+	return blacklisted && time.Now().Before(until)
+}
+
+// removes the permanent blacklist entry for the given IP address, temporary blacklistings are kept.
+func (m *Manager) permanentBlacklistRemove(ip string) {
+	m.blacklistMu.Lock()
+	defer m.blacklistMu.Unlock()
+
+	if until, blacklisted := m.blacklist[ip]; blacklisted && until.IsZero() {
+		delete(m.blacklist, ip)
+	}
 }
 
 // Blacklist blacklists the given IP from connecting.
 func (m *Manager) Blacklist(ip string) {
 	m.blacklistMu.Lock()
-	m.blacklist[ip] = struct{}{}
+	m.blacklist[ip] = time.Time{}
 	m.blacklistMu.Unlock()
 }
 
@@ -256,9 +335,33 @@ func (m *Manager) Whitelist(ips []string, port uint16, autopeeringPeer ...*autop
 func (m *Manager) WhitelistRemove(id string) {
 	m.whitelistMu.Lock()
 	delete(m.whitelist, id)
+	delete(m.pinned, id)
 	m.whitelistMu.Unlock()
 }
 
+// PinPublicKey pins the public key for all possible IDs for the given IP addresses/port combination.
+// Connections to these IDs have to be encrypted and authenticated by the given key.
+// A nil key removes the pinned keys.
+func (m *Manager) PinPublicKey(ips []string, port uint16, publicKey ed25519.PublicKey) {
+	m.whitelistMu.Lock()
+	defer m.whitelistMu.Unlock()
+	for _, ip := range ips {
+		id := peer.NewID(ip, port)
+		if publicKey == nil {
+			delete(m.pinned, id)
+			continue
+		}
+		m.pinned[id] = publicKey
+	}
+}
+
+// PinnedPublicKey returns the pinned public key for the given ID, or nil if none is pinned.
+func (m *Manager) PinnedPublicKey(id string) ed25519.PublicKey {
+	m.whitelistMu.Lock()
+	defer m.whitelistMu.Unlock()
+	return m.pinned[id]
+}
+
 // PeerConsumerFunc is a function which consumes a peer.
 // If it returns false, it signals that no further calls should be made to the function.
 type PeerConsumerFunc func(p *peer.Peer) bool
@@ -326,6 +429,9 @@ func (m *Manager) PeerInfos() []*peer.Info {
 	for _, p := range m.connected {
 		info := p.Info()
 		info.Connected = true
+		if pinnedPublicKey := m.PinnedPublicKey(p.ID); pinnedPublicKey != nil {
+			info.PinnedPublicKey = hex.EncodeToString(pinnedPublicKey)
+		}
 		infos = append(infos, info)
 	}
 	for _, reconnectInfo := range m.reconnect {
@@ -345,6 +451,17 @@ func (m *Manager) PeerInfos() []*peer.Info {
 			info.Autopeered = true
 			info.AutopeeringID = reconnectInfo.Autopeering.ID().String()
 		}
+		if reconnectInfo.PublicKey != nil {
+			info.PinnedPublicKey = hex.EncodeToString(reconnectInfo.PublicKey)
+		}
+		if reconnectInfo.CachedIPs != nil {
+			for ip := range reconnectInfo.CachedIPs.IPs {
+				if score := m.knownScore(peer.NewID(ip.String(), originAddr.Port)); score != nil {
+					info.Score = score.Value()
+					break
+				}
+			}
+		}
 		infos = append(infos, info)
 	}
 	return infos
@@ -385,7 +502,8 @@ func (m *Manager) ConnectedAndSyncedPeerCount() (uint8, uint8) {
 			m.connectedNeighborsCount++
 		}
 
-		if p.LatestHeartbeat == nil {
+		// light peers are not counted as synced, since they don't relay transactions
+		if p.LatestHeartbeat == nil || p.LatestHeartbeat.Light {
 			continue
 		}
 
@@ -429,12 +547,25 @@ func (m *Manager) SlotsFilled() bool {
 // SetupEventHandlers inits the event handlers for handshaking, the underlying connection and errors.
 func (m *Manager) SetupEventHandlers(p *peer.Peer) {
 
-	onProtocolReceive := events.NewClosure(p.Protocol.Receive)
+	p.UploadLimiter = utils.NewRateLimiter(m.Opts.MaxUploadBytesPerSecond)
+	p.DownloadLimiter = utils.NewRateLimiter(m.Opts.MaxDownloadBytesPerSecond)
+
+	// closed once the connection is closed, which also happens on shutdown
+	closeSignal := make(chan struct{})
+
+	onProtocolReceive := events.NewClosure(func(data []byte) {
+		// throttles reading from the connection if the peer exceeds the download limit
+		if !p.DownloadLimiter.Wait(len(data), closeSignal) {
+			return
+		}
+		p.Protocol.Receive(data)
+	})
 
 	onConnectionError := events.NewClosure(func(err error) {
 		if p.Disconnected {
 			return
 		}
+		p.SetDisconnectReason(err.Error())
 		m.Events.Error.Trigger(err)
 		if closeErr := p.Conn.Close(); closeErr != nil {
 			m.Events.Error.Trigger(closeErr)
@@ -445,6 +576,7 @@ func (m *Manager) SetupEventHandlers(p *peer.Peer) {
 		if p.Disconnected {
 			return
 		}
+		p.SetDisconnectReason(err.Error())
 		m.Events.Error.Trigger(err)
 		if closeErr := p.Conn.Close(); closeErr != nil {
 			m.Events.Error.Trigger(closeErr)
@@ -452,10 +584,17 @@ func (m *Manager) SetupEventHandlers(p *peer.Peer) {
 	})
 
 	onConnectionClose := events.NewClosure(func() {
+		close(closeSignal)
+
 		m.Lock()
 		m.moveFromConnectedToReconnectPool(p)
 		m.Unlock()
 
+		// the ID of inbound peers is only known after the handshake, failed handshakes are recorded separately
+		if !p.IsInbound() || p.Handshaked() {
+			m.recordHistoryEvent(p.ID, peer.HistoryEventDisconnected, p.DisconnectReason())
+		}
+
 		p.Conn.Events.ReceiveData.Detach(onProtocolReceive)
 		p.Conn.Events.Error.Detach(onConnectionError)
 		p.Protocol.Events.Error.Detach(onProtocolError)
@@ -474,17 +613,30 @@ func (m *Manager) SetupEventHandlers(p *peer.Peer) {
 	p.Conn.Events.Close.Attach(onConnectionClose)
 
 	m.setupHandshakeEventHandlers(p)
+
+	// drop connections which don't complete the handshake in time
+	m.enforceHandshakeTimeout(p)
 }
 
 // Add adds a new peer to the reconnect pool and immediately invokes a connection attempt.
+// If a hex encoded public key is given, the connection to the peer is encrypted and authenticated by that key.
 // The peer is not added if it is already connected or the given address is invalid.
-func (m *Manager) Add(addr string, preferIPv6 bool, alias string, autoPeer ...*autopeering.Peer) error {
+func (m *Manager) Add(addr string, preferIPv6 bool, alias string, publicKeyHex string, autoPeer ...*autopeering.Peer) error {
 
 	originAddr, err := iputils.ParseOriginAddress(addr)
 	if err != nil {
 		return fmt.Errorf("invalid peer address '%s': %w", addr, err)
 	}
 
+	publicKey, err := ParsePublicKey(publicKeyHex)
+	if err != nil {
+		return fmt.Errorf("invalid public key for peer '%s': %w", addr, err)
+	}
+
+	if err := m.checkPinnedPublicKey(publicKey); err != nil {
+		return fmt.Errorf("can't add peer '%s': %w", addr, err)
+	}
+
 	originAddr.PreferIPv6 = preferIPv6
 	originAddr.Alias = alias
 
@@ -546,8 +698,15 @@ func (m *Manager) Add(addr string, preferIPv6 bool, alias string, autoPeer ...*a
 		}
 	}
 
+	// a peer which was removed before is allowed to connect again after it was added explicitly
+	if !isAutopeer {
+		for ip := range possibleIPs.IPs {
+			m.permanentBlacklistRemove(ip.String())
+		}
+	}
+
 	// construct reconnect info
-	reconnectInfo := &reconnectinfo{OriginAddr: originAddr, CachedIPs: possibleIPs}
+	reconnectInfo := &reconnectinfo{OriginAddr: originAddr, CachedIPs: possibleIPs, PublicKey: publicKey}
 	if isAutopeer {
 		reconnectInfo.Autopeering = autoPeer[0]
 	}
@@ -579,6 +738,7 @@ func (m *Manager) Remove(id string) error {
 			if p, exists := m.connected[otherID]; exists {
 				p.MoveBackToReconnectPool = false
 				delete(m.connected, otherID)
+				p.SetDisconnectReason(disconnectReasonRemoved)
 				p.Disconnected = true
 				if p.Protocol != nil && p.Conn != nil {
 					_ = p.Conn.Close()
@@ -602,6 +762,7 @@ func (m *Manager) Remove(id string) error {
 		if id != p.InitAddress.String() {
 			continue
 		}
+		p.SetDisconnectReason(disconnectReasonRemoved)
 		p.Disconnected = true
 		p.MoveBackToReconnectPool = false
 		delete(m.connected, id)
@@ -637,7 +798,20 @@ func (m *Manager) Listen() error {
 
 	m.tcpServer.Events.Connect.Attach(events.NewClosure(func(conn *network.ManagedConnection) {
 		tcpConn := conn.RemoteAddr().(*net.TCPAddr)
-		if m.Blacklisted(tcpConn.IP.String()) {
+		ip := tcpConn.IP.String()
+
+		rejectionReason := RejectionReason("")
+		switch {
+		case m.Blacklisted(ip):
+			rejectionReason = RejectionReasonBlacklisted
+		case !m.allowConnectionAttempt(ip):
+			rejectionReason = RejectionReasonConnectionRate
+		case !m.acquireHandshakeSlot():
+			rejectionReason = RejectionReasonMaxHandshaking
+		}
+
+		if rejectionReason != "" {
+			m.reject(ip, rejectionReason)
 			if err := conn.Close(); err != nil {
 				log.Error(err)
 			}
@@ -646,12 +820,27 @@ func (m *Manager) Listen() error {
 
 		m.Events.PeerHandshakingIncoming.Trigger(conn.RemoteAddr().String())
 
+		// upgrade the connection if the peer initiated an encrypted transport
+		transportConn, publicKey, err := m.acceptTransport(conn.Conn)
+		if err != nil {
+			m.inboundLimiter.handshaking.Dec()
+			m.Events.Error.Trigger(err)
+			_ = conn.Close()
+			return
+		}
+		if transportConn != conn.Conn {
+			conn = network.NewManagedConnection(transportConn)
+		}
+
 		// init peer
 		p := peer.NewInboundPeer(conn.Conn.RemoteAddr())
 		p.Conn = conn
 		p.Conn.SetWriteTimeout(connectionWriteTimeout)
+		p.PublicKey = publicKey
 		p.Protocol = protocol.New(conn)
+		p.Protocol.Encrypted = p.IsEncrypted()
 		m.SetupEventHandlers(p)
+		m.releaseHandshakeSlotOnCompletion(p)
 
 		// kick off protocol
 		go p.Protocol.Start()
@@ -682,6 +871,7 @@ func (m *Manager) Shutdown() {
 	// close connections
 	for k, p := range m.connected {
 		p.MoveBackToReconnectPool = false
+		p.SetDisconnectReason(disconnectReasonShutdown)
 		p.Disconnected = true
 		// we don't care about errors while shutting down
 		_ = p.Conn.Close()
@@ -721,7 +911,7 @@ func (m *Manager) moveFromConnectedToReconnectPool(p *peer.Peer) {
 	// remove any other excess reconnect entry
 	m.removeFromReconnectPool(p)
 
-	m.reconnect[p.InitAddress.String()] = &reconnectinfo{OriginAddr: p.InitAddress, CachedIPs: p.Addresses}
+	m.reconnect[p.InitAddress.String()] = &reconnectinfo{OriginAddr: p.InitAddress, CachedIPs: p.Addresses, PublicKey: m.PinnedPublicKey(p.ID)}
 	m.Events.PeerMovedFromConnectedToReconnectPool.Trigger(p)
 }
 
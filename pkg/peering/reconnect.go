@@ -1,9 +1,9 @@
 package peering
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"net"
-	"time"
 
 	"github.com/iotaledger/hive.go/iputils"
 	"github.com/iotaledger/hive.go/network"
@@ -43,6 +43,11 @@ next:
 
 		prefIP := peerAddrs.GetPreferredAddress(originAddr.PreferIPv6)
 
+		// wait until the temporary blacklisting of a penalized peer expired
+		if m.temporarilyBlacklisted(prefIP.String()) {
+			continue
+		}
+
 		// don't do any new connection attempts if the peer is already connected
 		ips := make([]string, 0)
 		for ip := range peerAddrs.IPs {
@@ -57,6 +62,7 @@ next:
 
 		// whitelist all possible combinations for this peer ID
 		m.Whitelist(ips, reconnectInfo.OriginAddr.Port)
+		m.PinPublicKey(ips, reconnectInfo.OriginAddr.Port, reconnectInfo.PublicKey)
 
 		// create a new outbound peer and inject autopeering metadata if available
 		p := peer.NewOutboundPeer(originAddr, prefIP, originAddr.Port, peerAddrs)
@@ -73,11 +79,14 @@ next:
 		m.moveFromReconnectPoolToHandshaking(p)
 		m.Unlock()
 
+		m.recordHistoryEvent(p.ID, peer.HistoryEventHandshaking, "")
+
 		if p.Autopeering != nil {
 			m.Events.AutopeeredPeerHandshaking.Trigger(p)
 		}
 
-		if err := m.connect(p); err != nil {
+		if err := m.connect(p, m.PinnedPublicKey(p.ID)); err != nil {
+			m.recordHistoryEvent(p.ID, peer.HistoryEventConnectionFailed, err.Error())
 			m.Events.Error.Trigger(err)
 			m.Lock()
 			m.moveFromConnectedToReconnectPool(p)
@@ -107,22 +116,34 @@ func (m *Manager) moveInitialPeersToReconnectPool(peers []*config.PeerConfig) {
 		originAddr.PreferIPv6 = peerConf.PreferIPv6
 		originAddr.Alias = peerConf.Alias
 
+		publicKey, err := ParsePublicKey(peerConf.PublicKey)
+		if err != nil {
+			panic(errors.Wrapf(err, "invalid public key for peer %s", peerConf.ID))
+		}
+
+		if err := m.checkPinnedPublicKey(publicKey); err != nil {
+			panic(errors.Wrapf(err, "peer %s", peerConf.ID))
+		}
+
 		// no need to lock the manager in the configure stage
-		m.moveToReconnectPool(&reconnectinfo{OriginAddr: originAddr})
+		m.moveToReconnectPool(&reconnectinfo{OriginAddr: originAddr, PublicKey: publicKey})
 	}
 }
 
 // creates and initiates the connection to the given peer.
-func (m *Manager) connect(p *peer.Peer) error {
+// the connection is encrypted if a public key is pinned for the peer.
+func (m *Manager) connect(p *peer.Peer, pinnedPublicKey ed25519.PublicKey) error {
 	addr := fmt.Sprintf("%s:%d", iputils.IPToString(p.PrimaryAddress), p.InitAddress.Port)
-	conn, err := net.DialTimeout("tcp", addr, time.Duration(2)*time.Second)
+	conn, publicKey, err := m.dialTransport(addr, pinnedPublicKey)
 	if err != nil {
 		return fmt.Errorf("can't connect to %s: %w", p.ID, err)
 	}
 
 	p.Conn = network.NewManagedConnection(conn)
 	p.Conn.SetWriteTimeout(connectionWriteTimeout)
+	p.PublicKey = publicKey
 	p.Protocol = protocol.New(p.Conn)
+	p.Protocol.Encrypted = p.IsEncrypted()
 	return nil
 }
 
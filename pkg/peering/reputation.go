@@ -0,0 +1,209 @@
+package peering
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+const (
+	// the factor by which the recorded signals of the peer scores decay on every check.
+	scoreDecayFactor = 0.95
+)
+
+var (
+	// ErrPeerScoresCorrupted is returned when the persisted peer scores can't be parsed.
+	ErrPeerScoresCorrupted = errors.New("peer scores corrupted")
+)
+
+// ReputationOptions define options for the peer reputation.
+type ReputationOptions struct {
+	// The score below which a peer gets disconnected and blacklisted temporarily.
+	BlacklistThreshold float64
+	// The duration of the first blacklisting of a peer.
+	BlacklistDuration time.Duration
+	// The maximum duration of a blacklisting.
+	MaxBlacklistDuration time.Duration
+}
+
+// Score returns the score of the peer with the given ID.
+// A new neutral score is created if the peer is not known yet.
+func (m *Manager) Score(id string) *peer.Score {
+	m.scoresMu.Lock()
+	defer m.scoresMu.Unlock()
+
+	score, exists := m.scores[id]
+	if !exists {
+		score = peer.NewScore()
+		m.scores[id] = score
+	}
+	return score
+}
+
+// restoreScore keeps the score of the given peer across reconnects by restoring the signals which were
+// recorded under its ID into the peer's score. The peer's score is tracked under its ID afterwards.
+// The score of the peer is restored instead of replaced, as it is already in use by other goroutines.
+func (m *Manager) restoreScore(p *peer.Peer) {
+	m.scoresMu.Lock()
+	defer m.scoresMu.Unlock()
+
+	if score, exists := m.scores[p.ID]; exists && score != p.Score {
+		p.Score.Restore(score)
+	}
+	m.scores[p.ID] = p.Score
+}
+
+// knownScore returns the score of the peer with the given ID or nil if the peer is not known.
+func (m *Manager) knownScore(id string) *peer.Score {
+	m.scoresMu.Lock()
+	defer m.scoresMu.Unlock()
+	return m.scores[id]
+}
+
+// Penalize disconnects the given peer and blacklists its IP addresses temporarily.
+// The blacklist duration doubles with every penalty of the peer in a row.
+// Static peers are kept in the reconnect pool and reconnected after the blacklisting expired.
+func (m *Manager) Penalize(p *peer.Peer) {
+	duration := p.Score.Blacklisted(m.Opts.Reputation.BlacklistDuration, m.Opts.Reputation.MaxBlacklistDuration)
+
+	until := time.Now().Add(duration)
+	m.blacklistMu.Lock()
+	for ip := range p.Addresses.IPs {
+		if blacklistedUntil, blacklisted := m.blacklist[ip.String()]; blacklisted && blacklistedUntil.IsZero() {
+			// keep permanent blacklistings
+			continue
+		}
+		m.blacklist[ip.String()] = until
+	}
+	m.blacklistMu.Unlock()
+
+	m.recordHistoryEvent(p.ID, peer.HistoryEventBlacklisted, fmt.Sprintf("penalized with a score of %.2f for %v", p.Score.Value(), duration))
+	m.Events.PeerPenalized.Trigger(p, duration)
+
+	// the connection close handler moves static peers back into the reconnect pool
+	m.Lock()
+	p.SetDisconnectReason("peer was penalized")
+	p.Disconnected = true
+	if p.Conn != nil {
+		_ = p.Conn.Close()
+	}
+	m.Unlock()
+}
+
+// CheckScores decays the scores of all known peers and penalizes
+// the connected peers whose score dropped below the blacklist threshold.
+// The scores of peers which are neither connected nor whitelisted are removed,
+// unless the peer was blacklisted recently and its blacklisting backoff still applies.
+func (m *Manager) CheckScores() {
+	connectedIDs := make(map[string]struct{})
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		connectedIDs[p.ID] = struct{}{}
+		return true
+	})
+
+	m.scoresMu.Lock()
+	for id, score := range m.scores {
+		score.Decay(scoreDecayFactor)
+
+		if _, connected := connectedIDs[id]; connected {
+			continue
+		}
+		if _, whitelisted := m.Whitelisted(id); whitelisted {
+			continue
+		}
+		if score.BlacklistedWithin(2 * m.Opts.Reputation.MaxBlacklistDuration) {
+			continue
+		}
+		delete(m.scores, id)
+	}
+	m.scoresMu.Unlock()
+
+	var peersToPenalize []*peer.Peer
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		if p.Score.Value() < m.Opts.Reputation.BlacklistThreshold {
+			peersToPenalize = append(peersToPenalize, p)
+		}
+		return true
+	})
+
+	for _, p := range peersToPenalize {
+		m.Penalize(p)
+	}
+}
+
+// ForAllConnectedByScore executes the given function for each currently connected peer,
+// starting with the peer with the highest score, until abort is returned from within the
+// consumer function. The consumer function is only called on peers who are handshaked.
+func (m *Manager) ForAllConnectedByScore(f PeerConsumerFunc) {
+	type scoredPeer struct {
+		p     *peer.Peer
+		score float64
+	}
+
+	var peers []scoredPeer
+	m.ForAllConnected(func(p *peer.Peer) bool {
+		peers = append(peers, scoredPeer{p: p, score: p.Score.Value()})
+		return true
+	})
+
+	sort.SliceStable(peers, func(i, j int) bool {
+		return peers[i].score > peers[j].score
+	})
+
+	for _, scored := range peers {
+		if !f(scored.p) {
+			return
+		}
+	}
+}
+
+// LoadScores loads the persisted peer scores from the given file.
+// Missing files are ignored.
+func (m *Manager) LoadScores(filePath string) error {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	scores := make(map[string]*peer.Score)
+	if err := json.Unmarshal(data, &scores); err != nil {
+		return errors.Wrap(ErrPeerScoresCorrupted, err.Error())
+	}
+
+	m.scoresMu.Lock()
+	defer m.scoresMu.Unlock()
+	for id, score := range scores {
+		if score == nil {
+			continue
+		}
+		m.scores[id] = score
+	}
+	return nil
+}
+
+// StoreScores persists the peer scores to the given file.
+func (m *Manager) StoreScores(filePath string) error {
+	m.scoresMu.Lock()
+	data, err := json.MarshalIndent(m.scores, "", "  ")
+	m.scoresMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first to not corrupt the scores on crashes
+	filePathTmp := filePath + "_tmp"
+	if err := ioutil.WriteFile(filePathTmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(filePathTmp, filePath)
+}
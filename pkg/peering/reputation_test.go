@@ -0,0 +1,69 @@
+package peering
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+)
+
+func TestRestoreScore(t *testing.T) {
+	m := NewManager(Options{})
+
+	stored := m.Score("127.0.0.1:15600")
+	stored.InvalidTransaction()
+
+	// the score of a reconnected peer is restored into the score it already uses
+	p := &peer.Peer{ID: "127.0.0.1:15600", Score: peer.NewScore()}
+	score := p.Score
+	m.restoreScore(p)
+
+	require.True(t, score == p.Score)
+	require.Equal(t, stored.Value(), p.Score.Value())
+	require.True(t, m.knownScore(p.ID) == p.Score)
+}
+
+func TestCheckScoresEvictsUnknownPeers(t *testing.T) {
+	m := NewManager(Options{Reputation: ReputationOptions{
+		BlacklistThreshold:   -50,
+		BlacklistDuration:    time.Minute,
+		MaxBlacklistDuration: time.Hour,
+	}})
+
+	m.Whitelist([]string{"10.0.0.1"}, 15600)
+	m.Score(peer.NewID("10.0.0.1", 15600))
+	m.Score(peer.NewID("10.0.0.2", 15600))
+	m.Score(peer.NewID("10.0.0.3", 15600)).Blacklisted(time.Minute, time.Hour)
+
+	m.CheckScores()
+
+	// the whitelisted peer and the recently blacklisted peer are kept
+	require.NotNil(t, m.knownScore(peer.NewID("10.0.0.1", 15600)))
+	require.Nil(t, m.knownScore(peer.NewID("10.0.0.2", 15600)))
+	require.NotNil(t, m.knownScore(peer.NewID("10.0.0.3", 15600)))
+}
+
+func TestStoreScoresFileMode(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "scores")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	m := NewManager(Options{})
+	m.Score("127.0.0.1:15600").NewTransaction()
+
+	filePath := filepath.Join(tempDir, "scores.json")
+	require.NoError(t, m.StoreScores(filePath))
+
+	info, err := os.Stat(filePath)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded := NewManager(Options{})
+	require.NoError(t, loaded.LoadScores(filePath))
+	require.Equal(t, m.Score("127.0.0.1:15600").Value(), loaded.Score("127.0.0.1:15600").Value())
+}
@@ -0,0 +1,131 @@
+package source
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/config"
+)
+
+var (
+	// ErrNoAddressesFound is returned when a DNS lookup didn't return any addresses.
+	ErrNoAddressesFound = errors.New("no addresses found")
+)
+
+// NewDNSSource creates a new source which resolves the IP addresses of the host of the given "host:port" address.
+// Every resolved IP address is a peer with the given port.
+func NewDNSSource(addr string, preferIPv6 bool) (*DNSSource, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid DNS peer source '%s'", addr)
+	}
+
+	return &DNSSource{
+		host:       host,
+		port:       port,
+		preferIPv6: preferIPv6,
+		lookupIP:   net.LookupIP,
+	}, nil
+}
+
+// DNSSource resolves the peers from the A and AAAA records of a host.
+type DNSSource struct {
+	host       string
+	port       string
+	preferIPv6 bool
+	lookupIP   func(host string) ([]net.IP, error)
+}
+
+// Name returns the name of the source.
+func (s *DNSSource) Name() string {
+	return "dns://" + net.JoinHostPort(s.host, s.port)
+}
+
+// Peers resolves the IP addresses of the host.
+// Only addresses of the preferred IP version are used, if there are any.
+func (s *DNSSource) Peers() ([]*config.PeerConfig, error) {
+	ips, err := s.lookupIP(s.host)
+	if err != nil {
+		return nil, err
+	}
+
+	var ipv4, ipv6 []net.IP
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			ipv4 = append(ipv4, ip)
+			continue
+		}
+		ipv6 = append(ipv6, ip)
+	}
+
+	preferred, others := ipv4, ipv6
+	if s.preferIPv6 {
+		preferred, others = ipv6, ipv4
+	}
+	if len(preferred) == 0 {
+		preferred = others
+	}
+
+	if len(preferred) == 0 {
+		return nil, errors.Wrapf(ErrNoAddressesFound, "host '%s'", s.host)
+	}
+
+	peers := make([]*config.PeerConfig, 0, len(preferred))
+	for _, ip := range preferred {
+		peers = append(peers, &config.PeerConfig{
+			ID:         net.JoinHostPort(ip.String(), s.port),
+			Alias:      s.host,
+			PreferIPv6: s.preferIPv6,
+		})
+	}
+	return peers, nil
+}
+
+// NewSRVSource creates a new source which resolves the peers from the SRV records of the given name,
+// e.g. "_hornet._tcp.example.com".
+func NewSRVSource(name string, preferIPv6 bool) *SRVSource {
+	return &SRVSource{
+		name:       name,
+		preferIPv6: preferIPv6,
+		lookupSRV:  net.LookupSRV,
+	}
+}
+
+// SRVSource resolves the peers from SRV records.
+type SRVSource struct {
+	name       string
+	preferIPv6 bool
+	lookupSRV  func(service, proto, name string) (string, []*net.SRV, error)
+}
+
+// Name returns the name of the source.
+func (s *SRVSource) Name() string {
+	return "srv://" + s.name
+}
+
+// Peers resolves the targets of the SRV records.
+// The addresses of the targets are resolved by the peering manager.
+func (s *SRVSource) Peers() ([]*config.PeerConfig, error) {
+	_, records, err := s.lookupSRV("", "", s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, errors.Wrapf(ErrNoAddressesFound, "SRV name '%s'", s.name)
+	}
+
+	peers := make([]*config.PeerConfig, 0, len(records))
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		peers = append(peers, &config.PeerConfig{
+			ID:         net.JoinHostPort(target, strconv.Itoa(int(record.Port))),
+			Alias:      target,
+			PreferIPv6: s.preferIPv6,
+		})
+	}
+	return peers, nil
+}
@@ -0,0 +1,152 @@
+package source
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/config"
+)
+
+const (
+	// the maximum size of a seed list.
+	maxSeedListBytesLength = 1 << 20
+	// the timeout for fetching a seed list from a URL.
+	seedListFetchTimeout = 30 * time.Second
+)
+
+var (
+	// ErrInvalidSeedListSignature is returned when the signature of a seed list is invalid.
+	ErrInvalidSeedListSignature = errors.New("invalid seed list signature")
+	// ErrSeedListTooBig is returned when a seed list exceeds the maximum size.
+	ErrSeedListTooBig = errors.New("seed list too big")
+	// ErrSeedListFetchFailed is returned when a seed list couldn't be fetched from a URL.
+	ErrSeedListFetchFailed = errors.New("fetching seed list failed")
+)
+
+// SignedSeedList is the format of a seed list.
+// The signature is the hex encoded ed25519 signature of the peers field in compact JSON encoding,
+// so the seed list can be formatted without breaking the signature.
+type SignedSeedList struct {
+	Peers     json.RawMessage `json:"peers"`
+	Signature string          `json:"signature"`
+}
+
+// SignSeedList creates a signed seed list of the given peers.
+func SignSeedList(peers []*config.PeerConfig, privateKey ed25519.PrivateKey) ([]byte, error) {
+	peersBytes, err := json.Marshal(peers)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(&SignedSeedList{
+		Peers:     peersBytes,
+		Signature: hex.EncodeToString(ed25519.Sign(privateKey, peersBytes)),
+	}, "", "  ")
+}
+
+// ParseSeedList verifies the signature of the given seed list and returns the contained peers.
+func ParseSeedList(data []byte, publicKey ed25519.PublicKey) ([]*config.PeerConfig, error) {
+	seedList := &SignedSeedList{}
+	if err := json.Unmarshal(data, seedList); err != nil {
+		return nil, errors.Wrap(err, "invalid seed list")
+	}
+
+	signature, err := hex.DecodeString(seedList.Signature)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, ErrInvalidSeedListSignature
+	}
+
+	var peersBytes bytes.Buffer
+	if err := json.Compact(&peersBytes, seedList.Peers); err != nil {
+		return nil, errors.Wrap(err, "invalid seed list")
+	}
+
+	if !ed25519.Verify(publicKey, peersBytes.Bytes(), signature) {
+		return nil, ErrInvalidSeedListSignature
+	}
+
+	var peers []*config.PeerConfig
+	if err := json.Unmarshal(seedList.Peers, &peers); err != nil {
+		return nil, errors.Wrap(err, "invalid seed list")
+	}
+	return peers, nil
+}
+
+// NewSeedListSource creates a new source which loads a signed seed list from the given file path or HTTP(S) URL.
+func NewSeedListSource(location string, publicKey ed25519.PublicKey) *SeedListSource {
+	return &SeedListSource{
+		location:  location,
+		publicKey: publicKey,
+		client:    &http.Client{Timeout: seedListFetchTimeout},
+	}
+}
+
+// SeedListSource loads the peers from a signed seed list.
+type SeedListSource struct {
+	location  string
+	publicKey ed25519.PublicKey
+	client    *http.Client
+}
+
+// Name returns the name of the source.
+func (s *SeedListSource) Name() string {
+	return s.location
+}
+
+// Peers loads the seed list and verifies its signature.
+func (s *SeedListSource) Peers() ([]*config.PeerConfig, error) {
+	data, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSeedList(data, s.publicKey)
+}
+
+// loads the raw seed list from the file or URL.
+func (s *SeedListSource) load() ([]byte, error) {
+	if !strings.HasPrefix(s.location, "http://") && !strings.HasPrefix(s.location, "https://") {
+		f, err := os.Open(s.location)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return readLimited(f)
+	}
+
+	res, err := s.client.Get(s.location)
+	if err != nil {
+		return nil, errors.Wrap(ErrSeedListFetchFailed, err.Error())
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Wrapf(ErrSeedListFetchFailed, "status code %d", res.StatusCode)
+	}
+
+	return readLimited(res.Body)
+}
+
+// reads the seed list from the given reader up to the maximum size.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxSeedListBytesLength+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxSeedListBytesLength {
+		return nil, ErrSeedListTooBig
+	}
+	return data, nil
+}
@@ -0,0 +1,14 @@
+package source
+
+import (
+	"github.com/gohornet/hornet/pkg/config"
+)
+
+// Source provides a set of static peers which may change over time,
+// e.g. because they are resolved from DNS records or fetched from a seed list.
+type Source interface {
+	// Name returns the name of the source, used to identify it in log messages.
+	Name() string
+	// Peers returns the current peers of the source.
+	Peers() ([]*config.PeerConfig, error)
+}
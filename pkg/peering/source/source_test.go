@@ -0,0 +1,154 @@
+package source
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"net"
+	"sort"
+	"testing"
+
+	autopeering "github.com/iotaledger/hive.go/autopeering/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering"
+)
+
+type fakeManager struct {
+	peers map[string]*config.PeerConfig
+}
+
+func (m *fakeManager) Add(addr string, preferIPv6 bool, alias string, publicKeyHex string, _ ...*autopeering.Peer) error {
+	if _, exists := m.peers[addr]; exists {
+		return peering.ErrPeerAlreadyInReconnect
+	}
+	m.peers[addr] = &config.PeerConfig{ID: addr, Alias: alias, PreferIPv6: preferIPv6, PublicKey: publicKeyHex}
+	return nil
+}
+
+func (m *fakeManager) Remove(id string) error {
+	delete(m.peers, id)
+	return nil
+}
+
+type fakeSource struct {
+	name  string
+	peers []*config.PeerConfig
+	err   error
+}
+
+func (s *fakeSource) Name() string {
+	if s.name != "" {
+		return s.name
+	}
+	return "fake"
+}
+
+func (s *fakeSource) Peers() ([]*config.PeerConfig, error) {
+	return s.peers, s.err
+}
+
+func TestDNSSource(t *testing.T) {
+	dnsSource, err := NewDNSSource("peers.example.com:15600", false)
+	require.NoError(t, err)
+
+	dnsSource.lookupIP = func(host string) ([]net.IP, error) {
+		require.Equal(t, "peers.example.com", host)
+		return []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("fd00::1"), net.ParseIP("10.0.0.2")}, nil
+	}
+
+	peers, err := dnsSource.Peers()
+	require.NoError(t, err)
+	require.Len(t, peers, 2)
+	require.Equal(t, "10.0.0.1:15600", peers[0].ID)
+	require.Equal(t, "10.0.0.2:15600", peers[1].ID)
+
+	dnsSource.preferIPv6 = true
+	peers, err = dnsSource.Peers()
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	require.Equal(t, "[fd00::1]:15600", peers[0].ID)
+
+	_, err = NewDNSSource("peers.example.com", false)
+	require.Error(t, err)
+}
+
+func TestSeedList(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	peers := []*config.PeerConfig{{ID: "10.0.0.1:15600", Alias: "first"}, {ID: "node.example.com:15600", Alias: "second"}}
+	seedList, err := SignSeedList(peers, privateKey)
+	require.NoError(t, err)
+
+	parsed, err := ParseSeedList(seedList, publicKey)
+	require.NoError(t, err)
+	require.Equal(t, peers, parsed)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, err = ParseSeedList(seedList, otherPublicKey)
+	require.True(t, errors.Is(err, ErrInvalidSeedListSignature))
+}
+
+func TestSyncer(t *testing.T) {
+	manager := &fakeManager{peers: map[string]*config.PeerConfig{
+		// configured by other means
+		"10.0.0.3:15600": {ID: "10.0.0.3:15600"},
+	}}
+
+	fake := &fakeSource{peers: []*config.PeerConfig{{ID: "10.0.0.1:15600"}, {ID: "10.0.0.2:15600"}, {ID: "10.0.0.3:15600"}}}
+	syncer := NewSyncer(manager, fake)
+
+	result := syncer.Sync()
+	sort.Strings(result.Added)
+	require.Equal(t, []string{"10.0.0.1:15600", "10.0.0.2:15600"}, result.Added)
+	require.Empty(t, result.Errors)
+	require.True(t, syncer.Contains("10.0.0.1:15600"))
+	require.False(t, syncer.Contains("10.0.0.3:15600"))
+
+	// the peers of a failing source are kept
+	fake.err = errors.New("lookup failed")
+	result = syncer.Sync()
+	require.Empty(t, result.Added)
+	require.Empty(t, result.Removed)
+	require.Len(t, result.Errors, 1)
+	require.Len(t, manager.peers, 3)
+
+	// vanished peers are removed, modified ones are re-added, peers added by other means are kept
+	fake.err = nil
+	fake.peers = []*config.PeerConfig{{ID: "10.0.0.2:15600", PublicKey: "pinned"}}
+	result = syncer.Sync()
+	sort.Strings(result.Removed)
+	require.Equal(t, []string{"10.0.0.1:15600", "10.0.0.2:15600"}, result.Removed)
+	require.Equal(t, []string{"10.0.0.2:15600"}, result.Added)
+	require.Len(t, manager.peers, 2)
+	require.Equal(t, "pinned", manager.peers["10.0.0.2:15600"].PublicKey)
+	require.Contains(t, manager.peers, "10.0.0.3:15600")
+
+	// a changed alias doesn't cause a reconnect
+	fake.peers = []*config.PeerConfig{{ID: "10.0.0.2:15600", Alias: "renamed", PublicKey: "pinned"}}
+	result = syncer.Sync()
+	require.Empty(t, result.Added)
+	require.Empty(t, result.Removed)
+}
+
+func TestSyncerSourceOrder(t *testing.T) {
+	manager := &fakeManager{peers: make(map[string]*config.PeerConfig)}
+
+	first := &fakeSource{name: "first", peers: []*config.PeerConfig{{ID: "10.0.0.1:15600", PublicKey: "first"}}}
+	second := &fakeSource{name: "second", peers: []*config.PeerConfig{{ID: "10.0.0.1:15600", PublicKey: "second"}, {ID: "10.0.0.2:15600"}}}
+	syncer := NewSyncer(manager, first, second)
+
+	// the first source which lists a peer decides its config, the peers are added in the order of the sources
+	for i := 0; i < 10; i++ {
+		result := syncer.Sync()
+		if i == 0 {
+			require.Equal(t, []string{"10.0.0.1:15600", "10.0.0.2:15600"}, result.Added)
+		} else {
+			require.Empty(t, result.Added)
+			require.Empty(t, result.Removed)
+		}
+		require.Equal(t, "first", manager.peers["10.0.0.1:15600"].PublicKey)
+	}
+}
@@ -0,0 +1,151 @@
+package source
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	autopeering "github.com/iotaledger/hive.go/autopeering/peer"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering"
+)
+
+// PeerManager adds and removes the peers of the sources.
+type PeerManager interface {
+	Add(addr string, preferIPv6 bool, alias string, publicKeyHex string, autoPeer ...*autopeering.Peer) error
+	Remove(id string) error
+}
+
+// SyncResult is the outcome of a synchronization of the peers of the sources.
+type SyncResult struct {
+	// The IDs of the added peers.
+	Added []string
+	// The IDs of the removed peers.
+	Removed []string
+	// The errors of the sources which couldn't be queried and of the peers which couldn't be added or removed.
+	Errors []error
+}
+
+// NewSyncer creates a new Syncer for the given sources.
+func NewSyncer(manager PeerManager, sources ...Source) *Syncer {
+	return &Syncer{
+		manager: manager,
+		sources: sources,
+		results: make(map[string][]*config.PeerConfig),
+		applied: make(map[string]*config.PeerConfig),
+	}
+}
+
+// Syncer keeps the peers of the peering manager in sync with the peers of the sources.
+// Only peers added by the Syncer are removed by it, so peers which are also configured
+// in the peering config or added through the API are left untouched.
+type Syncer struct {
+	mu      sync.RWMutex
+	manager PeerManager
+	sources []Source
+	// the latest successful results of the sources by their name.
+	results map[string][]*config.PeerConfig
+	// the peers added by the syncer by their ID.
+	applied map[string]*config.PeerConfig
+}
+
+// Sync queries all sources and adds new, removes vanished and re-adds modified peers.
+// Peers are matched by their ID. If several sources list the same peer, the first source wins.
+// If a source can't be queried, its latest known peers are kept.
+func (s *Syncer) Sync() *SyncResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &SyncResult{}
+
+	for _, source := range s.sources {
+		peers, err := source.Peers()
+		if err != nil {
+			result.Errors = append(result.Errors, errors.Wrapf(err, "querying peer source %s failed", source.Name()))
+			continue
+		}
+		s.results[source.Name()] = peers
+	}
+
+	// merge the peers in the order of the sources, so the first source which lists a peer decides its config
+	var desiredIDs []string
+	desired := make(map[string]*config.PeerConfig)
+	for _, source := range s.sources {
+		for _, p := range s.results[source.Name()] {
+			if p.ID == "" {
+				continue
+			}
+			id := strings.ToLower(p.ID)
+			if _, exists := desired[id]; exists {
+				continue
+			}
+			desired[id] = p
+			desiredIDs = append(desiredIDs, id)
+		}
+	}
+
+	appliedIDs := make([]string, 0, len(s.applied))
+	for id := range s.applied {
+		appliedIDs = append(appliedIDs, id)
+	}
+	sort.Strings(appliedIDs)
+
+	// remove vanished and modified peers
+	for _, id := range appliedIDs {
+		p := s.applied[id]
+		if desiredPeer, exists := desired[id]; exists && sameConnection(desiredPeer, p) {
+			continue
+		}
+
+		if err := s.manager.Remove(p.ID); err != nil {
+			result.Errors = append(result.Errors, errors.Wrapf(err, "removing peer %s failed", p.ID))
+			continue
+		}
+		delete(s.applied, id)
+		result.Removed = append(result.Removed, p.ID)
+	}
+
+	// add new and modified peers
+	for _, id := range desiredIDs {
+		if _, exists := s.applied[id]; exists {
+			continue
+		}
+		p := desired[id]
+
+		if err := s.manager.Add(p.ID, p.PreferIPv6, p.Alias, p.PublicKey); err != nil {
+			if errors.Is(err, peering.ErrPeerAlreadyConnected) || errors.Is(err, peering.ErrPeerAlreadyInReconnect) {
+				// the peer was added by other means
+				continue
+			}
+			result.Errors = append(result.Errors, errors.Wrapf(err, "adding peer %s failed", p.ID))
+			continue
+		}
+
+		peerCopy := *p
+		s.applied[id] = &peerCopy
+		result.Added = append(result.Added, p.ID)
+	}
+
+	return result
+}
+
+// tells whether the given peer configs describe the same connection.
+// the alias is only informational, so a changed alias doesn't cause a reconnect.
+func sameConnection(a *config.PeerConfig, b *config.PeerConfig) bool {
+	return a.PreferIPv6 == b.PreferIPv6 && a.PublicKey == b.PublicKey
+}
+
+// Contains tells whether any of the given addresses belongs to a peer added by the Syncer.
+func (s *Syncer) Contains(addresses ...string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, addr := range addresses {
+		if _, exists := s.applied[strings.ToLower(addr)]; exists {
+			return true
+		}
+	}
+	return false
+}
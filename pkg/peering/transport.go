@@ -0,0 +1,335 @@
+package peering
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/handshake"
+)
+
+const (
+	// the first byte of a TLS record containing a handshake message.
+	tlsRecordTypeHandshake = 0x16
+	// the timeout for the transport negotiation of a new connection.
+	transportHandshakeTimeout = 5 * time.Second
+	// the PEM block type of the identity private key.
+	identityPEMBlockType = "PRIVATE KEY"
+)
+
+var (
+	// ErrInvalidPublicKey is returned when a pinned public key is invalid.
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	// ErrInvalidIdentity is returned when the identity private key could not be loaded.
+	ErrInvalidIdentity = errors.New("invalid gossip identity")
+	// ErrEncryptionDisabled is returned when an encrypted connection is needed, but no identity is configured.
+	ErrEncryptionDisabled = errors.New("encrypted transport is disabled")
+	// ErrEncryptionRequired is returned when a peer connected without an encrypted transport.
+	ErrEncryptionRequired = errors.New("encrypted transport is required")
+	// ErrPublicKeyMismatch is returned when the identity key of a peer doesn't match the pinned key.
+	ErrPublicKeyMismatch = errors.New("public key doesn't match the pinned key")
+	// ErrTransportMismatch is returned when the peer reports a different transport than the one used for the connection.
+	ErrTransportMismatch = errors.New("transport reported by the peer doesn't match the connection")
+)
+
+// Identity is the key pair used to authenticate the node on encrypted gossip connections.
+type Identity struct {
+	PrivateKey  ed25519.PrivateKey
+	certificate tls.Certificate
+}
+
+// PublicKey returns the public key of the identity.
+func (i *Identity) PublicKey() ed25519.PublicKey {
+	return i.PrivateKey.Public().(ed25519.PublicKey)
+}
+
+// PublicKeyHex returns the hex encoded public key of the identity, which has to be pinned by the peers.
+func (i *Identity) PublicKeyHex() string {
+	return hex.EncodeToString(i.PublicKey())
+}
+
+// NewIdentity creates an identity with a self-signed certificate for the given private key.
+// The certificate is only used to transport the public key, peers are authenticated by pinning the key.
+func NewIdentity(privateKey ed25519.PrivateKey) (*Identity, error) {
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(100, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidIdentity, err.Error())
+	}
+
+	return &Identity{
+		PrivateKey: privateKey,
+		certificate: tls.Certificate{
+			Certificate: [][]byte{certDER},
+			PrivateKey:  privateKey,
+		},
+	}, nil
+}
+
+// LoadOrCreateIdentity loads the identity private key from the given PEM file.
+// A new key is generated and stored if the file doesn't exist.
+func LoadOrCreateIdentity(path string) (*Identity, error) {
+
+	pemBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, errors.Wrapf(ErrInvalidIdentity, "unable to read '%s': %v", path, err)
+		}
+
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidIdentity, err.Error())
+		}
+
+		keyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidIdentity, err.Error())
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, errors.Wrapf(ErrInvalidIdentity, "unable to create dir for '%s': %v", path, err)
+		}
+
+		if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: identityPEMBlockType, Bytes: keyDER}), 0600); err != nil {
+			return nil, errors.Wrapf(ErrInvalidIdentity, "unable to write '%s': %v", path, err)
+		}
+
+		return NewIdentity(privateKey)
+	}
+
+	block, _ := pem.Decode(pemBytes)
+	if block == nil || block.Type != identityPEMBlockType {
+		return nil, errors.Wrapf(ErrInvalidIdentity, "no private key found in '%s'", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidIdentity, "unable to parse '%s': %v", path, err)
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.Wrapf(ErrInvalidIdentity, "'%s' doesn't contain an ed25519 private key", path)
+	}
+
+	return NewIdentity(privateKey)
+}
+
+// ParsePublicKey parses a hex encoded ed25519 public key. An empty string results in a nil key.
+func ParsePublicKey(publicKeyHex string) (ed25519.PublicKey, error) {
+	if publicKeyHex == "" {
+		return nil, nil
+	}
+
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.Wrapf(ErrInvalidPublicKey, "'%s'", publicKeyHex)
+	}
+
+	return publicKey, nil
+}
+
+// peerPublicKey extracts the ed25519 public key of the peer's certificate.
+func peerPublicKey(rawCerts [][]byte) (ed25519.PublicKey, error) {
+	if len(rawCerts) == 0 {
+		return nil, errors.Wrap(ErrInvalidPublicKey, "no certificate presented")
+	}
+
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidPublicKey, err.Error())
+	}
+
+	publicKey, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.Wrap(ErrInvalidPublicKey, "certificate doesn't contain an ed25519 public key")
+	}
+
+	return publicKey, nil
+}
+
+// tlsConfig returns the TLS configuration for gossip connections.
+// If a pinned key is given, the handshake fails if the peer doesn't present that key.
+func (i *Identity) tlsConfig(pinnedPublicKey ed25519.PublicKey) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{i.certificate},
+		MinVersion:   tls.VersionTLS13,
+		ClientAuth:   tls.RequireAnyClientCert,
+		// the certificates are self-signed, the peers are authenticated by their pinned public keys
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			publicKey, err := peerPublicKey(rawCerts)
+			if err != nil {
+				return err
+			}
+
+			if pinnedPublicKey != nil && !bytes.Equal(publicKey, pinnedPublicKey) {
+				return errors.Wrapf(ErrPublicKeyMismatch, "got %s, expected %s", hex.EncodeToString(publicKey), hex.EncodeToString(pinnedPublicKey))
+			}
+			return nil
+		},
+	}
+}
+
+// connectionPublicKey returns the identity key the peer presented during the TLS handshake.
+func connectionPublicKey(conn *tls.Conn) ed25519.PublicKey {
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	publicKey, _ := state.PeerCertificates[0].PublicKey.(ed25519.PublicKey)
+	return publicKey
+}
+
+// peekedConn is a connection of which the first bytes were already read to detect the transport.
+type peekedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// acceptTransport detects whether an inbound connection starts with a TLS handshake and upgrades it accordingly.
+// It returns the connection to use for the gossip protocol and the identity key of the peer (nil if unencrypted).
+func (m *Manager) acceptTransport(conn net.Conn) (net.Conn, ed25519.PublicKey, error) {
+
+	if m.Opts.Identity == nil {
+		return conn, nil, nil
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(transportHandshakeTimeout)); err != nil {
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	firstByte, err := reader.Peek(1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to detect transport of %s: %w", conn.RemoteAddr(), err)
+	}
+
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, nil, err
+	}
+
+	peeked := &peekedConn{Conn: conn, reader: reader}
+	if firstByte[0] != tlsRecordTypeHandshake {
+		// plain gossip protocol, the first byte is the type of the handshake message
+		return peeked, nil, nil
+	}
+
+	// the identity key of inbound peers is checked against the pinned key after the gossip handshake,
+	// since the ID of the peer is not known before.
+	tlsConn := tls.Server(peeked, m.Opts.Identity.tlsConfig(nil))
+	if err := tlsConn.SetDeadline(time.Now().Add(transportHandshakeTimeout)); err != nil {
+		return nil, nil, err
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, nil, fmt.Errorf("TLS handshake with %s failed: %w", conn.RemoteAddr(), err)
+	}
+	if err := tlsConn.SetDeadline(time.Time{}); err != nil {
+		return nil, nil, err
+	}
+
+	return tlsConn, connectionPublicKey(tlsConn), nil
+}
+
+// dialTransport creates an outbound connection to the given address.
+// The connection is encrypted if a public key is pinned for the peer.
+func (m *Manager) dialTransport(addr string, pinnedPublicKey ed25519.PublicKey) (net.Conn, ed25519.PublicKey, error) {
+
+	if pinnedPublicKey != nil && m.Opts.Identity == nil {
+		return nil, nil, errors.Wrapf(ErrEncryptionDisabled, "a public key is pinned for %s", addr)
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, time.Duration(2)*time.Second)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if pinnedPublicKey == nil {
+		return conn, nil, nil
+	}
+
+	tlsConn := tls.Client(conn, m.Opts.Identity.tlsConfig(pinnedPublicKey))
+	if err := tlsConn.SetDeadline(time.Now().Add(transportHandshakeTimeout)); err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("TLS handshake with %s failed: %w", addr, err)
+	}
+	if err := tlsConn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+
+	return tlsConn, connectionPublicKey(tlsConn), nil
+}
+
+// checkPinnedPublicKey checks whether a connection to a peer with the given pinned public key
+// is possible with the configured transport options.
+func (m *Manager) checkPinnedPublicKey(publicKey ed25519.PublicKey) error {
+	if publicKey != nil && m.Opts.Identity == nil {
+		return errors.Wrap(ErrEncryptionDisabled, "a public key is pinned")
+	}
+
+	// outbound connections are only encrypted if a public key is pinned
+	if publicKey == nil && m.Opts.RequireEncryption {
+		return errors.Wrap(ErrEncryptionRequired, "no public key is pinned")
+	}
+
+	return nil
+}
+
+// verifyTransport checks the transport of a handshaked peer against the pinned public key and the transport policy.
+func (m *Manager) verifyTransport(p *peer.Peer, handshakeMsg *handshake.Handshake) error {
+
+	encrypted := p.IsEncrypted()
+
+	// a peer that reports a different transport indicates a middlebox that terminates or strips the encryption
+	if handshakeMsg.HasExtension(handshake.ExtensionEncryptedTransport) != encrypted {
+		return errors.Wrapf(ErrTransportMismatch, "peer %s reported encrypted transport: %v", p.ID, !encrypted)
+	}
+
+	if pinnedPublicKey := m.PinnedPublicKey(p.ID); pinnedPublicKey != nil {
+		if !encrypted {
+			return errors.Wrapf(ErrEncryptionRequired, "a public key is pinned for %s", p.ID)
+		}
+		if !bytes.Equal(p.PublicKey, pinnedPublicKey) {
+			return errors.Wrapf(ErrPublicKeyMismatch, "peer %s presented %s", p.ID, hex.EncodeToString(p.PublicKey))
+		}
+		return nil
+	}
+
+	if m.Opts.RequireEncryption && !encrypted {
+		return errors.Wrapf(ErrEncryptionRequired, "peer %s", p.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package peering
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/handshake"
+)
+
+func newTestIdentity(t *testing.T) *Identity {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	identity, err := NewIdentity(privateKey)
+	require.NoError(t, err)
+	return identity
+}
+
+type acceptResult struct {
+	conn      net.Conn
+	publicKey ed25519.PublicKey
+	err       error
+}
+
+// listens for a single inbound connection and upgrades it with the transport of the given manager.
+func acceptOnce(t *testing.T, m *Manager) (string, <-chan *acceptResult) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	resultChan := make(chan *acceptResult, 1)
+	go func() {
+		defer listener.Close()
+
+		conn, err := listener.Accept()
+		if err != nil {
+			resultChan <- &acceptResult{err: err}
+			return
+		}
+
+		transportConn, publicKey, err := m.acceptTransport(conn)
+		if err != nil {
+			conn.Close()
+		}
+		resultChan <- &acceptResult{conn: transportConn, publicKey: publicKey, err: err}
+	}()
+
+	return listener.Addr().String(), resultChan
+}
+
+func TestTransportPinnedPublicKey(t *testing.T) {
+	identityServer := newTestIdentity(t)
+	identityClient := newTestIdentity(t)
+
+	server := NewManager(Options{Identity: identityServer})
+	client := NewManager(Options{Identity: identityClient})
+
+	addr, resultChan := acceptOnce(t, server)
+
+	conn, publicKey, err := client.dialTransport(addr, identityServer.PublicKey())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, identityServer.PublicKey(), publicKey)
+
+	result := <-resultChan
+	require.NoError(t, result.err)
+	defer result.conn.Close()
+	require.Equal(t, identityClient.PublicKey(), result.publicKey)
+
+	// the encrypted connection transports the gossip protocol
+	_, err = conn.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+
+	buf := make([]byte, 3)
+	_, err = result.conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, buf)
+}
+
+func TestTransportPublicKeyMismatch(t *testing.T) {
+	server := NewManager(Options{Identity: newTestIdentity(t)})
+	client := NewManager(Options{Identity: newTestIdentity(t)})
+
+	addr, resultChan := acceptOnce(t, server)
+
+	// the client pinned another key for the server
+	_, _, err := client.dialTransport(addr, newTestIdentity(t).PublicKey())
+	require.True(t, errors.Is(err, ErrPublicKeyMismatch))
+
+	require.Error(t, (<-resultChan).err)
+}
+
+func TestTransportUnencrypted(t *testing.T) {
+	server := NewManager(Options{Identity: newTestIdentity(t)})
+	client := NewManager(Options{})
+
+	addr, resultChan := acceptOnce(t, server)
+
+	conn, publicKey, err := client.dialTransport(addr, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Nil(t, publicKey)
+
+	// the transport is detected by the first byte of the gossip protocol
+	_, err = conn.Write([]byte{1})
+	require.NoError(t, err)
+
+	result := <-resultChan
+	require.NoError(t, result.err)
+	defer result.conn.Close()
+	require.Nil(t, result.publicKey)
+
+	buf := make([]byte, 1)
+	_, err = result.conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, buf)
+
+	// a pinned key needs an identity
+	_, _, err = client.dialTransport(addr, newTestIdentity(t).PublicKey())
+	require.True(t, errors.Is(err, ErrEncryptionDisabled))
+}
+
+func TestVerifyTransport(t *testing.T) {
+	identity := newTestIdentity(t)
+	pinnedPublicKey := newTestIdentity(t).PublicKey()
+
+	m := NewManager(Options{Identity: identity})
+	m.PinPublicKey([]string{"10.0.0.1"}, 15600, pinnedPublicKey)
+
+	pinnedPeer := &peer.Peer{ID: peer.NewID("10.0.0.1", 15600)}
+	otherPeer := &peer.Peer{ID: peer.NewID("10.0.0.2", 15600)}
+
+	plain := &handshake.Handshake{}
+	encrypted := &handshake.Handshake{Extensions: handshake.ExtensionEncryptedTransport}
+
+	// a pinned peer has to be encrypted
+	require.True(t, errors.Is(m.verifyTransport(pinnedPeer, plain), ErrEncryptionRequired))
+
+	// the reported transport has to match the connection
+	require.True(t, errors.Is(m.verifyTransport(pinnedPeer, encrypted), ErrTransportMismatch))
+
+	pinnedPeer.PublicKey = identity.PublicKey()
+	require.True(t, errors.Is(m.verifyTransport(pinnedPeer, encrypted), ErrPublicKeyMismatch))
+
+	pinnedPeer.PublicKey = pinnedPublicKey
+	require.NoError(t, m.verifyTransport(pinnedPeer, encrypted))
+
+	// unpinned peers may connect unencrypted, unless encryption is required
+	require.NoError(t, m.verifyTransport(otherPeer, plain))
+
+	m.Opts.RequireEncryption = true
+	require.True(t, errors.Is(m.verifyTransport(otherPeer, plain), ErrEncryptionRequired))
+
+	otherPeer.PublicKey = identity.PublicKey()
+	require.NoError(t, m.verifyTransport(otherPeer, encrypted))
+}
+
+func TestPinnedPublicKeyConfig(t *testing.T) {
+	publicKeyHex := hex.EncodeToString(newTestIdentity(t).PublicKey())
+
+	// the pinned key is reported for peers which are not connected
+	m := NewManager(Options{Identity: newTestIdentity(t)}, &config.PeerConfig{ID: "127.0.0.1:15600", PublicKey: publicKeyHex})
+
+	infos := m.PeerInfos()
+	require.Len(t, infos, 1)
+	require.Equal(t, publicKeyHex, infos[0].PinnedPublicKey)
+	require.Empty(t, infos[0].PublicKey)
+
+	// pinned keys need an identity
+	m = NewManager(Options{})
+	require.True(t, errors.Is(m.Add("127.0.0.1:15600", false, "", publicKeyHex), ErrEncryptionDisabled))
+	require.Panics(t, func() {
+		NewManager(Options{}, &config.PeerConfig{ID: "127.0.0.1:15600", PublicKey: publicKeyHex})
+	})
+
+	// outbound connections are only encrypted with a pinned key, so it is needed if encryption is required
+	m = NewManager(Options{Identity: newTestIdentity(t), RequireEncryption: true})
+	require.True(t, errors.Is(m.Add("127.0.0.1:15600", false, "", ""), ErrEncryptionRequired))
+	require.Panics(t, func() {
+		NewManager(Options{Identity: newTestIdentity(t), RequireEncryption: true}, &config.PeerConfig{ID: "127.0.0.1:15600"})
+	})
+}
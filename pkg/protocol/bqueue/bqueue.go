@@ -15,6 +15,8 @@ type Broadcast struct {
 	TxData []byte
 	// The requested transaction hash.
 	RequestedTxHash hornet.Hash
+	// The address of the transaction, used to forward the transaction to the light peers watching it.
+	TxAddress hornet.Hash
 	// The IDs of the peers to exclude from broadcasting.
 	ExcludePeers map[string]struct{}
 }
@@ -53,18 +55,30 @@ func (bc *queue) Run(shutdownSignal <-chan struct{}) {
 			return
 		case b := <-bc.c:
 			bc.manager.ForAllConnected(func(p *peer.Peer) bool {
-				if _, excluded := b.ExcludePeers[p.ID]; excluded {
-					return true
-				}
-
-				// just send the transaction when the peer supports STING
-				if p.Protocol.Supports(sting.FeatureSet) {
+				if wantsBroadcast(p, b) {
 					helpers.SendTransaction(p, b.TxData)
-					return true
 				}
-
 				return true
 			})
 		}
 	}
 }
+
+// tells whether the given broadcast should be sent to the given peer.
+func wantsBroadcast(p *peer.Peer, b *Broadcast) bool {
+	if _, excluded := b.ExcludePeers[p.ID]; excluded {
+		return false
+	}
+
+	// just send the transaction when the peer supports STING
+	if !p.Protocol.Supports(sting.FeatureSet) {
+		return false
+	}
+
+	// light peers don't relay transactions, so they only get the transactions of their watched addresses
+	if p.IsLight() {
+		return p.WatchesAddress(b.TxAddress)
+	}
+
+	return true
+}
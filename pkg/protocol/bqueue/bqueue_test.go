@@ -0,0 +1,62 @@
+package bqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+func newTestPeer(id string, light bool) *peer.Peer {
+	return &peer.Peer{
+		ID:              id,
+		Protocol:        &protocol.Protocol{FeatureSet: sting.FeatureSet},
+		LatestHeartbeat: &sting.Heartbeat{Light: light},
+	}
+}
+
+func TestWantsBroadcastLightPeers(t *testing.T) {
+	watchedAddress := hornet.HashFromAddressTrytes(trinary.MustPad("WATCHED", 81))
+	otherAddress := hornet.HashFromAddressTrytes(trinary.MustPad("OTHER", 81))
+
+	fullPeer := newTestPeer("10.0.0.1:15600", false)
+	lightPeer := newTestPeer("10.0.0.2:15600", true)
+
+	// the light peer announces its watched addresses
+	msg, err := sting.NewWatchedAddressesMessage(hornet.Hashes{watchedAddress})
+	require.NoError(t, err)
+
+	addresses, err := sting.ParseWatchedAddresses(msg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	require.NoError(t, err)
+	lightPeer.SetWatchedAddresses(addresses)
+
+	watched := &Broadcast{TxAddress: watchedAddress}
+	other := &Broadcast{TxAddress: otherAddress}
+
+	// full peers get all transactions
+	require.True(t, wantsBroadcast(fullPeer, watched))
+	require.True(t, wantsBroadcast(fullPeer, other))
+
+	// light peers only get the transactions of their watched addresses
+	require.True(t, wantsBroadcast(lightPeer, watched))
+	require.False(t, wantsBroadcast(lightPeer, other))
+
+	// the peers which sent the transaction are excluded
+	excluded := &Broadcast{TxAddress: watchedAddress, ExcludePeers: map[string]struct{}{lightPeer.ID: {}}}
+	require.False(t, wantsBroadcast(lightPeer, excluded))
+	require.True(t, wantsBroadcast(fullPeer, excluded))
+
+	// invalid watched addresses messages are rejected
+	_, err = sting.ParseWatchedAddresses(make([]byte, sting.WatchedAddressBytesLength+1))
+	require.Error(t, err)
+
+	_, err = sting.NewWatchedAddressesMessage(make(hornet.Hashes, sting.MaxWatchedAddresses+1))
+	require.Error(t, err)
+}
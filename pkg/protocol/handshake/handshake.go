@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"io"
 	"time"
 
 	"github.com/willf/bitset"
@@ -31,6 +32,8 @@ var (
 	// - own used MWM (1 byte)
 	// - supported protocol versions. we need up to 32 bytes to represent 256 possible protocol
 	//   versions. only up to N bytes are used to communicate the highest supported version.
+	//   optional feature sets (e.g. transaction batches) are advertised with additional bits in the same bitset.
+	// - optional extension flags (1 byte). nodes without support for extensions ignore the trailing data.
 	HandshakeMessageDefinition = &message.Definition{
 		ID:             MessageTypeHandshake,
 		MaxBytesLength: 92,
@@ -45,6 +48,20 @@ const (
 	ByteEncodedCooAddressBytesLength = 49
 )
 
+// Extension is a flag in the extensions byte of the handshake message.
+type Extension byte
+
+const (
+	// ExtensionEncryptedTransport denotes that the sender uses an encrypted and authenticated transport for the connection.
+	ExtensionEncryptedTransport Extension = 1 << 0
+	// ExtensionMilestoneConeSync denotes that the sender supports the bulk milestone cone sync messages.
+	ExtensionMilestoneConeSync Extension = 1 << 1
+	// ExtensionHeartbeatFlags denotes that the sender supports the flags byte in heartbeat messages.
+	ExtensionHeartbeatFlags Extension = 1 << 3
+	// ExtensionWatchedAddresses denotes that the sender supports the watched addresses message of light nodes.
+	ExtensionWatchedAddresses Extension = 1 << 4
+)
+
 var (
 	ErrVersionNotSupported = errors.New("version not supported")
 )
@@ -56,6 +73,31 @@ type Handshake struct {
 	ByteEncodedCooAddress []byte
 	MWM                   byte
 	SupportedVersions     []byte
+	// the supported messages bitset of the peer, nil if it couldn't be parsed.
+	SupportedMessages *bitset.BitSet
+	Extensions        Extension
+}
+
+// HasExtension tells whether the given extension flag is set in the handshake.
+func (hs Handshake) HasExtension(extension Extension) bool {
+	return hs.Extensions&extension != 0
+}
+
+// SupportedOptionalFeatureSets returns the given optional feature sets which are also advertised
+// in the supported messages bitset of the handshake.
+func (hs Handshake) SupportedOptionalFeatureSets(ownOptionalFeatureSets byte) byte {
+	if hs.SupportedMessages == nil {
+		return 0
+	}
+
+	var featureSets byte
+	for i := uint(0); i < 8; i++ {
+		if hs.SupportedMessages.Test(i) {
+			featureSets |= 1 << i
+		}
+	}
+
+	return featureSets & ownOptionalFeatureSets
 }
 
 // SupportedVersion returns the highest supported protocol version.
@@ -86,7 +128,7 @@ func (hs Handshake) SupportedVersion(ownSupportedMessagesBitset *bitset.BitSet)
 }
 
 // NewHandshakeMessage creates a new handshake message.
-func NewHandshakeMessage(ownSupportedMessagesBitset *bitset.BitSet, ownSourcePort uint16, ownByteEncodedCooAddress []byte, ownUsedMWM byte) ([]byte, error) {
+func NewHandshakeMessage(ownSupportedMessagesBitset *bitset.BitSet, ownSourcePort uint16, ownByteEncodedCooAddress []byte, ownUsedMWM byte, extensions Extension) ([]byte, error) {
 
 	maxLength := HandshakeMessageDefinition.MaxBytesLength
 
@@ -95,7 +137,7 @@ func NewHandshakeMessage(ownSupportedMessagesBitset *bitset.BitSet, ownSourcePor
 		return nil, err
 	}
 
-	payloadLengthBytes := maxLength - (maxLength - 60) + uint16(len(supportedMessageTypes))
+	payloadLengthBytes := maxLength - (maxLength - 60) + uint16(len(supportedMessageTypes)) + 1
 	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+payloadLengthBytes))
 
 	if err := tlv.WriteHeader(buf, MessageTypeHandshake, payloadLengthBytes); err != nil {
@@ -122,6 +164,10 @@ func NewHandshakeMessage(ownSupportedMessagesBitset *bitset.BitSet, ownSourcePor
 		return nil, err
 	}
 
+	if err := binary.Write(buf, binary.BigEndian, extensions); err != nil {
+		return nil, err
+	}
+
 	return buf.Bytes(), nil
 }
 
@@ -155,6 +201,31 @@ func ParseHandshake(msg []byte) (*Handshake, error) {
 		return nil, err
 	}
 
-	hs := &Handshake{ServerSocketPort: serverSocketPort, SentTimestamp: sentTimestamp, ByteEncodedCooAddress: byteEncodedCooAddress, MWM: mwm, SupportedVersions: supportedVersions}
+	// the supported versions bitset starts with its length in bits, followed by the words of the bitset.
+	// the extensions follow the bitset.
+	var supportedMessages *bitset.BitSet
+	var extensions Extension
+	if len(supportedVersions) == 8 {
+		bitsetWordsLength := int64((binary.BigEndian.Uint64(supportedVersions) + 63) / 64 * 8)
+		if bitsetWordsLength <= int64(r.Len()) {
+			bitsetWords := make([]byte, bitsetWordsLength)
+			if _, err := io.ReadFull(r, bitsetWords); err != nil {
+				return nil, err
+			}
+
+			supportedMessages = &bitset.BitSet{}
+			if err := supportedMessages.UnmarshalBinary(append(supportedVersions, bitsetWords...)); err != nil {
+				supportedMessages = nil
+			}
+
+			if r.Len() > 0 {
+				if err := binary.Read(r, binary.BigEndian, &extensions); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	hs := &Handshake{ServerSocketPort: serverSocketPort, SentTimestamp: sentTimestamp, ByteEncodedCooAddress: byteEncodedCooAddress, MWM: mwm, SupportedVersions: supportedVersions, SupportedMessages: supportedMessages, Extensions: extensions}
 	return hs, nil
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/handshake"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
 )
 
@@ -17,12 +18,18 @@ func SendTransaction(p *peer.Peer, txData []byte) {
 }
 
 // SendHeartbeat sends a heartbeat message to the given peer.
-func SendHeartbeat(p *peer.Peer, solidMsIndex milestone.Index, pruningMsIndex milestone.Index, latestMsIndex milestone.Index, connectedNeighbors uint8, syncedNeighbors uint8) {
+// The flags are only sent if the peer supports the heartbeat flags extension.
+func SendHeartbeat(p *peer.Peer, solidMsIndex milestone.Index, pruningMsIndex milestone.Index, latestMsIndex milestone.Index, connectedNeighbors uint8, syncedNeighbors uint8, flags sting.HeartbeatFlag) {
 	if !p.Protocol.Supports(sting.FeatureSet) {
 		return
 	}
 
-	heartbeatData, _ := sting.NewHeartbeatMessage(solidMsIndex, pruningMsIndex, latestMsIndex, connectedNeighbors, syncedNeighbors)
+	var heartbeatData []byte
+	if p.Protocol.SupportsExtension(handshake.ExtensionHeartbeatFlags) {
+		heartbeatData, _ = sting.NewHeartbeatMessage(solidMsIndex, pruningMsIndex, latestMsIndex, connectedNeighbors, syncedNeighbors, flags)
+	} else {
+		heartbeatData, _ = sting.NewHeartbeatMessage(solidMsIndex, pruningMsIndex, latestMsIndex, connectedNeighbors, syncedNeighbors)
+	}
 	p.EnqueueForSending(heartbeatData)
 }
 
@@ -50,3 +57,29 @@ func SendMilestoneRequest(p *peer.Peer, index milestone.Index) {
 func SendLatestMilestoneRequest(p *peer.Peer) {
 	SendMilestoneRequest(p, sting.LatestMilestoneRequestIndex)
 }
+
+// SendMilestoneConeRequest sends a milestone cone request for the given range to the given peer.
+func SendMilestoneConeRequest(p *peer.Peer, startIndex milestone.Index, endIndex milestone.Index) {
+	if !p.Protocol.Supports(sting.FeatureSet) || !p.Protocol.SupportsExtension(handshake.ExtensionMilestoneConeSync) {
+		return
+	}
+
+	// only the requested cones are accepted from the peer
+	p.AddRequestedMilestoneCones(startIndex, endIndex)
+
+	milestoneConeRequestData, _ := sting.NewMilestoneConeRequestMessage(startIndex, endIndex)
+	p.EnqueueForSending(milestoneConeRequestData)
+}
+
+// SendWatchedAddresses sends the addresses whose transactions a light node wants to receive to the given peer.
+func SendWatchedAddresses(p *peer.Peer, addresses hornet.Hashes) {
+	if !p.Protocol.Supports(sting.FeatureSet) || !p.Protocol.SupportsExtension(handshake.ExtensionWatchedAddresses) {
+		return
+	}
+
+	watchedAddressesData, err := sting.NewWatchedAddressesMessage(addresses)
+	if err != nil {
+		return
+	}
+	p.EnqueueForSending(watchedAddressesData)
+}
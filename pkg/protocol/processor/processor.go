@@ -15,8 +15,10 @@ import (
 
 	"github.com/gohornet/hornet/pkg/compressed"
 	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/dag"
 	"github.com/gohornet/hornet/pkg/metrics"
 	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/peering"
 	"github.com/gohornet/hornet/pkg/peering/peer"
@@ -74,15 +76,21 @@ func New(requestQueue rqueue.Queue, peerManager *peering.Manager, opts *Options)
 
 	proc.wp = workerpool.New(func(task workerpool.Task) {
 		p := task.Param(0).(*peer.Peer)
-		data := task.Param(2).([]byte)
 
 		switch task.Param(1).(message.Type) {
 		case sting.MessageTypeTransaction:
-			proc.processTransaction(p, data)
+			proc.processTransaction(p, task.Param(2).([]byte))
+		case sting.MessageTypeTransactionBatch:
+			proc.processTransactionBatch(p, task.Param(2).([]byte))
 		case sting.MessageTypeTransactionRequest:
-			proc.processTransactionRequest(p, data)
+			proc.processTransactionRequest(p, task.Param(2).([]byte))
 		case sting.MessageTypeMilestoneRequest:
-			proc.processMilestoneRequest(p, data)
+			proc.processMilestoneRequest(p, task.Param(2).([]byte))
+		case sting.MessageTypeMilestoneConeRequest:
+			proc.processMilestoneConeRequest(p, task.Param(2).([]byte))
+		case sting.MessageTypeMilestoneCone:
+			// milestone cones are submitted once all of their chunks were received
+			proc.processMilestoneCone(p, task.Param(2).(*peer.MilestoneCone))
 		}
 
 		task.Return(nil)
@@ -121,6 +129,12 @@ type Processor struct {
 type Options struct {
 	ValidMWM          uint64
 	WorkUnitCacheOpts profile.CacheOpts
+	// UnrequestedTransactionFilter tells whether a transaction which wasn't requested should be processed.
+	// All transactions are processed if no filter is set.
+	UnrequestedTransactionFilter func(tx *hornet.Transaction) bool
+	// DisableRelaying disables the broadcast of received transactions.
+	// The transactions passed to CompressAndEmit are broadcasted nevertheless.
+	DisableRelaying bool
 }
 
 // Run runs the processor and blocks until the shutdown signal is triggered.
@@ -135,6 +149,40 @@ func (proc *Processor) Process(p *peer.Peer, msgType message.Type, data []byte)
 	proc.wp.Submit(p, msgType, data)
 }
 
+// ProcessMilestoneConeChunk collects the given milestone cone message and submits the milestone cone
+// to the processor once all of its chunks were received. The messages of a peer have to be passed in the
+// order they were received, so this function must be called from the peer's receive handler.
+func (proc *Processor) ProcessMilestoneConeChunk(p *peer.Peer, data []byte) {
+	chunk, err := sting.ParseMilestoneConeChunk(data)
+	if err != nil {
+		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Score.InvalidTransaction()
+
+		// drop the connection to the peer
+		proc.pm.Penalize(p)
+		return
+	}
+
+	cone, err := p.AddMilestoneConeChunk(chunk)
+	switch {
+	case errors.Is(err, peer.ErrMilestoneConeNotRequested):
+		// the cone may have been delivered after the request timed out
+		return
+	case err != nil:
+		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Score.InvalidTransaction()
+
+		// drop the connection to the peer
+		proc.pm.Penalize(p)
+		return
+	case cone == nil:
+		// wait for the remaining chunks
+		return
+	}
+
+	proc.wp.Submit(p, sting.MessageTypeMilestoneCone, cone)
+}
+
 // ValidateTransactionTrytesAndEmit validates the given transaction trytes which were not received via gossip but
 // through some other mechanism. This function does not run within the Processor's worker pool.
 // Emits a TransactionProcessed and BroadcastTransaction event if the transaction was processed.
@@ -196,6 +244,7 @@ func (proc *Processor) CompressAndEmit(tx *transaction.Transaction, txTrits trin
 	proc.Events.BroadcastTransaction.Trigger(&bqueue.Broadcast{
 		TxData:          txBytesTruncated,
 		RequestedTxHash: hornetTx.GetTxHash(),
+		TxAddress:       hornetTx.GetAddress(),
 	})
 	return nil
 }
@@ -239,12 +288,195 @@ func (proc *Processor) processMilestoneRequest(p *peer.Peer, data []byte) {
 	cachedTxs := cachedReqMs.GetBundle().GetTransactions() // txs +1
 	for _, cachedTxToSend := range cachedTxs {
 		transactionMsg, _ := sting.NewTransactionMessage(cachedTxToSend.GetTransaction().RawBytes)
-		p.EnqueueForSending(transactionMsg)
+		p.EnqueueForSending(transactionMsg, peer.SendPriorityMilestone)
 	}
 	cachedTxs.Release(true)   // txs -1
 	cachedReqMs.Release(true) // bundle -1
 }
 
+// processes the given milestone cone request by parsing it and then replying to the peer
+// with the transactions confirmed by the requested milestones.
+func (proc *Processor) processMilestoneConeRequest(p *peer.Peer, data []byte) {
+	startIndex, endIndex, err := sting.ExtractRequestedMilestoneConeRange(data)
+	if err != nil {
+		metrics.SharedServerMetrics.InvalidRequests.Inc()
+
+		// drop the connection to the peer
+		proc.pm.Remove(p.ID)
+		return
+	}
+
+	// limit the amount of work a single request can cause
+	if endIndex-startIndex >= sting.MaxMilestoneConeRequestRange {
+		endIndex = startIndex + sting.MaxMilestoneConeRequestRange - 1
+	}
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return
+	}
+
+	// we can only reply with cones which are solid and not pruned yet
+	if startIndex <= snapshotInfo.PruningIndex {
+		startIndex = snapshotInfo.PruningIndex + 1
+	}
+	if solidMilestoneIndex := tangle.GetSolidMilestoneIndex(); endIndex > solidMilestoneIndex {
+		endIndex = solidMilestoneIndex
+	}
+
+	for msIndex := startIndex; msIndex <= endIndex; msIndex++ {
+		// every cone causes a traversal, so the peer gets only a limited amount of cones per second.
+		// the remaining cones are requested transaction by transaction.
+		if !p.MilestoneConeLimiter.Allow(1) {
+			return
+		}
+
+		txsData, err := milestoneConeTransactions(msIndex)
+		if err != nil || len(txsData) == 0 {
+			// can't reply if we don't have the full cone of the milestone
+			continue
+		}
+
+		coneMsgs, err := sting.NewMilestoneConeMessages(msIndex, txsData)
+		if err != nil {
+			continue
+		}
+
+		for _, coneMsg := range coneMsgs {
+			p.EnqueueForSending(coneMsg)
+		}
+	}
+}
+
+// collects the transactions which were confirmed by the given milestone.
+// the traversal is aborted with ErrMilestoneConeTooBig if the cone exceeds sting.MaxMilestoneConeTransactions.
+func milestoneConeTransactions(msIndex milestone.Index) ([][]byte, error) {
+	cachedMs := tangle.GetCachedMilestoneOrNil(msIndex) // milestone +1
+	if cachedMs == nil {
+		return nil, nil
+	}
+	defer cachedMs.Release(true) // milestone -1
+
+	var txsData [][]byte
+	err := dag.TraverseApprovees(cachedMs.GetMilestone().Hash,
+		// traversal stops if no more transactions pass the given condition
+		// Caution: condition func is not in DFS order
+		func(cachedTxMeta *tangle.CachedMetadata) (bool, error) { // meta +1
+			defer cachedTxMeta.Release(true) // meta -1
+			confirmed, at := cachedTxMeta.GetMetadata().GetConfirmed()
+			return confirmed && at == msIndex, nil
+		},
+		// consumer
+		func(cachedTxMeta *tangle.CachedMetadata) error { // meta +1
+			defer cachedTxMeta.Release(true) // meta -1
+
+			if len(txsData) >= sting.MaxMilestoneConeTransactions {
+				return sting.ErrMilestoneConeTooBig
+			}
+
+			cachedTx := tangle.GetCachedTransactionOrNil(cachedTxMeta.GetMetadata().GetTxHash()) // tx +1
+			if cachedTx == nil {
+				return tangle.ErrTransactionNotFound
+			}
+			defer cachedTx.Release(true) // tx -1
+
+			txsData = append(txsData, cachedTx.GetTransaction().RawBytes)
+			return nil
+		},
+		// called on missing approvees
+		// return error on missing approvees
+		nil,
+		// called on solid entry points
+		// Ignore solid entry points (snapshot milestone included)
+		nil,
+		false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return txsData, nil
+}
+
+// processes the given milestone cone by verifying that it was confirmed by the requested milestone
+// and then processing all contained transactions.
+func (proc *Processor) processMilestoneCone(p *peer.Peer, cone *peer.MilestoneCone) {
+	cachedMs := tangle.GetMilestoneOrNil(cone.MilestoneIndex) // bundle +1
+	if cachedMs == nil {
+		// the cone can't be verified without the milestone, its transactions are requested one by one instead
+		return
+	}
+	msTailTxHash := cachedMs.GetBundle().GetTailHash()
+	cachedMs.Release(true) // bundle -1
+
+	txs := make(map[string]*transaction.Transaction, len(cone.Transactions))
+	for _, txData := range cone.Transactions {
+		tx, err := compressed.TransactionFromCompressedBytes(txData)
+		if err != nil {
+			metrics.SharedServerMetrics.InvalidTransactions.Inc()
+			p.Score.InvalidTransaction()
+
+			// drop the connection to the peer
+			proc.pm.Penalize(p)
+			return
+		}
+		txHash := string(hornet.HashFromHashTrytes(tx.Hash))
+		txs[txHash] = tx
+	}
+
+	// every transaction of the cone has to be approved by the milestone either directly or through
+	// other transactions of the cone, otherwise the peer sent transactions which don't belong to it.
+	approved := make(map[string]struct{}, len(txs))
+	stack := []string{string(msTailTxHash)}
+	for len(stack) > 0 {
+		txHash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		tx, exists := txs[txHash]
+		if !exists {
+			continue
+		}
+		if _, visited := approved[txHash]; visited {
+			continue
+		}
+		approved[txHash] = struct{}{}
+
+		stack = append(stack, string(hornet.HashFromHashTrytes(tx.TrunkTransaction)), string(hornet.HashFromHashTrytes(tx.BranchTransaction)))
+	}
+
+	if len(approved) != len(txs) {
+		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Score.InvalidTransaction()
+
+		// drop the connection to the peer
+		proc.pm.Penalize(p)
+		return
+	}
+
+	for _, txData := range cone.Transactions {
+		proc.processTransaction(p, txData)
+	}
+}
+
+// processes the given transaction batch message by decompressing it and then processing all contained transactions.
+func (proc *Processor) processTransactionBatch(p *peer.Peer, data []byte) {
+	txsData, err := sting.ExtractTransactionBatch(data)
+	if err != nil {
+		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Score.InvalidTransaction()
+
+		// drop the connection to the peer
+		proc.pm.Penalize(p)
+		return
+	}
+
+	p.Metrics.ReceivedTransactions.Add(uint32(len(txsData)))
+	metrics.SharedServerMetrics.Transactions.Add(uint32(len(txsData)))
+
+	for _, txData := range txsData {
+		proc.processTransaction(p, txData)
+	}
+}
+
 // processes the given transaction request by parsing it and then replying to the peer with it.
 func (proc *Processor) processTransactionRequest(p *peer.Peer, data []byte) {
 	if len(data) != 49 {
@@ -259,7 +491,7 @@ func (proc *Processor) processTransactionRequest(p *peer.Peer, data []byte) {
 	defer cachedTx.Release()
 
 	transactionMsg, _ := sting.NewTransactionMessage(cachedTx.GetTransaction().RawBytes)
-	p.EnqueueForSending(transactionMsg)
+	p.EnqueueForSending(transactionMsg, peer.SendPriorityResponse)
 }
 
 // gets or creates a new WorkUnit for the given transaction and then processes the WorkUnit.
@@ -286,16 +518,17 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 		wu.processingLock.Unlock()
 
 		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Score.InvalidTransaction()
 
 		// drop the connection to the peer
-		proc.pm.Remove(p.ID)
+		proc.pm.Penalize(p)
 
 		return
 	case wu.Is(Hashed):
 		wu.processingLock.Unlock()
 
 		// emit an event to say that a transaction was fully processed
-		if request := proc.requestQueue.Received(wu.tx.GetTxHash()); request != nil {
+		if request := proc.requestQueue.ReceivedFrom(wu.tx.GetTxHash(), p.ID); request != nil {
 			proc.Events.TransactionProcessed.Trigger(wu.tx, request, p)
 			wu.wasStale = false
 			return
@@ -331,7 +564,7 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 	hornetTx := hornet.NewTransactionFromTx(tx, wu.receivedTxBytes)
 
 	// mark the transaction as received
-	request := proc.requestQueue.Received(hornetTx.GetTxHash())
+	request := proc.requestQueue.ReceivedFrom(hornetTx.GetTxHash(), p.ID)
 
 	// validate minimum weight magnitude requirement
 	if request == nil && !transaction.HasValidNonce(tx, proc.opts.ValidMWM) {
@@ -363,6 +596,10 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 		return
 	}
 
+	if request == nil && proc.opts.UnrequestedTransactionFilter != nil && !proc.opts.UnrequestedTransactionFilter(hornetTx) {
+		return
+	}
+
 	// check the existence of the transaction before broadcasting it
 	containsTx := tangle.ContainsTransaction(hornetTx.GetTxHash())
 
@@ -373,7 +610,7 @@ func (proc *Processor) processWorkUnit(wu *WorkUnit, p *peer.Peer) {
 
 	// broadcast the transaction if it wasn't requested and the timestamp is
 	// within what we consider a sensible delta from now
-	if request == nil && broadcast && !containsTx {
+	if request == nil && broadcast && !containsTx && !proc.opts.DisableRelaying {
 		proc.Events.BroadcastTransaction.Trigger(wu.broadcast())
 	}
 }
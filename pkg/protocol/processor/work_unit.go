@@ -106,15 +106,16 @@ func (wu *WorkUnit) addReceivedFrom(p *peer.Peer, requestedTxHash hornet.Hash) {
 
 // punishes, respectively increases the invalid transaction metric of all peers
 // which sent the given underlying transaction of this WorkUnit.
-// it also closes the connection to these peers.
+// it also closes the connection to these peers and blacklists them temporarily.
 func (wu *WorkUnit) punish() {
 	wu.receivedFromLock.Lock()
 	defer wu.receivedFromLock.Unlock()
 	for _, p := range wu.receivedFrom {
 		metrics.SharedServerMetrics.InvalidTransactions.Inc()
+		p.Score.InvalidTransaction()
 
 		// drop the connection to the peer
-		peering.Manager().Remove(p.ID)
+		peering.Manager().Penalize(p)
 	}
 }
 
@@ -126,11 +127,16 @@ func (wu *WorkUnit) stale() {
 	for _, p := range wu.receivedFrom {
 		metrics.SharedServerMetrics.StaleTransactions.Inc()
 		p.Metrics.StaleTransactions.Inc()
+		p.Score.StaleTransaction()
 	}
 }
 
 // builds a Broadcast where all peers which are associated with this WorkUnit are excluded from.
 func (wu *WorkUnit) broadcast() *bqueue.Broadcast {
+	wu.dataLock.RLock()
+	txAddress := wu.tx.GetAddress()
+	wu.dataLock.RUnlock()
+
 	wu.receivedFromLock.Lock()
 	defer wu.receivedFromLock.Unlock()
 	exclude := map[string]struct{}{}
@@ -140,6 +146,7 @@ func (wu *WorkUnit) broadcast() *bqueue.Broadcast {
 	return &bqueue.Broadcast{
 		TxData:          wu.receivedTxBytes,
 		RequestedTxHash: wu.receivedTxHash,
+		TxAddress:       txAddress,
 		ExcludePeers:    exclude,
 	}
 }
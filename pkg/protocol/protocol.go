@@ -35,6 +35,13 @@ var (
 
 	// supported protocol messages/feature sets
 	SupportedFeatureSets = bitset.From([]uint64{sting.FeatureSet})
+
+	// supported optional feature sets which are advertised in the supported messages bitset of the handshake.
+	// they are not protocol versions, so they are not used to negotiate the protocol version.
+	SupportedOptionalFeatureSets byte = sting.TransactionBatchFeatureSet
+
+	// supported optional protocol extensions which are negotiated in the handshake
+	SupportedExtensions = handshake.ExtensionMilestoneConeSync | handshake.ExtensionHeartbeatFlags | handshake.ExtensionWatchedAddresses
 )
 
 var (
@@ -80,6 +87,12 @@ type Protocol struct {
 	// The protocol features this instance supports.
 	// This variable is only usable after protocol handshake.
 	FeatureSet byte
+	// Whether the underlying connection is encrypted and authenticated.
+	// It is announced to the peer in the handshake.
+	Encrypted bool
+	// The optional protocol extensions supported by both sides.
+	// This variable is only usable after protocol handshake.
+	Extensions handshake.Extension
 	// Holds events for sent and received messages, handshake completion and generic errors.
 	Events Events
 	// the underlying connection
@@ -136,20 +149,44 @@ func (p *Protocol) Supports(featureSet byte) bool {
 	return p.FeatureSet&featureSet > 0
 }
 
+// SupportsExtension tells whether the protocol supports the given extension.
+func (p *Protocol) SupportsExtension(extension handshake.Extension) bool {
+	return p.Extensions&extension != 0
+}
+
 // SupportedFeatureSets returns a slice of named supported feature sets.
 func (p *Protocol) SupportedFeatureSets() []string {
 	var features []string
 	if p.Supports(sting.FeatureSet) {
 		features = append(features, sting.FeatureSetName)
 	}
+	if p.SupportsExtension(handshake.ExtensionMilestoneConeSync) {
+		features = append(features, sting.MilestoneConeSyncName)
+	}
+	if p.Supports(sting.TransactionBatchFeatureSet) {
+		features = append(features, sting.TransactionBatchName)
+	}
+	if p.SupportsExtension(handshake.ExtensionHeartbeatFlags) {
+		features = append(features, sting.HeartbeatFlagsName)
+	}
+	if p.SupportsExtension(handshake.ExtensionWatchedAddresses) {
+		features = append(features, sting.WatchedAddressesName)
+	}
 	return features
 }
 
 // Start kicks off the protocol by sending a handshake message and starting to read from
 // the connection.
 func (p *Protocol) Start() {
+	extensions := SupportedExtensions
+	if p.Encrypted {
+		extensions |= handshake.ExtensionEncryptedTransport
+	}
+
+	supportedMessages := SupportedFeatureSets.Union(bitset.From([]uint64{uint64(SupportedOptionalFeatureSets)}))
+
 	// kick off protocol by sending a handshake message
-	handshakeMsg, err := handshake.NewHandshakeMessage(SupportedFeatureSets, ownSrvSocketPort, ownByteEncodedCooAddress, byte(ownMWM))
+	handshakeMsg, err := handshake.NewHandshakeMessage(supportedMessages, ownSrvSocketPort, ownByteEncodedCooAddress, byte(ownMWM), extensions)
 	if err != nil {
 		fmt.Println("creating handshake message error: ", err)
 		_ = p.conn.Close()
@@ -8,8 +8,10 @@ import (
 	"github.com/gohornet/hornet/pkg/protocol"
 	"github.com/gohornet/hornet/pkg/protocol/handshake"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
 	"github.com/iotaledger/hive.go/events"
 	"github.com/stretchr/testify/assert"
+	"github.com/willf/bitset"
 )
 
 type fakeconn struct {
@@ -79,7 +81,7 @@ func TestProtocol_Receive(t *testing.T) {
 		handshakeMessageReceived = true
 	}))
 
-	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14)
+	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14, 0)
 	assert.NoError(t, err)
 
 	wg := consume(t, p, conn, len(handshakeMsg))
@@ -100,7 +102,7 @@ func TestProtocol_Send(t *testing.T) {
 		handshakeMessageSent = true
 	}))
 
-	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14)
+	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14, 0)
 	assert.NoError(t, err)
 
 	wg := consume(t, p, conn, len(handshakeMsg))
@@ -116,3 +118,155 @@ func TestProtocol_Supports(t *testing.T) {
 	assert.True(t, p.Supports(sting.FeatureSet))
 	assert.False(t, p.Supports(243))
 }
+
+func TestHandshake_Extensions(t *testing.T) {
+	handshakeMsg, err := handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14, handshake.ExtensionEncryptedTransport)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(handshakeMsg)-int(tlv.HeaderMessageDefinition.MaxBytesLength), int(handshake.HandshakeMessageDefinition.MaxBytesLength))
+
+	hs, err := handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(100), hs.ServerSocketPort)
+	assert.Equal(t, byte(14), hs.MWM)
+	assert.True(t, hs.HasExtension(handshake.ExtensionEncryptedTransport))
+
+	handshakeMsg, err = handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14, 0)
+	assert.NoError(t, err)
+
+	hs, err = handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.False(t, hs.HasExtension(handshake.ExtensionEncryptedTransport))
+
+	// handshakes of nodes without support for extensions
+	hs, err = handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength : len(handshakeMsg)-1])
+	assert.NoError(t, err)
+	assert.False(t, hs.HasExtension(handshake.ExtensionEncryptedTransport))
+}
+
+func TestHandshake_OptionalFeatureSets(t *testing.T) {
+	supportedMessages := protocol.SupportedFeatureSets.Union(bitset.From([]uint64{sting.TransactionBatchFeatureSet}))
+	handshakeMsg, err := handshake.NewHandshakeMessage(supportedMessages, 100, make([]byte, 49), 14, 0)
+	assert.NoError(t, err)
+
+	hs, err := handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, byte(sting.TransactionBatchFeatureSet), hs.SupportedOptionalFeatureSets(sting.TransactionBatchFeatureSet))
+	assert.Equal(t, byte(0), hs.SupportedOptionalFeatureSets(0))
+
+	// the optional feature sets don't change the negotiated protocol version
+	version, err := hs.SupportedVersion(protocol.SupportedFeatureSets)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.FeatureSet, version)
+
+	// handshakes of nodes without support for optional feature sets
+	handshakeMsg, err = handshake.NewHandshakeMessage(protocol.SupportedFeatureSets, 100, make([]byte, 49), 14, 0)
+	assert.NoError(t, err)
+
+	hs, err = handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0), hs.SupportedOptionalFeatureSets(sting.TransactionBatchFeatureSet))
+
+	hs, err = handshake.ParseHandshake(handshakeMsg[tlv.HeaderMessageDefinition.MaxBytesLength : len(handshakeMsg)-1])
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0), hs.SupportedOptionalFeatureSets(sting.TransactionBatchFeatureSet))
+}
+
+func TestMilestoneConeMessages(t *testing.T) {
+	requestMsg, err := sting.NewMilestoneConeRequestMessage(10, 20)
+	assert.NoError(t, err)
+
+	startIndex, endIndex, err := sting.ExtractRequestedMilestoneConeRange(requestMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, startIndex)
+	assert.EqualValues(t, 20, endIndex)
+
+	// enough transactions to exceed the maximum message size
+	var txsData [][]byte
+	for i := 0; i < 100; i++ {
+		txData := make([]byte, 1000)
+		txData[0] = byte(i)
+		txsData = append(txsData, txData)
+	}
+
+	coneMsgs, err := sting.NewMilestoneConeMessages(15, txsData)
+	assert.NoError(t, err)
+	assert.Len(t, coneMsgs, 2)
+
+	var received [][]byte
+	for i, coneMsg := range coneMsgs {
+		header, err := tlv.ParseHeader(coneMsg[:tlv.HeaderMessageDefinition.MaxBytesLength])
+		assert.NoError(t, err)
+		assert.Equal(t, len(coneMsg)-int(tlv.HeaderMessageDefinition.MaxBytesLength), int(header.MessageBytesLength))
+
+		chunk, err := sting.ParseMilestoneConeChunk(coneMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+		assert.NoError(t, err)
+		assert.EqualValues(t, 15, chunk.MilestoneIndex)
+		assert.Equal(t, i == len(coneMsgs)-1, chunk.Last)
+		received = append(received, chunk.Transactions...)
+	}
+	assert.Equal(t, txsData, received)
+
+	_, err = sting.ParseMilestoneConeChunk([]byte{0, 0, 0, 15, 1, 0, 10, 1})
+	assert.Error(t, err)
+}
+
+func TestTransactionBatchMessage(t *testing.T) {
+	var txsData [][]byte
+	for i := 0; i < 20; i++ {
+		txData := make([]byte, 500+i)
+		txData[0] = byte(i)
+		txsData = append(txsData, txData)
+	}
+
+	batchMsg, err := sting.NewTransactionBatchMessage(txsData)
+	assert.NoError(t, err)
+
+	header, err := tlv.ParseHeader(batchMsg[:tlv.HeaderMessageDefinition.MaxBytesLength])
+	assert.NoError(t, err)
+	assert.Equal(t, sting.MessageTypeTransactionBatch, header.Definition.ID)
+	assert.Equal(t, len(batchMsg)-int(tlv.HeaderMessageDefinition.MaxBytesLength), int(header.MessageBytesLength))
+
+	// the batch is compressed
+	assert.Less(t, len(batchMsg), 500*20)
+
+	received, err := sting.ExtractTransactionBatch(batchMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.Equal(t, txsData, received)
+
+	// too many bytes for a single batch
+	_, err = sting.NewTransactionBatchMessage(append(txsData, make([]byte, sting.MaxTransactionBatchBytesLength)))
+	assert.Equal(t, sting.ErrTransactionBatchTooBig, err)
+
+	_, err = sting.ExtractTransactionBatch([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestHeartbeatMessage(t *testing.T) {
+	heartbeatMsg, err := sting.NewHeartbeatMessage(10, 5, 12, 8, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, sting.HeartbeatMinBytesLength, len(heartbeatMsg)-int(tlv.HeaderMessageDefinition.MaxBytesLength))
+
+	heartbeat, err := sting.ParseHeartbeat(heartbeatMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, heartbeat.SolidMilestoneIndex)
+	assert.EqualValues(t, 5, heartbeat.PrunedMilestoneIndex)
+	assert.EqualValues(t, 12, heartbeat.LatestMilestoneIndex)
+	assert.Equal(t, 8, heartbeat.ConnectedNeighbors)
+	assert.Equal(t, 6, heartbeat.SyncedNeighbors)
+	assert.False(t, heartbeat.Light)
+
+	heartbeatMsg, err = sting.NewHeartbeatMessage(10, 5, 12, 8, 6, sting.HeartbeatFlagLight)
+	assert.NoError(t, err)
+
+	header, err := tlv.ParseHeader(heartbeatMsg[:tlv.HeaderMessageDefinition.MaxBytesLength])
+	assert.NoError(t, err)
+	assert.Equal(t, len(heartbeatMsg)-int(tlv.HeaderMessageDefinition.MaxBytesLength), int(header.MessageBytesLength))
+
+	heartbeat, err = sting.ParseHeartbeat(heartbeatMsg[tlv.HeaderMessageDefinition.MaxBytesLength:])
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, heartbeat.SolidMilestoneIndex)
+	assert.True(t, heartbeat.Light)
+
+	_, err = sting.ParseHeartbeat([]byte{1, 2, 3})
+	assert.Equal(t, sting.ErrInvalidSourceLength, err)
+}
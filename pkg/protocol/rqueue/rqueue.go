@@ -29,6 +29,19 @@ type Queue interface {
 	// It is added to the processing set.
 	// Returns the origin request which was pending or nil if the hash was not requested.
 	Received(hash hornet.Hash) *Request
+	// ReceivedFrom works like Received but also updates the statistics of the peer with the given ID,
+	// if the request was sent to that peer.
+	ReceivedFrom(hash hornet.Hash, peerID string) *Request
+	// Requested marks the given pending request as sent to the peer with the given ID.
+	Requested(r *Request, peerID string)
+	// BestPeer returns the ID of the peer out of the given candidates to which the given request should be sent.
+	// Returns an empty string if all candidates reached the given in-flight limit (0 = unlimited).
+	BestPeer(r *Request, candidates []string, maxInFlight int) string
+	// PeerStats returns a snapshot of the request statistics of the peer with the given ID.
+	PeerStats(peerID string) PeerStats
+	// RemovePeer removes the request statistics of the peer with the given ID.
+	// Pending requests which were sent to the peer are no longer awaited from it.
+	RemovePeer(peerID string)
 	// Processed marks a request as fulfilled and thereby removes it from the processing set.
 	// Returns the origin request which was pending or nil if the hash was not requested.
 	Processed(hash hornet.Hash) *Request
@@ -55,6 +68,13 @@ type FilterFunc func(r *Request) bool
 
 const DefaultLatencyResolution = 100
 
+const (
+	// the weight of a new sample in the moving average of the latency of a peer.
+	peerLatencySampleWeight = 0.1
+	// the time after which a request which was sent to a peer is considered unanswered.
+	unansweredRequestThreshold = time.Second
+)
+
 // New creates a new Queue where request are prioritized over their milestone index (lower = higher priority).
 func New(latencyResolution ...int32) Queue {
 	q := &priorityqueue{
@@ -62,6 +82,7 @@ func New(latencyResolution ...int32) Queue {
 		queued:     make(map[string]*Request),
 		pending:    make(map[string]*Request),
 		processing: make(map[string]*Request),
+		peerStats:  make(map[string]*PeerStats),
 	}
 	if len(latencyResolution) == 0 {
 		q.latencyResolution = DefaultLatencyResolution
@@ -84,6 +105,29 @@ type Request struct {
 	// the time at which this request was first enqueued.
 	// do not modify this time
 	EnqueueTime time.Time
+	// the time at which this request was sent to a peer the last time.
+	RequestTime time.Time
+	// the IDs of the peers to which this request was sent.
+	RequestedFrom []string
+	// the ID of the peer from which an answer to this request is awaited.
+	inFlightPeerID string
+}
+
+// PeerStats holds the request statistics of a peer.
+type PeerStats struct {
+	// The moving average of the time it took the peer to answer requests.
+	Latency time.Duration
+	// The number of requests the peer answered.
+	Answered uint64
+	// The number of requests the peer didn't answer in time.
+	Unanswered uint64
+	// The number of requests sent to the peer which are not answered yet.
+	InFlight int
+}
+
+// SuccessRate returns the estimated probability that the peer answers a request.
+func (s PeerStats) SuccessRate() float64 {
+	return float64(s.Answered+1) / float64(s.Answered+s.Unanswered+2)
 }
 
 // implements a priority queue where requests with the lowest milestone index are popped first.
@@ -100,6 +144,7 @@ type priorityqueue struct {
 	latencySum        int64
 	latencyEntries    int64
 	filter            FilterFunc
+	peerStats         map[string]*PeerStats
 	sync.RWMutex
 }
 
@@ -155,10 +200,25 @@ func (pq *priorityqueue) IsProcessing(hash hornet.Hash) bool {
 }
 
 func (pq *priorityqueue) Received(hash hornet.Hash) *Request {
+	return pq.ReceivedFrom(hash, "")
+}
+
+func (pq *priorityqueue) ReceivedFrom(hash hornet.Hash, peerID string) *Request {
 	pq.Lock()
 	defer pq.Unlock()
 
 	if req, wasPending := pq.pending[string(hash)]; wasPending {
+		requestedPeerID := req.inFlightPeerID
+		if stats := pq.settle(req); stats != nil && requestedPeerID == peerID {
+			latency := time.Since(req.RequestTime)
+			if stats.Answered == 0 {
+				stats.Latency = latency
+			} else {
+				stats.Latency = time.Duration((1-peerLatencySampleWeight)*float64(stats.Latency) + peerLatencySampleWeight*float64(latency))
+			}
+			stats.Answered++
+		}
+
 		pq.latencySum += time.Since(req.EnqueueTime).Milliseconds()
 		pq.latencyEntries++
 		if pq.latencyEntries == pq.latencyResolution {
@@ -202,10 +262,17 @@ func (pq *priorityqueue) EnqueuePending(discardOlderThan time.Duration) int {
 	s := time.Now()
 	for k, v := range pq.pending {
 		if pq.filter != nil && !pq.filter(v) {
+			pq.settle(v)
 			delete(pq.pending, k)
 			enqueued--
 			continue
 		}
+		// the request wasn't answered in time
+		if v.inFlightPeerID != "" && s.Sub(v.RequestTime) >= unansweredRequestThreshold {
+			if stats := pq.settle(v); stats != nil {
+				stats.Unanswered++
+			}
+		}
 		if discardOlderThan == 0 || v.PreventDiscard || s.Sub(v.EnqueueTime) < discardOlderThan {
 			// no need to examine the queued set
 			// as addition and removal are synced over Push and Pops
@@ -213,6 +280,7 @@ func (pq *priorityqueue) EnqueuePending(discardOlderThan time.Duration) int {
 			continue
 		}
 		// discard request from the queue
+		pq.settle(v)
 		delete(pq.pending, k)
 		enqueued--
 	}
@@ -239,6 +307,133 @@ func (pq *priorityqueue) AvgLatency() int64 {
 	return pq.avgLatency.Load()
 }
 
+func (pq *priorityqueue) Requested(r *Request, peerID string) {
+	pq.Lock()
+	defer pq.Unlock()
+
+	// the request was answered in the meantime
+	if _, pending := pq.pending[string(r.Hash)]; !pending {
+		return
+	}
+
+	// only the latest request is awaited
+	pq.settle(r)
+
+	stats, exists := pq.peerStats[peerID]
+	if !exists {
+		stats = &PeerStats{}
+		pq.peerStats[peerID] = stats
+	}
+	stats.InFlight++
+
+	r.RequestTime = time.Now()
+	r.RequestedFrom = append(r.RequestedFrom, peerID)
+	r.inFlightPeerID = peerID
+}
+
+// BestPeer prefers the candidates to which the request wasn't sent yet, so that retries go to alternative peers.
+// Out of these, the peer with the lowest expected latency (average latency divided by the success rate) is chosen.
+// Candidates without answered requests are assumed to be as fast as the average of the other candidates.
+// On equal expected latency the order of the candidates is kept.
+func (pq *priorityqueue) BestPeer(r *Request, candidates []string, maxInFlight int) string {
+	pq.RLock()
+	defer pq.RUnlock()
+
+	var latencySum time.Duration
+	var latencyCount int
+	for _, peerID := range candidates {
+		if stats, exists := pq.peerStats[peerID]; exists && stats.Answered > 0 {
+			latencySum += stats.Latency
+			latencyCount++
+		}
+	}
+
+	var defaultLatency time.Duration
+	if latencyCount > 0 {
+		defaultLatency = latencySum / time.Duration(latencyCount)
+	}
+
+	requestedFrom := make(map[string]struct{}, len(r.RequestedFrom))
+	for _, peerID := range r.RequestedFrom {
+		requestedFrom[peerID] = struct{}{}
+	}
+
+	bestPeerID := ""
+	bestAlreadyRequested := true
+	bestCost := 0.0
+	for _, peerID := range candidates {
+		stats := PeerStats{Latency: defaultLatency}
+		if s, exists := pq.peerStats[peerID]; exists {
+			stats = *s
+			if stats.Answered == 0 {
+				stats.Latency = defaultLatency
+			}
+		}
+
+		if maxInFlight > 0 && stats.InFlight >= maxInFlight {
+			continue
+		}
+
+		_, alreadyRequested := requestedFrom[peerID]
+		cost := float64(stats.Latency) / stats.SuccessRate()
+
+		switch {
+		case bestPeerID == "":
+		case bestAlreadyRequested && !alreadyRequested:
+		case bestAlreadyRequested == alreadyRequested && cost < bestCost:
+		default:
+			continue
+		}
+
+		bestPeerID = peerID
+		bestAlreadyRequested = alreadyRequested
+		bestCost = cost
+	}
+
+	return bestPeerID
+}
+
+func (pq *priorityqueue) PeerStats(peerID string) PeerStats {
+	pq.RLock()
+	defer pq.RUnlock()
+
+	if stats, exists := pq.peerStats[peerID]; exists {
+		return *stats
+	}
+	return PeerStats{}
+}
+
+func (pq *priorityqueue) RemovePeer(peerID string) {
+	pq.Lock()
+	defer pq.Unlock()
+
+	for _, r := range pq.pending {
+		if r.inFlightPeerID == peerID {
+			r.inFlightPeerID = ""
+		}
+	}
+	delete(pq.peerStats, peerID)
+}
+
+// removes the in-flight mark of the given request from the statistics of the peer it was sent to.
+// returns the statistics of that peer or nil if the request wasn't sent to a peer.
+func (pq *priorityqueue) settle(r *Request) *PeerStats {
+	if r.inFlightPeerID == "" {
+		return nil
+	}
+
+	stats, exists := pq.peerStats[r.inFlightPeerID]
+	r.inFlightPeerID = ""
+	if !exists {
+		return nil
+	}
+
+	if stats.InFlight > 0 {
+		stats.InFlight--
+	}
+	return stats
+}
+
 func (pq *priorityqueue) Requests() (queued []*Request, pending []*Request, processing []*Request) {
 	pq.Lock()
 	defer pq.Unlock()
@@ -278,6 +473,7 @@ func (pq *priorityqueue) Filter(f FilterFunc) {
 		pq.queue = filteredQueue
 		for k, v := range pq.pending {
 			if !f(v) {
+				pq.settle(v)
 				delete(pq.pending, k)
 			}
 		}
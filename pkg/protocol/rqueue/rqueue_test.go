@@ -125,3 +125,63 @@ func TestRequestQueue(t *testing.T) {
 	assert.Zero(t, len(pendingReqs))
 	assert.Zero(t, len(processingReq))
 }
+
+func TestRequestQueuePeerRouting(t *testing.T) {
+	q := rqueue.New()
+
+	var (
+		hashA = hornet.Hash(t5b1.EncodeTrytes("A"))
+		hashB = hornet.Hash(t5b1.EncodeTrytes("B"))
+		hashC = hornet.Hash(t5b1.EncodeTrytes("C"))
+	)
+
+	candidates := []string{"peerA", "peerB"}
+
+	for _, hash := range (hornet.Hashes{hashA, hashB, hashC}) {
+		assert.True(t, q.Enqueue(&rqueue.Request{Hash: hash, MilestoneIndex: 1}))
+	}
+
+	// without statistics the order of the candidates decides
+	rA := q.Next()
+	assert.Equal(t, "peerA", q.BestPeer(rA, candidates, 0))
+	q.Requested(rA, "peerA")
+	assert.Equal(t, 1, q.PeerStats("peerA").InFlight)
+
+	// the in-flight limit of peerA is reached
+	rB := q.Next()
+	assert.Equal(t, "peerB", q.BestPeer(rB, candidates, 1))
+	assert.Equal(t, "", q.BestPeer(rB, []string{"peerA"}, 1))
+	q.Requested(rB, "peerB")
+
+	// an answer of the requested peer updates its statistics
+	q.ReceivedFrom(rA.Hash, "peerA")
+	statsA := q.PeerStats("peerA")
+	assert.Zero(t, statsA.InFlight)
+	assert.Equal(t, uint64(1), statsA.Answered)
+
+	// answers of other peers only release the in-flight request
+	q.ReceivedFrom(rB.Hash, "peerA")
+	statsB := q.PeerStats("peerB")
+	assert.Zero(t, statsB.InFlight)
+	assert.Zero(t, statsB.Answered)
+
+	// retries prefer peers which were not asked for the request yet
+	rC := q.Next()
+	q.Requested(rC, "peerA")
+	assert.Equal(t, "peerB", q.BestPeer(rC, candidates, 0))
+	q.Requested(rC, "peerB")
+	assert.Equal(t, []string{"peerA", "peerB"}, rC.RequestedFrom)
+	assert.Zero(t, q.PeerStats("peerA").InFlight)
+	assert.Equal(t, 1, q.PeerStats("peerB").InFlight)
+
+	// the statistics of removed peers are dropped and their requests are no longer awaited
+	q.RemovePeer("peerB")
+	assert.Equal(t, rqueue.PeerStats{}, q.PeerStats("peerB"))
+
+	q.Requested(rC, "peerB")
+	q.RemovePeer("peerB")
+	q.Requested(rC, "peerA")
+	q.ReceivedFrom(rC.Hash, "peerB")
+	assert.Equal(t, rqueue.PeerStats{}, q.PeerStats("peerB"))
+	assert.Zero(t, q.PeerStats("peerA").InFlight)
+}
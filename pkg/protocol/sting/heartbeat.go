@@ -6,6 +6,17 @@ import (
 	"github.com/gohornet/hornet/pkg/model/milestone"
 )
 
+// HeartbeatFlagsName is the name of the heartbeat flags extension.
+const HeartbeatFlagsName = "HeartbeatFlags"
+
+// HeartbeatFlag is a flag in the optional flags byte of the heartbeat message.
+type HeartbeatFlag byte
+
+const (
+	// HeartbeatFlagLight denotes that the sender runs in light mode and therefore doesn't relay transactions.
+	HeartbeatFlagLight HeartbeatFlag = 1 << 0
+)
+
 // Heartbeat contains information about a nodes current solid and pruned milestone index.
 type Heartbeat struct {
 	SolidMilestoneIndex  milestone.Index `json:"solid_milestone_index"`
@@ -13,17 +24,30 @@ type Heartbeat struct {
 	LatestMilestoneIndex milestone.Index `json:"latest_milestone_index"`
 	ConnectedNeighbors   int             `json:"connected_neighbors"`
 	SyncedNeighbors      int             `json:"synced_neighbors"`
+	Light                bool            `json:"light"`
 }
 
-/// ParseHeartbeat parses the given message into a heartbeat.
-func ParseHeartbeat(data []byte) *Heartbeat {
-	return &Heartbeat{
+// ParseHeartbeat parses the given message into a heartbeat.
+// The flags byte is optional, as it is only sent to peers which support the heartbeat flags extension.
+func ParseHeartbeat(data []byte) (*Heartbeat, error) {
+	if len(data) < HeartbeatMinBytesLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	heartbeat := &Heartbeat{
 		SolidMilestoneIndex:  milestone.Index(binary.BigEndian.Uint32(data[:4])),
 		PrunedMilestoneIndex: milestone.Index(binary.BigEndian.Uint32(data[4:8])),
 		LatestMilestoneIndex: milestone.Index(binary.BigEndian.Uint32(data[8:12])),
 		ConnectedNeighbors:   int(data[12]),
 		SyncedNeighbors:      int(data[13]),
 	}
+
+	if len(data) > HeartbeatMinBytesLength {
+		flags := HeartbeatFlag(data[HeartbeatMinBytesLength])
+		heartbeat.Light = flags&HeartbeatFlagLight != 0
+	}
+
+	return heartbeat, nil
 }
 
 func HeartbeatCaller(handler interface{}, params ...interface{}) {
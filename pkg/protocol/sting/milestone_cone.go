@@ -0,0 +1,200 @@
+package sting
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+func init() {
+	if err := message.RegisterType(MessageTypeMilestoneConeRequest, MilestoneConeRequestMessageDefinition); err != nil {
+		panic(err)
+	}
+	if err := message.RegisterType(MessageTypeMilestoneCone, MilestoneConeMessageDefinition); err != nil {
+		panic(err)
+	}
+}
+
+// MilestoneConeSyncName is the name of the milestone cone sync extension.
+const MilestoneConeSyncName = "MilestoneConeSync"
+
+const (
+	MessageTypeMilestoneConeRequest message.Type = 7
+	MessageTypeMilestoneCone        message.Type = 8
+)
+
+const (
+	// The amount of bytes used for the requested milestone range (start and end index).
+	RequestedMilestoneConeRangeMsgBytesLength = 8
+
+	// The amount of bytes used for the header of a milestone cone message (milestone index and last chunk flag).
+	MilestoneConeHeaderBytesLength = 5
+
+	// The amount of bytes used for the length prefix of a transaction within a milestone cone message.
+	MilestoneConeTransactionLengthBytesLength = 2
+
+	// The maximum amount of milestones which can be requested with a single milestone cone request.
+	MaxMilestoneConeRequestRange = 50
+
+	// The maximum amount of transactions within a single milestone cone.
+	// Bigger cones are not sent in bulk and have to be requested transaction by transaction.
+	MaxMilestoneConeTransactions = 10000
+)
+
+var (
+	// ErrMilestoneConeTooBig is returned when a milestone cone contains more than MaxMilestoneConeTransactions transactions.
+	ErrMilestoneConeTooBig = errors.New("milestone cone too big")
+)
+
+var (
+	// The requested milestone cone range packet.
+	// Contains the start and end index of the milestones whose cones are requested.
+	MilestoneConeRequestMessageDefinition = &message.Definition{
+		ID:             MessageTypeMilestoneConeRequest,
+		MaxBytesLength: RequestedMilestoneConeRangeMsgBytesLength,
+		VariableLength: false,
+	}
+
+	// The milestone cone packet.
+	// Contains a batch of the transactions confirmed by the given milestone.
+	// Big cones are split into several packets, the last one is flagged.
+	MilestoneConeMessageDefinition = &message.Definition{
+		ID:             MessageTypeMilestoneCone,
+		MaxBytesLength: 65535,
+		VariableLength: true,
+	}
+)
+
+// MilestoneConeChunk is a part of the transactions confirmed by a milestone.
+type MilestoneConeChunk struct {
+	MilestoneIndex milestone.Index
+	// Whether this is the last chunk of the milestone cone.
+	Last bool
+	// The compressed transaction bytes.
+	Transactions [][]byte
+}
+
+// NewMilestoneConeRequestMessage creates a new milestone cone request message.
+func NewMilestoneConeRequestMessage(startIndex milestone.Index, endIndex milestone.Index) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+MilestoneConeRequestMessageDefinition.MaxBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeMilestoneConeRequest, MilestoneConeRequestMessageDefinition.MaxBytesLength); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, startIndex); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, endIndex); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ExtractRequestedMilestoneConeRange extracts the requested milestone range from the given source.
+func ExtractRequestedMilestoneConeRange(source []byte) (startIndex milestone.Index, endIndex milestone.Index, err error) {
+	if len(source) != RequestedMilestoneConeRangeMsgBytesLength {
+		return 0, 0, ErrInvalidSourceLength
+	}
+
+	startIndex = milestone.Index(binary.BigEndian.Uint32(source[:4]))
+	endIndex = milestone.Index(binary.BigEndian.Uint32(source[4:]))
+	if startIndex > endIndex {
+		return 0, 0, ErrInvalidSourceLength
+	}
+
+	return startIndex, endIndex, nil
+}
+
+// NewMilestoneConeMessages creates the milestone cone messages for the given transactions.
+// The transactions are split into as many messages as needed to respect the maximum message size.
+func NewMilestoneConeMessages(msIndex milestone.Index, txsData [][]byte) ([][]byte, error) {
+	var msgs [][]byte
+	var chunk [][]byte
+	chunkBytesLength := MilestoneConeHeaderBytesLength
+
+	for _, txData := range txsData {
+		txBytesLength := MilestoneConeTransactionLengthBytesLength + len(txData)
+		if chunkBytesLength+txBytesLength > int(MilestoneConeMessageDefinition.MaxBytesLength) && len(chunk) > 0 {
+			msg, err := newMilestoneConeMessage(msIndex, false, chunk, chunkBytesLength)
+			if err != nil {
+				return nil, err
+			}
+			msgs = append(msgs, msg)
+			chunk = nil
+			chunkBytesLength = MilestoneConeHeaderBytesLength
+		}
+		chunk = append(chunk, txData)
+		chunkBytesLength += txBytesLength
+	}
+
+	msg, err := newMilestoneConeMessage(msIndex, true, chunk, chunkBytesLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(msgs, msg), nil
+}
+
+func newMilestoneConeMessage(msIndex milestone.Index, last bool, txsData [][]byte, msgBytesLength int) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, int(tlv.HeaderMessageDefinition.MaxBytesLength)+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeMilestoneCone, uint16(msgBytesLength)); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, msIndex); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.BigEndian, last); err != nil {
+		return nil, err
+	}
+
+	for _, txData := range txsData {
+		if err := binary.Write(buf, binary.BigEndian, uint16(len(txData))); err != nil {
+			return nil, err
+		}
+
+		if err := binary.Write(buf, binary.BigEndian, txData); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParseMilestoneConeChunk parses the given milestone cone message data.
+func ParseMilestoneConeChunk(source []byte) (*MilestoneConeChunk, error) {
+	if len(source) < MilestoneConeHeaderBytesLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	chunk := &MilestoneConeChunk{
+		MilestoneIndex: milestone.Index(binary.BigEndian.Uint32(source[:4])),
+		Last:           source[4] != 0,
+	}
+
+	offset := MilestoneConeHeaderBytesLength
+	for offset < len(source) {
+		if offset+MilestoneConeTransactionLengthBytesLength > len(source) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		txBytesLength := int(binary.BigEndian.Uint16(source[offset : offset+MilestoneConeTransactionLengthBytesLength]))
+		offset += MilestoneConeTransactionLengthBytesLength
+
+		if txBytesLength == 0 || offset+txBytesLength > len(source) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		chunk.Transactions = append(chunk.Transactions, source[offset:offset+txBytesLength])
+		offset += txBytesLength
+	}
+
+	return chunk, nil
+}
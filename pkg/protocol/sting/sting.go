@@ -55,6 +55,9 @@ const (
 	// The amount of bytes used for a milestone index within a heartbeat packet.
 	HeartbeatMilestoneIndexBytesLength = 4
 
+	// The amount of bytes of a heartbeat packet without the optional flags byte.
+	HeartbeatMinBytesLength = HeartbeatMilestoneIndexBytesLength*3 + 2
+
 	// The index to use to request the latest milestone via a milestone request message.
 	LatestMilestoneRequestIndex = 0
 )
@@ -76,11 +79,11 @@ var (
 	}
 
 	// The heartbeat packet containing the current latest solid, pruned and latest milestone index,
-	// number of connected neighbors and number of synced neighbors.
+	// number of connected neighbors, number of synced neighbors and the optional flags byte.
 	HeartbeatMessageDefinition = &message.Definition{
 		ID:             MessageTypeHeartbeat,
-		MaxBytesLength: HeartbeatMilestoneIndexBytesLength*3 + 2,
-		VariableLength: false,
+		MaxBytesLength: HeartbeatMinBytesLength + 1,
+		VariableLength: true,
 	}
 
 	// The requested milestone index packet.
@@ -122,9 +125,16 @@ func NewTransactionRequestMessage(requestedHash hornet.Hash) ([]byte, error) {
 }
 
 // NewHeartbeatMessage creates a new heartbeat message.
-func NewHeartbeatMessage(solidMilestoneIndex milestone.Index, prunedMilestoneIndex milestone.Index, latestMilestoneIndex milestone.Index, connectedNeighbors uint8, syncedNeighbors uint8) ([]byte, error) {
-	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+HeartbeatMessageDefinition.MaxBytesLength))
-	if err := tlv.WriteHeader(buf, MessageTypeHeartbeat, HeartbeatMessageDefinition.MaxBytesLength); err != nil {
+// The flags byte is only appended if flags are given, since peers without support
+// for the heartbeat flags extension only accept heartbeats without it.
+func NewHeartbeatMessage(solidMilestoneIndex milestone.Index, prunedMilestoneIndex milestone.Index, latestMilestoneIndex milestone.Index, connectedNeighbors uint8, syncedNeighbors uint8, flags ...HeartbeatFlag) ([]byte, error) {
+	msgBytesLength := uint16(HeartbeatMinBytesLength)
+	if len(flags) > 0 {
+		msgBytesLength++
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeHeartbeat, msgBytesLength); err != nil {
 		return nil, err
 	}
 
@@ -148,6 +158,17 @@ func NewHeartbeatMessage(solidMilestoneIndex milestone.Index, prunedMilestoneInd
 		return nil, err
 	}
 
+	if len(flags) > 0 {
+		var combined HeartbeatFlag
+		for _, flag := range flags {
+			combined |= flag
+		}
+
+		if err := binary.Write(buf, binary.BigEndian, combined); err != nil {
+			return nil, err
+		}
+	}
+
 	return buf.Bytes(), nil
 }
 
@@ -0,0 +1,142 @@
+package sting
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+func init() {
+	if err := message.RegisterType(MessageTypeTransactionBatch, TransactionBatchMessageDefinition); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	// ErrTransactionBatchTooBig is returned when the transactions don't fit into a single transaction batch message.
+	ErrTransactionBatchTooBig = errors.New("transaction batch too big")
+)
+
+// TransactionBatchName is the name of the transaction batch feature set.
+const TransactionBatchName = "TransactionBatch"
+
+// TransactionBatchFeatureSet denotes the bit in the supported messages bitset of the handshake
+// which advertises the support for transaction batch messages. It is an optional feature set and not a protocol version,
+// therefore the highest bit of the first byte is used to keep clear of the bits of future protocol versions.
+const TransactionBatchFeatureSet = 1 << 7
+
+const (
+	MessageTypeTransactionBatch message.Type = 9
+)
+
+const (
+	// The amount of bytes used for the length prefix of a transaction within a transaction batch message.
+	TransactionBatchTransactionLengthBytesLength = 2
+
+	// The maximum amount of uncompressed bytes of a transaction batch.
+	// The limit ensures that even incompressible batches fit into a single message.
+	MaxTransactionBatchBytesLength = 64000
+)
+
+var (
+	// The transaction batch packet.
+	// Contains several length prefixed compressed transactions, compressed as a whole using DEFLATE.
+	TransactionBatchMessageDefinition = &message.Definition{
+		ID:             MessageTypeTransactionBatch,
+		MaxBytesLength: 65535,
+		VariableLength: true,
+	}
+)
+
+// NewTransactionBatchMessage creates a new transaction batch message for the given compressed transactions.
+func NewTransactionBatchMessage(txsData [][]byte) ([]byte, error) {
+	batchBytesLength := 0
+	for _, txData := range txsData {
+		batchBytesLength += TransactionBatchTransactionLengthBytesLength + len(txData)
+	}
+
+	if batchBytesLength > MaxTransactionBatchBytesLength {
+		return nil, ErrTransactionBatchTooBig
+	}
+
+	var compressed bytes.Buffer
+	w, err := flate.NewWriter(&compressed, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, txData := range txsData {
+		if err := binary.Write(w, binary.BigEndian, uint16(len(txData))); err != nil {
+			return nil, err
+		}
+
+		if _, err := w.Write(txData); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	if compressed.Len() > int(TransactionBatchMessageDefinition.MaxBytesLength) {
+		return nil, ErrTransactionBatchTooBig
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, int(tlv.HeaderMessageDefinition.MaxBytesLength)+compressed.Len()))
+	if err := tlv.WriteHeader(buf, MessageTypeTransactionBatch, uint16(compressed.Len())); err != nil {
+		return nil, err
+	}
+
+	if _, err := compressed.WriteTo(buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ExtractTransactionBatch decompresses the given transaction batch message data and returns the contained compressed transactions.
+func ExtractTransactionBatch(source []byte) ([][]byte, error) {
+	// limit the decompressed size to protect against decompression bombs
+	r := flate.NewReader(bytes.NewReader(source))
+	defer r.Close()
+
+	batch, err := ioutil.ReadAll(io.LimitReader(r, MaxTransactionBatchBytesLength+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(batch) > MaxTransactionBatchBytesLength {
+		return nil, ErrTransactionBatchTooBig
+	}
+
+	var txsData [][]byte
+	offset := 0
+	for offset < len(batch) {
+		if offset+TransactionBatchTransactionLengthBytesLength > len(batch) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		txBytesLength := int(binary.BigEndian.Uint16(batch[offset : offset+TransactionBatchTransactionLengthBytesLength]))
+		offset += TransactionBatchTransactionLengthBytesLength
+
+		if txBytesLength == 0 || txBytesLength > int(TransactionMessageDefinition.MaxBytesLength) || offset+txBytesLength > len(batch) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		txsData = append(txsData, batch[offset:offset+txBytesLength])
+		offset += txBytesLength
+	}
+
+	if len(txsData) == 0 {
+		return nil, ErrInvalidSourceLength
+	}
+
+	return txsData, nil
+}
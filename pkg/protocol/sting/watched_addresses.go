@@ -0,0 +1,91 @@
+package sting
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+func init() {
+	if err := message.RegisterType(MessageTypeWatchedAddresses, WatchedAddressesMessageDefinition); err != nil {
+		panic(err)
+	}
+}
+
+// WatchedAddressesName is the name of the watched addresses extension.
+const WatchedAddressesName = "WatchedAddresses"
+
+const (
+	MessageTypeWatchedAddresses message.Type = 10
+)
+
+const (
+	// The amount of bytes used for a byte encoded address within a watched addresses message.
+	WatchedAddressBytesLength = 49
+
+	// The maximum amount of addresses within a watched addresses message.
+	MaxWatchedAddresses = 100
+)
+
+var (
+	// ErrTooManyWatchedAddresses is returned when more than MaxWatchedAddresses addresses are watched.
+	ErrTooManyWatchedAddresses = errors.New("too many watched addresses")
+)
+
+var (
+	// The watched addresses packet.
+	// Contains the byte encoded addresses whose transactions a light node wants to receive.
+	WatchedAddressesMessageDefinition = &message.Definition{
+		ID:             MessageTypeWatchedAddresses,
+		MaxBytesLength: WatchedAddressBytesLength * MaxWatchedAddresses,
+		VariableLength: true,
+	}
+)
+
+// NewWatchedAddressesMessage creates a new watched addresses message.
+func NewWatchedAddressesMessage(addresses hornet.Hashes) ([]byte, error) {
+	if len(addresses) > MaxWatchedAddresses {
+		return nil, ErrTooManyWatchedAddresses
+	}
+
+	msgBytesLength := len(addresses) * WatchedAddressBytesLength
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+uint16(msgBytesLength)))
+	if err := tlv.WriteHeader(buf, MessageTypeWatchedAddresses, uint16(msgBytesLength)); err != nil {
+		return nil, err
+	}
+
+	for _, addr := range addresses {
+		if len(addr) != WatchedAddressBytesLength {
+			return nil, ErrInvalidSourceLength
+		}
+
+		if _, err := buf.Write(addr); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParseWatchedAddresses parses the given message into the watched addresses.
+func ParseWatchedAddresses(data []byte) (hornet.Hashes, error) {
+	if len(data)%WatchedAddressBytesLength != 0 {
+		return nil, ErrInvalidSourceLength
+	}
+
+	if len(data)/WatchedAddressBytesLength > MaxWatchedAddresses {
+		return nil, ErrTooManyWatchedAddresses
+	}
+
+	addresses := make(hornet.Hashes, 0, len(data)/WatchedAddressBytesLength)
+	for offset := 0; offset < len(data); offset += WatchedAddressBytesLength {
+		addr := make(hornet.Hash, WatchedAddressBytesLength)
+		copy(addr, data[offset:offset+WatchedAddressBytesLength])
+		addresses = append(addresses, addr)
+	}
+
+	return addresses, nil
+}
@@ -99,6 +99,13 @@ type WarpSync struct {
 	AdvancementRange int
 }
 
+// Checkpoint returns the current checkpoint of the synchronization (0 if the synchronization didn't start).
+func (ws *WarpSync) Checkpoint() milestone.Index {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.CurrentCheckpoint
+}
+
 // UpdateCurrent updates the current solid milestone index state.
 func (ws *WarpSync) UpdateCurrent(current milestone.Index) {
 	ws.mu.Lock()
@@ -0,0 +1,330 @@
+// Package spentaddresses implements the verifiable export file format for spent addresses.
+// The file consists of an uncompressed header followed by a gzip compressed stream of 49 byte addresses.
+// The header contains the amount of addresses and the Merkle root over all addresses in the order they appear in the file.
+package spentaddresses
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	_ "golang.org/x/crypto/blake2b" // import implementation
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+const (
+	// the hash function used to compute the Merkle root over all spent addresses in an export file
+	merkleHashFunc = crypto.BLAKE2b_512
+
+	// 4 (magic) + 1 (version) + 4 (ms index) = 9
+	fileCountOffset = 9
+)
+
+var (
+	// the magic bytes at the beginning of a spent addresses export file
+	fileMagic = []byte("HSAD")
+
+	SupportedFileVersions = []byte{1}
+
+	ErrUnsupportedFile    = errors.New("unsupported spent addresses file")
+	ErrMerkleRootMismatch = errors.New("spent addresses Merkle root does not match")
+	ErrCountMismatch      = errors.New("spent addresses count does not match")
+	ErrFileNotFound       = errors.New("spent addresses file not found")
+	ErrFileTrailingData   = errors.New("spent addresses file contains trailing data")
+	ErrOperationAborted   = errors.New("operation was aborted")
+)
+
+// StreamFunc writes all spent addresses as 49 byte hashes to the given writer
+// and returns the amount of written spent addresses.
+type StreamFunc func(w io.Writer, abortSignal <-chan struct{}) (int32, error)
+
+// FileHeader is the uncompressed header of a spent addresses export file.
+type FileHeader struct {
+	// the solid milestone index of the node at the time the file was created
+	MilestoneIndex milestone.Index
+	// the amount of spent addresses in the file
+	SpentAddressesCount int32
+	// the Merkle tree hash over all spent addresses in the order they appear in the file
+	MerkleRoot []byte
+}
+
+// merkleRootSize returns the size of the Merkle root in the file header.
+func merkleRootSize() int {
+	return merkleHashFunc.Size()
+}
+
+func writeFileHeader(w io.Writer, header *FileHeader) error {
+
+	if _, err := w.Write(fileMagic); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, SupportedFileVersions[0]); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, header.MilestoneIndex); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, header.SpentAddressesCount); err != nil {
+		return err
+	}
+
+	merkleRoot := make([]byte, merkleRootSize())
+	copy(merkleRoot, header.MerkleRoot)
+
+	return binary.Write(w, binary.LittleEndian, merkleRoot)
+}
+
+func readFileHeader(r io.Reader) (*FileHeader, error) {
+
+	magic := make([]byte, len(fileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, errors.Wrapf(ErrUnsupportedFile, "magic: %v", err)
+	}
+
+	if !bytes.Equal(magic, fileMagic) {
+		return nil, errors.Wrap(ErrUnsupportedFile, "wrong magic bytes")
+	}
+
+	var fileVersion byte
+	if err := binary.Read(r, binary.LittleEndian, &fileVersion); err != nil {
+		return nil, err
+	}
+
+	var supported bool
+	for _, v := range SupportedFileVersions {
+		if v == fileVersion {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, errors.Wrapf(ErrUnsupportedFile, "spent addresses file version is %d but this HORNET version only supports %v", fileVersion, SupportedFileVersions)
+	}
+
+	header := &FileHeader{
+		MerkleRoot: make([]byte, merkleRootSize()),
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &header.MilestoneIndex); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &header.SpentAddressesCount); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.ReadFull(r, header.MerkleRoot); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// IsExportFile checks whether the given file starts with the magic bytes of a spent addresses export file.
+func IsExportFile(filePath string) bool {
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(fileMagic))
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false
+	}
+
+	return bytes.Equal(magic, fileMagic)
+}
+
+// merkleWriter feeds all written spent addresses into a Merkle tree hash stream.
+type merkleWriter struct {
+	stream *whiteflag.TreeHashStream
+	buf    []byte
+}
+
+func newMerkleWriter() *merkleWriter {
+	return &merkleWriter{
+		stream: whiteflag.NewHasher(merkleHashFunc).NewTreeHashStream(),
+	}
+}
+
+func (w *merkleWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= 49 {
+		w.stream.Add(hornet.Hash(w.buf[:49]))
+		w.buf = w.buf[49:]
+	}
+	return len(p), nil
+}
+
+// WriteFile writes the spent addresses of the given stream to a compressed export file,
+// together with a header containing the amount of spent addresses and their Merkle root.
+// The file is written to a temporary file first, which replaces the given file after it was completely written.
+func WriteFile(filePath string, msIndex milestone.Index, stream StreamFunc, abortSignal <-chan struct{}) (*FileHeader, error) {
+
+	if _, fileErr := os.Stat(filePath); os.IsNotExist(fileErr) {
+		// create dir if it not exists
+		if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	filePathTmp := filePath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(filePathTmp)
+
+	exportFile, err := os.OpenFile(filePathTmp, os.O_RDWR|os.O_CREATE, 0660)
+	if err != nil {
+		return nil, err
+	}
+	defer exportFile.Close()
+
+	header := &FileHeader{
+		MilestoneIndex: msIndex,
+	}
+
+	// write the header with a WRONG spent addresses count and Merkle root
+	if err := writeFileHeader(exportFile, header); err != nil {
+		return nil, err
+	}
+
+	fileBufWriter := bufio.NewWriterSize(exportFile, 4096*2)
+	gzipWriter := gzip.NewWriter(fileBufWriter)
+	merkleWriter := newMerkleWriter()
+
+	// stream spent addresses into the file
+	spentAddressesCount, err := stream(io.MultiWriter(gzipWriter, merkleWriter), abortSignal)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := fileBufWriter.Flush(); err != nil {
+		return nil, err
+	}
+
+	if int(spentAddressesCount) != merkleWriter.stream.Count() {
+		return nil, errors.Wrapf(ErrCountMismatch, "written: %d, hashed: %d", spentAddressesCount, merkleWriter.stream.Count())
+	}
+
+	header.SpentAddressesCount = spentAddressesCount
+	header.MerkleRoot = merkleWriter.stream.Sum()
+
+	// seek to spent addresses count in the header and override it together with the Merkle root
+	if _, err := exportFile.Seek(fileCountOffset, 0); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(exportFile, binary.LittleEndian, header.SpentAddressesCount); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(exportFile, binary.LittleEndian, header.MerkleRoot); err != nil {
+		return nil, err
+	}
+
+	if err := exportFile.Sync(); err != nil {
+		return nil, err
+	}
+
+	if err := exportFile.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(filePathTmp, filePath); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// ForEachInFile reads the header of the given spent addresses export file
+// and calls the consumer for every spent address in the file.
+func ForEachInFile(filePath string, consumer func(address hornet.Hash) error) (*FileHeader, error) {
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Wrap(ErrFileNotFound, filePath)
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	fileBufReader := bufio.NewReader(file)
+
+	header, err := readFileHeader(fileBufReader)
+	if err != nil {
+		return nil, err
+	}
+
+	gzipReader, err := gzip.NewReader(fileBufReader)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	for i := int32(0); i < header.SpentAddressesCount; i++ {
+		spentAddrBuf := make(hornet.Hash, 49)
+		if _, err := io.ReadFull(gzipReader, spentAddrBuf); err != nil {
+			return nil, errors.Wrapf(ErrCountMismatch, "expected: %d, read: %d, error: %v", header.SpentAddressesCount, i, err)
+		}
+
+		if err := consumer(spentAddrBuf); err != nil {
+			return nil, err
+		}
+	}
+
+	// there must not be any additional data in the file
+	if n, _ := io.Copy(ioutil.Discard, gzipReader); n != 0 {
+		return nil, ErrFileTrailingData
+	}
+
+	return header, nil
+}
+
+// VerifyFile checks the integrity of the given spent addresses export file
+// by recomputing the Merkle root over all contained spent addresses.
+func VerifyFile(filePath string, abortSignal <-chan struct{}) (*FileHeader, error) {
+
+	merkleWriter := newMerkleWriter()
+
+	header, err := ForEachInFile(filePath, func(address hornet.Hash) error {
+		select {
+		case <-abortSignal:
+			return ErrOperationAborted
+		default:
+		}
+
+		_, err := merkleWriter.Write(address)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if merkleRoot := merkleWriter.stream.Sum(); !bytes.Equal(merkleRoot, header.MerkleRoot) {
+		return nil, errors.Wrapf(ErrMerkleRootMismatch, "file: %x, computed: %x", header.MerkleRoot, merkleRoot)
+	}
+
+	return header, nil
+}
@@ -0,0 +1,148 @@
+package spentaddresses
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+)
+
+func testAddresses(count int) hornet.Hashes {
+	addresses := make(hornet.Hashes, 0, count)
+	for i := 0; i < count; i++ {
+		address := make(hornet.Hash, 49)
+		binary.LittleEndian.PutUint32(address, uint32(i))
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+func streamAddresses(addresses hornet.Hashes) StreamFunc {
+	return func(w io.Writer, _ <-chan struct{}) (int32, error) {
+		for _, address := range addresses {
+			if _, err := w.Write(address); err != nil {
+				return 0, err
+			}
+		}
+		return int32(len(addresses)), nil
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "spentaddresses")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "export", "spent_addresses_5.bin")
+	addresses := testAddresses(1000)
+
+	written, err := WriteFile(filePath, 5, streamAddresses(addresses), nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, written.MilestoneIndex)
+	require.EqualValues(t, len(addresses), written.SpentAddressesCount)
+	require.True(t, IsExportFile(filePath))
+
+	// the temporary file was replaced
+	_, err = os.Stat(filePath + "_tmp")
+	require.True(t, os.IsNotExist(err))
+
+	verified, err := VerifyFile(filePath, nil)
+	require.NoError(t, err)
+	require.Equal(t, written, verified)
+
+	var imported hornet.Hashes
+	header, err := ForEachInFile(filePath, func(address hornet.Hash) error {
+		imported = append(imported, address)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, written, header)
+	require.Equal(t, addresses, imported)
+
+	// exporting the same addresses results in the same Merkle root
+	rewritten, err := WriteFile(filePath, 6, streamAddresses(addresses), nil)
+	require.NoError(t, err)
+	require.Equal(t, written.MerkleRoot, rewritten.MerkleRoot)
+}
+
+func TestExportImportEmpty(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "spentaddresses")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "spent_addresses_1.bin")
+
+	written, err := WriteFile(filePath, 1, streamAddresses(nil), nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, written.SpentAddressesCount)
+
+	verified, err := VerifyFile(filePath, nil)
+	require.NoError(t, err)
+	require.Equal(t, written, verified)
+}
+
+func TestVerifyFileDetectsTampering(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "spentaddresses")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "spent_addresses_5.bin")
+	_, err = WriteFile(filePath, 5, streamAddresses(testAddresses(10)), nil)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+
+	// a wrong Merkle root in the header
+	tampered := append([]byte{}, data...)
+	tampered[fileCountOffset+4] ^= 0xFF
+	require.NoError(t, ioutil.WriteFile(filePath, tampered, 0660))
+	_, err = VerifyFile(filePath, nil)
+	require.True(t, errors.Is(err, ErrMerkleRootMismatch))
+
+	// a wrong spent addresses count in the header
+	tampered = append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(tampered[fileCountOffset:], 11)
+	require.NoError(t, ioutil.WriteFile(filePath, tampered, 0660))
+	_, err = VerifyFile(filePath, nil)
+	require.True(t, errors.Is(err, ErrCountMismatch))
+
+	binary.LittleEndian.PutUint32(tampered[fileCountOffset:], 9)
+	require.NoError(t, ioutil.WriteFile(filePath, tampered, 0660))
+	_, err = VerifyFile(filePath, nil)
+	require.True(t, errors.Is(err, ErrFileTrailingData))
+
+	// wrong magic bytes
+	tampered = append([]byte{}, data...)
+	tampered[0] = 'X'
+	require.NoError(t, ioutil.WriteFile(filePath, tampered, 0660))
+	require.False(t, IsExportFile(filePath))
+	_, err = VerifyFile(filePath, nil)
+	require.True(t, errors.Is(err, ErrUnsupportedFile))
+
+	_, err = VerifyFile(filepath.Join(tempDir, "missing.bin"), nil)
+	require.True(t, errors.Is(err, ErrFileNotFound))
+}
+
+func TestVerifyFileAbort(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "spentaddresses")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "spent_addresses_5.bin")
+	_, err = WriteFile(filePath, 5, streamAddresses(testAddresses(10)), nil)
+	require.NoError(t, err)
+
+	abortSignal := make(chan struct{})
+	close(abortSignal)
+
+	_, err = VerifyFile(filePath, abortSignal)
+	require.True(t, errors.Is(err, ErrOperationAborted))
+}
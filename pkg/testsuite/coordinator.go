@@ -3,16 +3,20 @@ package testsuite
 import (
 	"crypto"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/iotaledger/iota.go/bundle"
 	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
 
 	"github.com/gohornet/hornet/pkg/model/coordinator"
+	"github.com/gohornet/hornet/pkg/model/coordinator/signer"
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/mselection"
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/testsuite/utils"
 	"github.com/gohornet/hornet/pkg/whiteflag"
@@ -40,7 +44,7 @@ func (te *TestEnvironment) configureCoordinator() {
 			bndl = append(bndl, b[i])
 		}
 
-		ms := te.StoreBundle(bndl, true) // no need to release, since we store all the bundles for later cleanup
+		ms := te.StoreBundle(bndl, isMilestone) // no need to release, since we store all the bundles for later cleanup
 
 		if isMilestone {
 			tangle.SetLatestMilestoneIndex(ms.GetBundle().GetMilestoneIndex())
@@ -49,7 +53,7 @@ func (te *TestEnvironment) configureCoordinator() {
 		return nil
 	}
 
-	te.coo = coordinator.New(cooSeed, cooSecLevel, merkleTreeDepth, mwm, fmt.Sprintf("%s/coordinator.state", te.tempDir), 10, te.powHandler, storeBundleFunc, merkleHashFunc)
+	te.coo = coordinator.New(signer.NewInProcessSigner(cooSeed, cooSecLevel), cooSecLevel, merkleTreeDepth, mwm, te.coordinatorStateFilePath(), 10, te.powHandler, storeBundleFunc, merkleHashFunc)
 	require.NotNil(te.testState, te.coo)
 
 	err := te.coo.InitMerkleTree(fmt.Sprintf("%s/pkg/testsuite/assets/coordinator.tree", searchProjectRootFolder()), cooAddress)
@@ -90,6 +94,35 @@ func (te *TestEnvironment) configureCoordinator() {
 	require.Equal(te.testState, 3, conf.TxsConfirmed)
 }
 
+// coordinatorStateFilePath returns the path to the state file of the coordinator.
+func (te *TestEnvironment) coordinatorStateFilePath() string {
+	return fmt.Sprintf("%s/coordinator.state", te.tempDir)
+}
+
+// CoordinatorState returns the current state of the coordinator.
+func (te *TestEnvironment) CoordinatorState() *coordinator.State {
+	return te.coo.State()
+}
+
+// RecoverCoordinatorState removes the state file of the coordinator and recovers the state
+// from the latest milestone of the given coordinator address in the database.
+func (te *TestEnvironment) RecoverCoordinatorState(cooAddress trinary.Hash) error {
+	if err := os.Remove(te.coordinatorStateFilePath()); err != nil && !os.IsNotExist(err) {
+		require.NoError(te.testState, err)
+	}
+
+	if err := te.coo.RecoverState(cooAddress); err != nil {
+		return err
+	}
+
+	// the recovered state was stored to the state file
+	state, err := coordinator.LoadStateFile(te.coordinatorStateFilePath())
+	require.NoError(te.testState, err)
+	require.Equal(te.testState, te.coo.State(), state)
+
+	return nil
+}
+
 // IssueAndConfirmMilestoneOnTip creates a milestone on top of a given tip.
 func (te *TestEnvironment) IssueAndConfirmMilestoneOnTip(tip hornet.Hash, createConfirmationGraph bool) *whiteflag.ConfirmedMilestoneStats {
 
@@ -147,3 +180,21 @@ func (te *TestEnvironment) IssueAndConfirmMilestoneOnTip(tip hornet.Hash, create
 
 	return confStats
 }
+
+// IssueAndConfirmMilestoneWithSelector passes the given bundles in the given order to the milestone selector,
+// issues a checkpoint on the selected tips and confirms it with a new milestone.
+// This allows to compare the milestone selection strategies on the same tangle.
+func (te *TestEnvironment) IssueAndConfirmMilestoneWithSelector(selector mselection.MilestoneSelector, bundles ...*tangle.CachedBundle) (hornet.Hashes, *whiteflag.ConfirmedMilestoneStats) {
+
+	for _, cachedBndl := range bundles {
+		selector.OnNewSolidBundle(cachedBndl.GetBundle())
+	}
+
+	tips, err := selector.SelectTips(1)
+	require.NoError(te.testState, err)
+
+	checkpointHash, err := te.coo.IssueCheckpoint(0, te.lastMilestoneHash, tips)
+	require.NoError(te.testState, err)
+
+	return tips, te.IssueAndConfirmMilestoneOnTip(checkpointHash, false)
+}
@@ -0,0 +1,36 @@
+package toolset
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/coordinator"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+// clears the record of the last signed milestone in the lease of the coordinator active/standby mode.
+// this is needed if an instance recorded a milestone, but failed to issue it, since the other instances wait for it.
+func leaseClearSigning(args []string) error {
+
+	if len(args) != 1 {
+		return errors.New("the index of the last signed milestone, which was never issued, has to be given for 'leaseclear'")
+	}
+
+	index, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid milestone index '%s': %w", args[0], err)
+	}
+
+	leaseDuration := time.Duration(config.NodeConfig.GetInt(config.CfgCoordinatorHALeaseDurationSeconds)) * time.Second
+	lease := coordinator.NewFileLease(config.NodeConfig.GetString(config.CfgCoordinatorHALeaseFilePath), leaseDuration)
+
+	if err := lease.ClearSigning(milestone.Index(index)); err != nil {
+		return err
+	}
+
+	fmt.Printf("successfully cleared the record of milestone %d, the next active instance signs it again\n", index)
+	return nil
+}
@@ -0,0 +1,81 @@
+package toolset
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/iota.go/merkle"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/coordinator"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+)
+
+// prints the key usage of the Merkle tree of the coordinator and the steps to rotate to a new Merkle tree.
+// the path to an already created new Merkle tree can be passed to check whether it is suitable for the rotation.
+func merkleTreeRotationPlan(args []string) error {
+
+	if len(args) > 1 {
+		return errors.New("too many arguments for 'merkleplan'")
+	}
+
+	depth := config.NodeConfig.GetInt(config.CfgCoordinatorMerkleTreeDepth)
+	interval := time.Duration(config.NodeConfig.GetInt(config.CfgCoordinatorIntervalSeconds)) * time.Second
+
+	var latestMilestoneIndex milestone.Index
+	state, err := coordinator.LoadStateFile(config.NodeConfig.GetString(config.CfgCoordinatorStateFilePath))
+	if err != nil {
+		fmt.Printf("coordinator state file could not be loaded, assuming the network wasn't bootstrapped yet: %v\n", err)
+	} else {
+		latestMilestoneIndex = state.LatestMilestoneIndex
+	}
+
+	usage := coordinator.NewMerkleKeyUsage(depth, latestMilestoneIndex)
+	timeLeft := usage.TimeLeft(interval)
+
+	fmt.Printf("coordinator address:    %v\n", config.NodeConfig.GetString(config.CfgCoordinatorAddress))
+	fmt.Printf("Merkle tree depth:      %d\n", usage.Depth)
+	fmt.Printf("latest milestone index: %d\n", latestMilestoneIndex)
+	fmt.Printf("keys used:              %d/%d\n", usage.Used, usage.Capacity)
+	fmt.Printf("keys remaining:         %d (%0.2f%%)\n", usage.Remaining, usage.RemainingPercentage())
+	fmt.Printf("keys used up in about:  %v (%v, milestone interval %v)\n\n", timeLeft.Truncate(time.Minute), time.Now().Add(timeLeft).Format(time.RFC3339), interval)
+
+	// milestone indexes continue after the rotation and the key index equals the milestone index,
+	// so the new Merkle tree has to be deeper than the current one to have any keys left.
+	minDepth := depth + 1
+
+	if len(args) == 1 {
+		newTree, err := merkle.LoadMerkleTreeFile(args[0])
+		if err != nil {
+			return fmt.Errorf("error loading new Merkle tree: %w", err)
+		}
+
+		if newTree.Root == config.NodeConfig.GetString(config.CfgCoordinatorAddress) {
+			return errors.New("the new Merkle tree has the same root as the current coordinator address, it has to be created with a new seed")
+		}
+
+		// the rotation happens before the current tree is used up
+		newUsage := coordinator.NewMerkleKeyUsage(newTree.Depth, milestone.Index(usage.Capacity))
+		if newUsage.Remaining == 0 {
+			return fmt.Errorf("the new Merkle tree has no keys left for the milestones after index %d, it needs a depth of at least %d", usage.Capacity, minDepth)
+		}
+
+		fmt.Printf("new coordinator address:   %v\n", newTree.Root)
+		fmt.Printf("new Merkle tree depth:     %d\n", newTree.Depth)
+		fmt.Printf("new keys after rotation:   at least %d, used up in about %v\n\n", newUsage.Remaining, newUsage.TimeLeft(interval).Truncate(time.Minute))
+	}
+
+	fmt.Println("rotation plan:")
+	if len(args) == 0 {
+		fmt.Printf("  1. create a new Merkle tree with a new seed and a depth of at least %d with 'tool merkle' (use a different '%s')\n", minDepth, config.CfgCoordinatorMerkleTreeFilePath)
+		fmt.Println("     and check it with 'tool merkleplan <new Merkle tree file>'")
+	} else {
+		fmt.Println("  1. the new Merkle tree was checked")
+	}
+	fmt.Printf("  2. announce the new coordinator address and Merkle tree depth, nodes have to update '%s' and '%s'\n", config.CfgCoordinatorAddress, config.CfgCoordinatorMerkleTreeDepth)
+	fmt.Printf("  3. stop the coordinator before milestone %d is due, at the latest at %v\n", usage.Capacity, time.Now().Add(timeLeft).Format(time.RFC3339))
+	fmt.Println("  4. start the coordinator with the new Merkle tree and the new seed, the milestone indexes continue with the next index")
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package toolset
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/coordinator/signer"
+)
+
+// loads the PIN of the software HSM token from the environment.
+func loadHSMPin() (string, error) {
+	pin, exists := os.LookupEnv("COO_HSM_PIN")
+	if !exists || len(pin) == 0 {
+		return "", errors.New("environment variable 'COO_HSM_PIN' not set")
+	}
+	return pin, nil
+}
+
+// creates the signer which is served by the signing daemon.
+// the used key indexes are persisted in the state file, or in the token if "softhsm" is given.
+func daemonSigner(args []string) (signer.Signer, error) {
+
+	securityLvl := consts.SecurityLevel(config.NodeConfig.GetInt(config.CfgCoordinatorSecurityLevel))
+
+	if len(args) == 0 {
+		seed, err := config.LoadHashFromEnvironment("COO_SEED", consts.HashTrytesSize)
+		if err != nil {
+			return nil, err
+		}
+		seed, _ = signer.NormalizeSeed(seed)
+
+		inProcessSigner, err := signer.NewPersistentInProcessSigner(seed, securityLvl, config.NodeConfig.GetString(config.CfgCoordinatorSignerStateFilePath))
+		if err != nil {
+			return nil, err
+		}
+		return inProcessSigner, nil
+	}
+
+	if strings.ToLower(args[0]) != "softhsm" {
+		return nil, fmt.Errorf("unknown signer '%s' for 'signer'", args[0])
+	}
+
+	pin, err := loadHSMPin()
+	if err != nil {
+		return nil, err
+	}
+
+	hsm, err := signer.OpenSoftHSM(config.NodeConfig.GetString(config.CfgCoordinatorSignerSoftHSMTokenPath), securityLvl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := hsm.Login(pin); err != nil {
+		return nil, err
+	}
+
+	return hsm, nil
+}
+
+// runs a signing daemon for the "remote" signer of the coordinator.
+// the seed is loaded from COO_SEED, or from the software HSM token if "softhsm" is given.
+func signingDaemon(args []string) error {
+
+	if len(args) > 1 {
+		return errors.New("too many arguments for 'signer'")
+	}
+
+	milestoneSigner, err := daemonSigner(args)
+	if err != nil {
+		return err
+	}
+
+	server, err := signer.NewServer(milestoneSigner)
+	if err != nil {
+		return err
+	}
+
+	// only the user running the daemon and the coordinator is allowed to request signatures
+	socketPath := config.NodeConfig.GetString(config.CfgCoordinatorSignerRemoteSocketPath)
+	listener, err := signer.Listen(socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socketPath)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		_ = listener.Close()
+	}()
+
+	fmt.Printf("signing daemon listening on %s\n", socketPath)
+
+	if err := server.Serve(listener); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+		return err
+	}
+
+	fmt.Println("signing daemon stopped")
+	return nil
+}
+
+// creates a software HSM token for the coordinator seed loaded from COO_SEED, protected by the PIN loaded from COO_HSM_PIN.
+func softHSMTokenCreate(args []string) error {
+
+	if len(args) > 1 {
+		return errors.New("too many arguments for 'hsmtoken'")
+	}
+
+	label := "coordinator"
+	if len(args) == 1 {
+		label = args[0]
+	}
+
+	seed, err := config.LoadHashFromEnvironment("COO_SEED", consts.HashTrytesSize)
+	if err != nil {
+		return err
+	}
+	seed, _ = signer.NormalizeSeed(seed)
+
+	pin, err := loadHSMPin()
+	if err != nil {
+		return err
+	}
+
+	tokenPath := config.NodeConfig.GetString(config.CfgCoordinatorSignerSoftHSMTokenPath)
+	if err := signer.CreateSoftHSMToken(tokenPath, label, pin, seed, consts.SecurityLevel(config.NodeConfig.GetInt(config.CfgCoordinatorSecurityLevel))); err != nil {
+		return err
+	}
+
+	fmt.Printf("successfully created software HSM token '%s' at %s\n", label, tokenPath)
+	return nil
+}
@@ -8,10 +8,14 @@ import (
 
 var (
 	tools = map[string]func([]string) error{
-		"pwdhash": hashPasswordAndSalt,
-		"seedgen": seedGen,
-		"list":    listTools,
-		"merkle":  merkleTreeCreate,
+		"pwdhash":    hashPasswordAndSalt,
+		"seedgen":    seedGen,
+		"list":       listTools,
+		"merkle":     merkleTreeCreate,
+		"signer":     signingDaemon,
+		"hsmtoken":   softHSMTokenCreate,
+		"merkleplan": merkleTreeRotationPlan,
+		"leaseclear": leaseClearSigning,
 	}
 )
 
@@ -58,6 +62,10 @@ func listTools(args []string) error {
 	fmt.Println("pwdhash: generates a sha265 sum from your password and salt")
 	fmt.Println("seedgen: generates an autopeering seed")
 	fmt.Println("merkle: generates a Merkle tree for coordinator plugin")
+	fmt.Println("signer: runs a signing daemon for the remote signer of the coordinator plugin")
+	fmt.Println("hsmtoken: creates a software HSM token for the coordinator plugin")
+	fmt.Println("merkleplan: shows the Merkle key usage of the coordinator plugin and plans the rotation to a new Merkle tree")
+	fmt.Println("leaseclear: clears the last signed milestone of the coordinator lease, if it was never issued")
 
 	return nil
 }
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidCronSchedule is returned when a cron schedule could not be parsed.
+	ErrInvalidCronSchedule = errors.New("invalid cron schedule")
+)
+
+// cronField holds the allowed range of a single field of a cron schedule.
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 6},
+}
+
+// CronSchedule is a parsed cron-like schedule with the five fields
+// "minute hour day-of-month month day-of-week".
+// Every field supports "*", single values, ranges ("1-5"), lists ("1,15") and steps ("*/10", "0-30/5").
+// Like in standard cron, a day matches if either the day-of-month or the day-of-week field matches
+// in case both fields are restricted (don't start with "*").
+type CronSchedule struct {
+	minutes     map[int]struct{}
+	hours       map[int]struct{}
+	daysOfMonth map[int]struct{}
+	months      map[int]struct{}
+	daysOfWeek  map[int]struct{}
+
+	// whether the day-of-month and day-of-week fields are both restricted
+	daysEitherMatch bool
+}
+
+// ParseCronSchedule parses a cron-like schedule, e.g. "0 */6 * * *".
+func ParseCronSchedule(schedule string) (*CronSchedule, error) {
+
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return nil, errors.Wrapf(ErrInvalidCronSchedule, "expected %d fields, got %d: \"%s\"", len(cronFields), len(fields), schedule)
+	}
+
+	values := make([]map[int]struct{}, len(cronFields))
+	for i, field := range fields {
+		parsed, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = parsed
+	}
+
+	return &CronSchedule{
+		minutes:     values[0],
+		hours:       values[1],
+		daysOfMonth: values[2],
+		months:      values[3],
+		daysOfWeek:  values[4],
+
+		daysEitherMatch: !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, def cronField) (map[int]struct{}, error) {
+
+	values := make(map[int]struct{})
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx != -1 {
+			var err error
+			if step, err = strconv.Atoi(part[idx+1:]); err != nil || step < 1 {
+				return nil, errors.Wrapf(ErrInvalidCronSchedule, "invalid step in %s field: \"%s\"", def.name, part)
+			}
+			part = part[:idx]
+		}
+
+		start, end := def.min, def.max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var errStart, errEnd error
+			start, errStart = strconv.Atoi(bounds[0])
+			end, errEnd = strconv.Atoi(bounds[1])
+			if errStart != nil || errEnd != nil {
+				return nil, errors.Wrapf(ErrInvalidCronSchedule, "invalid range in %s field: \"%s\"", def.name, part)
+			}
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, errors.Wrapf(ErrInvalidCronSchedule, "invalid value in %s field: \"%s\"", def.name, part)
+			}
+			start = value
+			if step == 1 {
+				end = value
+			}
+		}
+
+		if start < def.min || end > def.max || start > end {
+			return nil, errors.Wrapf(ErrInvalidCronSchedule, "%s field out of range [%d-%d]: \"%s\"", def.name, def.min, def.max, part)
+		}
+
+		for value := start; value <= end; value += step {
+			values[value] = struct{}{}
+		}
+	}
+
+	return values, nil
+}
+
+// Matches returns whether the given time matches the schedule (with a resolution of one minute).
+func (c *CronSchedule) Matches(t time.Time) bool {
+	_, minuteMatch := c.minutes[t.Minute()]
+	_, hourMatch := c.hours[t.Hour()]
+	_, monthMatch := c.months[int(t.Month())]
+
+	return minuteMatch && hourMatch && c.dayMatches(t) && monthMatch
+}
+
+// dayMatches returns whether the day of the given time matches the day-of-month and day-of-week fields.
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	_, dayOfMonthMatch := c.daysOfMonth[t.Day()]
+	_, dayOfWeekMatch := c.daysOfWeek[int(t.Weekday())]
+
+	if c.daysEitherMatch {
+		return dayOfMonthMatch || dayOfWeekMatch
+	}
+	return dayOfMonthMatch && dayOfWeekMatch
+}
+
+// Next returns the first time after the given time that matches the schedule.
+// It returns the zero time if there is no match within the next five years.
+func (c *CronSchedule) Next(after time.Time) time.Time {
+
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if _, monthMatch := c.months[int(t.Month())]; !monthMatch {
+			// skip to the beginning of the next month
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !c.dayMatches(t) {
+			// skip to the beginning of the next day
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if _, hourMatch := c.hours[t.Hour()]; !hourMatch {
+			// skip to the beginning of the next hour
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if _, minuteMatch := c.minutes[t.Minute()]; !minuteMatch {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
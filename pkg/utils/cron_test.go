@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+
+	for _, schedule := range []string{"* * * * *", "0 0 * * *", "*/15 1-5 1,15 * 0-6", "30 12 1 1/3 *"} {
+		_, err := ParseCronSchedule(schedule)
+		require.NoError(t, err, schedule)
+	}
+
+	for _, schedule := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := ParseCronSchedule(schedule)
+		require.True(t, errors.Is(err, ErrInvalidCronSchedule), schedule)
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+
+	start := time.Date(2020, time.November, 15, 10, 17, 42, 0, time.UTC)
+
+	tests := []struct {
+		schedule string
+		next     time.Time
+	}{
+		{"* * * * *", time.Date(2020, time.November, 15, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2020, time.November, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 0 * * *", time.Date(2020, time.November, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 * *", time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// 15.11.2020 is a sunday
+		{"0 9 * * 1", time.Date(2020, time.November, 16, 9, 0, 0, 0, time.UTC)},
+		// day-of-month and day-of-week are ORed if both are restricted
+		{"0 0 1 * 1", time.Date(2020, time.November, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 16 * 3", time.Date(2020, time.November, 16, 0, 0, 0, 0, time.UTC)},
+		// but ANDed if one of them starts with "*"
+		{"0 0 */2 * 1", time.Date(2020, time.November, 23, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		schedule, err := ParseCronSchedule(test.schedule)
+		require.NoError(t, err)
+
+		next := schedule.Next(start)
+		require.Equal(t, test.next, next, test.schedule)
+		require.True(t, schedule.Matches(next), test.schedule)
+	}
+
+	schedule, err := ParseCronSchedule("0 0 31 2 *")
+	require.NoError(t, err)
+	require.True(t, schedule.Next(start).IsZero())
+}
+
+func TestCronScheduleMatchesDays(t *testing.T) {
+
+	schedule, err := ParseCronSchedule("0 0 1 * 1")
+	require.NoError(t, err)
+
+	// 01.11.2020 is a sunday, 16.11.2020 is a monday, 17.11.2020 is a tuesday
+	require.True(t, schedule.Matches(time.Date(2020, time.November, 1, 0, 0, 0, 0, time.UTC)))
+	require.True(t, schedule.Matches(time.Date(2020, time.November, 16, 0, 0, 0, 0, time.UTC)))
+	require.False(t, schedule.Matches(time.Date(2020, time.November, 17, 0, 0, 0, 0, time.UTC)))
+
+	schedule, err = ParseCronSchedule("0 0 1 * *")
+	require.NoError(t, err)
+
+	require.True(t, schedule.Matches(time.Date(2020, time.November, 1, 0, 0, 0, 0, time.UTC)))
+	require.False(t, schedule.Matches(time.Date(2020, time.November, 16, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomically writes the given data to a temporary file in the directory of the given file path,
+// syncs it to disk and renames it over the given file path, so that a crash leaves either the old or the new content.
+func WriteFileAtomically(filePath string, data []byte, perm os.FileMode) error {
+	tmpFile, err := ioutil.TempFile(filepath.Dir(filePath), filepath.Base(filePath)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpFilePath := tmpFile.Name()
+
+	// removes the temporary file if it wasn't renamed
+	defer os.Remove(tmpFilePath)
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+
+	if err := tmpFile.Chmod(perm); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpFilePath, filePath); err != nil {
+		return err
+	}
+
+	// sync the directory, so that the rename is persisted as well
+	if dir, err := os.Open(filepath.Dir(filePath)); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+
+	return nil
+}
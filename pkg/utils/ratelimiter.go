@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the throughput to a given amount of bytes per second.
+// Up to one second worth of bytes can be processed in a burst.
+// A nil RateLimiter doesn't limit the throughput.
+type RateLimiter struct {
+	mu             sync.Mutex
+	bytesPerSecond float64
+	tokens         float64
+	lastUpdate     time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter for the given amount of bytes per second.
+// Returns nil if bytesPerSecond is not positive, which disables the limit.
+func NewRateLimiter(bytesPerSecond int) *RateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	return &RateLimiter{
+		bytesPerSecond: float64(bytesPerSecond),
+		tokens:         float64(bytesPerSecond),
+		lastUpdate:     time.Now(),
+	}
+}
+
+// Wait blocks until the given amount of bytes can be processed without exceeding the limit.
+// Returns false if the abort signal was triggered while waiting.
+func (l *RateLimiter) Wait(bytes int, abortSignal <-chan struct{}) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.lastUpdate).Seconds() * l.bytesPerSecond
+	if l.tokens > l.bytesPerSecond {
+		l.tokens = l.bytesPerSecond
+	}
+	l.lastUpdate = now
+
+	// reserve the bytes, the tokens get negative if we have to wait
+	l.tokens -= float64(bytes)
+	wait := time.Duration(-l.tokens / l.bytesPerSecond * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-abortSignal:
+		return false
+	}
+}
+
+// Allow takes the given amount of tokens if they are available without waiting.
+// Returns false if taking them would exceed the limit.
+func (l *RateLimiter) Allow(amount int) bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.lastUpdate).Seconds() * l.bytesPerSecond
+	if l.tokens > l.bytesPerSecond {
+		l.tokens = l.bytesPerSecond
+	}
+	l.lastUpdate = now
+
+	if l.tokens < float64(amount) {
+		return false
+	}
+	l.tokens -= float64(amount)
+	return true
+}
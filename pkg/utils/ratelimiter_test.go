@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	limiter := NewRateLimiter(2)
+
+	// a burst of one second is allowed
+	require.True(t, limiter.Allow(1))
+	require.True(t, limiter.Allow(1))
+	require.False(t, limiter.Allow(1))
+
+	// no limit is applied without a rate
+	limiter = NewRateLimiter(0)
+	require.Nil(t, limiter)
+	require.True(t, limiter.Allow(100))
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := NewRateLimiter(1000)
+
+	// the burst is processed without waiting
+	start := time.Now()
+	require.True(t, limiter.Wait(1000, nil))
+	require.Less(t, int64(time.Since(start)), int64(50*time.Millisecond))
+
+	// exceeding the limit waits until enough bytes are available again
+	require.True(t, limiter.Wait(100, nil))
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(90*time.Millisecond))
+
+	// the waiting is aborted by the abort signal
+	abortSignal := make(chan struct{})
+	close(abortSignal)
+	require.False(t, limiter.Wait(10000, abortSignal))
+
+	// no limit is applied without a rate
+	require.True(t, (*RateLimiter)(nil).Wait(10000, nil))
+}
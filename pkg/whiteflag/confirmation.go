@@ -22,6 +22,8 @@ type ConfirmedMilestoneStats struct {
 	TxsZeroValue     int
 	Collecting       time.Duration
 	Total            time.Duration
+	// StoreErr is the error which occurred while storing the conflicts and the confirmation of the milestone.
+	StoreErr error
 }
 
 // ConfirmMilestone traverses a milestone and collects all unconfirmed tx,
@@ -77,6 +79,12 @@ func ConfirmMilestone(cachedTxMetas map[string]*tangle.CachedMetadata, cachedMsB
 
 	tc := time.Now()
 
+	// the conflicts and the confirmation are stored before the ledger diff is applied,
+	// so that they are available for every applied milestone. if the node crashes before the diff is applied,
+	// they are overwritten when the milestone is confirmed again.
+	// they are not needed to confirm the milestone, so failures are reported without stopping the confirmation.
+	storeErr := storeMilestoneConfirmation(msBundle.GetTailHash(), milestoneIndex, mutations)
+
 	err = tangle.ApplyLedgerDiffWithoutLocking(mutations.AddressMutations, milestoneIndex)
 	if err != nil {
 		return nil, fmt.Errorf("confirmMilestone: ApplyLedgerDiff failed with Error: %v", err)
@@ -127,7 +135,8 @@ func ConfirmMilestone(cachedTxMetas map[string]*tangle.CachedMetadata, cachedMsB
 	}
 
 	conf := &ConfirmedMilestoneStats{
-		Index: milestoneIndex,
+		Index:    milestoneIndex,
+		StoreErr: storeErr,
 	}
 
 	confirmationTime := cachedMsTailTx.GetTransaction().GetTimestamp()
@@ -191,3 +200,26 @@ func ConfirmMilestone(cachedTxMetas map[string]*tangle.CachedMetadata, cachedMsB
 
 	return conf, nil
 }
+
+// storeMilestoneConfirmation stores the reasons why bundles were excluded as conflicting
+// and the result of the white-flag confirmation of the given milestone.
+func storeMilestoneConfirmation(milestoneHash hornet.Hash, milestoneIndex milestone.Index, mutations *WhiteFlagMutations) error {
+
+	if err := tangle.StoreConflictsForMilestone(milestoneIndex, mutations.Conflicts); err != nil {
+		return fmt.Errorf("confirmMilestone: StoreConflicts failed with Error: %w", err)
+	}
+
+	if err := tangle.StoreMilestoneConfirmation(&tangle.MilestoneConfirmation{
+		MilestoneIndex:           milestoneIndex,
+		MilestoneHash:            milestoneHash,
+		TailsIncluded:            mutations.TailsIncluded,
+		TailsExcludedConflicting: mutations.TailsExcludedConflicting,
+		TailsExcludedZeroValue:   mutations.TailsExcludedZeroValue,
+		TailsReferenced:          mutations.TailsReferenced,
+		MerkleTreeHash:           mutations.MerkleTreeHash,
+	}); err != nil {
+		return fmt.Errorf("confirmMilestone: StoreMilestoneConfirmation failed with Error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package whiteflag
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+var (
+	// ErrNoTipsGiven is returned when a white-flag confirmation should be simulated without any tips.
+	ErrNoTipsGiven = errors.New("no tips given")
+
+	// ErrTooManyTipsGiven is returned when a white-flag confirmation should be simulated with more tips than allowed.
+	ErrTooManyTipsGiven = errors.New("too many tips given")
+)
+
+// SimulateWhiteFlagMutations computes the ledger changes in accordance to the white-flag rules for the cone referenced by the given tips,
+// as if a milestone referencing these tips would be issued. Nothing is applied to the ledger.
+// The tips are traversed in the given order, so the result of trunk and branch of a milestone equals the result of the tips trunk, branch.
+// The ledger state is read locked while the mutations are computed, therefore the amount of tips and traversed transactions is limited.
+// The simulation is aborted if the abortSignal is closed or if more than maxTraversedTxs transactions were traversed.
+func SimulateWhiteFlagMutations(merkleTreeHashFunc crypto.Hash, tips hornet.Hashes, maxTips int, maxTraversedTxs int, abortSignal <-chan struct{}) (*WhiteFlagMutations, error) {
+
+	if len(tips) == 0 {
+		return nil, ErrNoTipsGiven
+	}
+
+	if len(tips) > maxTips {
+		return nil, fmt.Errorf("%w: %d given, %d allowed", ErrTooManyTipsGiven, len(tips), maxTips)
+	}
+
+	cachedTxMetas := make(map[string]*tangle.CachedMetadata)
+	cachedBundles := make(map[string]*tangle.CachedBundle)
+
+	defer func() {
+		// All releases are forced since the cone is not needed anymore
+
+		// release all bundles at the end
+		for _, cachedBundle := range cachedBundles {
+			cachedBundle.Release(true) // bundle -1
+		}
+
+		// Release all tx metadata at the end
+		for _, cachedTxMeta := range cachedTxMetas {
+			cachedTxMeta.Release(true) // meta -1
+		}
+	}()
+
+	tangle.ReadLockLedger()
+	defer tangle.ReadUnlockLedger()
+
+	return computeWhiteFlagMutations(cachedTxMetas, cachedBundles, merkleTreeHashFunc, maxTraversedTxs, abortSignal, tips[0], tips[1:]...)
+}
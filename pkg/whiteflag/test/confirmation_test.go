@@ -0,0 +1,76 @@
+package test
+
+import (
+	"testing"
+
+	_ "golang.org/x/crypto/blake2b"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/testsuite/utils"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+func TestStoredMilestoneConfirmation(t *testing.T) {
+
+	// Fill up the balances
+	balances := make(map[string]uint64)
+	balances[string(utils.GenerateAddress(t, seed1, 0))] = 1000
+
+	te := testsuite.SetupTestEnvironment(t, balances, 3, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	// Valid transfer 100 from seed1[0] to seed2[0]
+	bundleA := te.AttachAndStoreBundle(te.Milestones[0].GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ValueTx(t, "A", seed1, 0, 1000, seed2, 0, 100))
+	// Invalid transfer 10 from seed3[0] to seed2[0] (insufficient funds)
+	bundleB := te.AttachAndStoreBundle(te.Milestones[2].GetBundle().GetTailHash(), bundleA.GetBundle().GetTailHash(), utils.ValueTx(t, "B", seed3, 0, 99999, seed2, 0, 10))
+	// Zero value transaction
+	bundleC := te.AttachAndStoreBundle(bundleB.GetBundle().GetTailHash(), bundleA.GetBundle().GetTailHash(), utils.ZeroValueTx(t, "C"))
+
+	tailA := bundleA.GetBundle().GetTailHash()
+	tailB := bundleB.GetBundle().GetTailHash()
+	tailC := bundleC.GetBundle().GetTailHash()
+
+	confStats := te.IssueAndConfirmMilestoneOnTip(tailC, false)
+
+	conf, err := tangle.GetMilestoneConfirmation(confStats.Index)
+	require.NoError(t, err)
+	require.NotNil(t, conf)
+
+	ms := te.Milestones[len(te.Milestones)-1].GetBundle()
+	require.Equal(t, confStats.Index, conf.MilestoneIndex)
+	require.Equal(t, ms.GetTailHash(), conf.MilestoneHash)
+	require.Equal(t, hornet.Hashes{tailA}, conf.TailsIncluded)
+	require.Equal(t, hornet.Hashes{tailB}, conf.TailsExcludedConflicting)
+	// the milestone itself is a zero value bundle in its own cone
+	require.Equal(t, hornet.Hashes{tailC, ms.GetTailHash()}, conf.TailsExcludedZeroValue)
+	require.Equal(t, hornet.Hashes{tailA, tailB, tailC, ms.GetTailHash()}, conf.TailsReferenced)
+
+	referencedCount, stored, err := tangle.GetMilestoneConfirmationReferencedCount(confStats.Index)
+	require.NoError(t, err)
+	require.True(t, stored)
+	require.Equal(t, len(conf.TailsReferenced), referencedCount)
+
+	// the included tails can be proven against the Merkle tree hash signed by the coordinator
+	merkleTreeHash, err := ms.GetMilestoneMerkleTreeHash()
+	require.NoError(t, err)
+	require.Equal(t, merkleTreeHash, conf.MerkleTreeHash)
+
+	hasher := whiteflag.NewHasher(tangle.GetMilestoneMerkleHashFunc())
+	auditPath, err := hasher.AuditPath(conf.TailsIncluded, 0)
+	require.NoError(t, err)
+	require.True(t, hasher.VerifyAuditPath(tailA, 0, len(conf.TailsIncluded), auditPath, merkleTreeHash))
+	require.False(t, hasher.VerifyAuditPath(tailB, 0, len(conf.TailsIncluded), auditPath, merkleTreeHash))
+
+	require.NoError(t, tangle.DeleteMilestoneConfirmation(confStats.Index))
+	conf, err = tangle.GetMilestoneConfirmation(confStats.Index)
+	require.NoError(t, err)
+	require.Nil(t, conf)
+
+	_, stored, err = tangle.GetMilestoneConfirmationReferencedCount(confStats.Index)
+	require.NoError(t, err)
+	require.False(t, stored)
+}
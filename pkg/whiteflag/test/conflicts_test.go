@@ -0,0 +1,62 @@
+package test
+
+import (
+	"testing"
+
+	_ "golang.org/x/crypto/blake2b"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/testsuite/utils"
+)
+
+func TestWhiteFlagConflictReasons(t *testing.T) {
+
+	// Fill up the balances
+	balances := make(map[string]uint64)
+	balances[string(utils.GenerateAddress(t, seed1, 0))] = 1000
+
+	te := testsuite.SetupTestEnvironment(t, balances, 3, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	// Valid transfer 100 from seed1[0] to seed2[0]
+	bundleA := te.AttachAndStoreBundle(te.Milestones[0].GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ValueTx(t, "A", seed1, 0, 1000, seed2, 0, 100))
+	// Double spend of seed1[0], transfer 200 to seed3[0]
+	bundleB := te.AttachAndStoreBundle(bundleA.GetBundle().GetTailHash(), te.Milestones[2].GetBundle().GetTailHash(), utils.ValueTx(t, "B", seed1, 0, 1000, seed3, 0, 200))
+	// Invalid transfer 10 from seed4[0] to seed2[0] (insufficient funds)
+	bundleC := te.AttachAndStoreBundle(bundleB.GetBundle().GetTailHash(), bundleB.GetBundle().GetTailHash(), utils.ValueTx(t, "C", seed4, 0, 99999, seed2, 0, 10))
+
+	conf := te.IssueAndConfirmMilestoneOnTip(bundleC.GetBundle().GetTailHash(), false)
+	require.Equal(t, 8, conf.TxsConflicting)
+
+	conflictB, err := tangle.GetConflict(conf.Index, bundleB.GetBundle().GetTailHash())
+	require.NoError(t, err)
+	require.NotNil(t, conflictB)
+	require.Equal(t, tangle.ConflictDoubleSpend, conflictB.Reason)
+	require.Equal(t, hornet.Hash(utils.GenerateAddress(t, seed1, 0)), conflictB.Address)
+	require.Equal(t, bundleA.GetBundle().GetTailHash(), conflictB.ConflictingTailHash)
+
+	conflictC, err := tangle.GetConflict(conf.Index, bundleC.GetBundle().GetTailHash())
+	require.NoError(t, err)
+	require.NotNil(t, conflictC)
+	require.Equal(t, tangle.ConflictInsufficientBalance, conflictC.Reason)
+	require.Equal(t, hornet.Hash(utils.GenerateAddress(t, seed4, 0)), conflictC.Address)
+	require.Nil(t, conflictC.ConflictingTailHash)
+
+	// the included bundle is not conflicting
+	conflictA, err := tangle.GetConflict(conf.Index, bundleA.GetBundle().GetTailHash())
+	require.NoError(t, err)
+	require.Nil(t, conflictA)
+
+	conflicts, err := tangle.GetConflictsForMilestone(conf.Index)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 2)
+
+	require.NoError(t, tangle.DeleteConflictsForMilestone(conf.Index))
+	conflicts, err = tangle.GetConflictsForMilestone(conf.Index)
+	require.NoError(t, err)
+	require.Empty(t, conflicts)
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	_ "golang.org/x/crypto/blake2b"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/testsuite/utils"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+func TestSimulateWhiteFlagMutations(t *testing.T) {
+
+	// Fill up the balances
+	balances := make(map[string]uint64)
+	balances[string(utils.GenerateAddress(t, seed1, 0))] = 1000
+
+	te := testsuite.SetupTestEnvironment(t, balances, 3, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	// Valid transfer 100 from seed1[0] to seed2[0]
+	bundleA := te.AttachAndStoreBundle(te.Milestones[0].GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ValueTx(t, "A", seed1, 0, 1000, seed2, 0, 100))
+	// Invalid transfer 10 from seed3[0] to seed2[0] (insufficient funds)
+	bundleB := te.AttachAndStoreBundle(te.Milestones[2].GetBundle().GetTailHash(), bundleA.GetBundle().GetTailHash(), utils.ValueTx(t, "B", seed3, 0, 99999, seed2, 0, 10))
+	// Zero value transaction
+	bundleC := te.AttachAndStoreBundle(te.Milestones[2].GetBundle().GetTailHash(), te.Milestones[2].GetBundle().GetTailHash(), utils.ZeroValueTx(t, "C"))
+
+	tailA := bundleA.GetBundle().GetTailHash()
+	tailB := bundleB.GetBundle().GetTailHash()
+	tailC := bundleC.GetBundle().GetTailHash()
+
+	// no tips given
+	_, err := whiteflag.SimulateWhiteFlagMutations(tangle.GetMilestoneMerkleHashFunc(), nil, 10, 0, nil)
+	require.Equal(t, whiteflag.ErrNoTipsGiven, err)
+
+	// the amount of tips is limited
+	_, err = whiteflag.SimulateWhiteFlagMutations(tangle.GetMilestoneMerkleHashFunc(), hornet.Hashes{tailC, tailB}, 1, 0, nil)
+	require.True(t, errors.Is(err, whiteflag.ErrTooManyTipsGiven))
+
+	// the traversed cone is limited
+	_, err = whiteflag.SimulateWhiteFlagMutations(tangle.GetMilestoneMerkleHashFunc(), hornet.Hashes{tailC, tailB}, 10, 2, nil)
+	require.True(t, errors.Is(err, whiteflag.ErrTooManyTransactionsTraversed))
+
+	// the simulation is aborted by the abort signal
+	abortSignal := make(chan struct{})
+	close(abortSignal)
+	_, err = whiteflag.SimulateWhiteFlagMutations(tangle.GetMilestoneMerkleHashFunc(), hornet.Hashes{tailC, tailB}, 10, 0, abortSignal)
+	require.True(t, errors.Is(err, tangle.ErrOperationAborted))
+
+	// the candidate tip set is traversed in the given order
+	mutations, err := whiteflag.SimulateWhiteFlagMutations(tangle.GetMilestoneMerkleHashFunc(), hornet.Hashes{tailC, tailB}, 10, 0, nil)
+	require.NoError(t, err)
+	require.Equal(t, hornet.Hashes{tailA}, mutations.TailsIncluded)
+	require.Equal(t, hornet.Hashes{tailB}, mutations.TailsExcludedConflicting)
+	require.Equal(t, hornet.Hashes{tailC}, mutations.TailsExcludedZeroValue)
+	require.Equal(t, hornet.Hashes{tailC, tailA, tailB}, mutations.TailsReferenced)
+	require.Equal(t, int64(-1000), mutations.AddressMutations[string(utils.GenerateAddress(t, seed1, 0))])
+	require.Equal(t, int64(100), mutations.AddressMutations[string(utils.GenerateAddress(t, seed2, 0))])
+
+	// nothing was applied to the ledger
+	te.AssertAddressBalance(seed1, 0, 1000)
+	te.AssertAddressBalance(seed2, 0, 0)
+
+	// simulating the trunk and branch of the next milestone results in the same mutations as the confirmation
+	simulated, err := whiteflag.SimulateWhiteFlagMutations(tangle.GetMilestoneMerkleHashFunc(), hornet.Hashes{te.Milestones[len(te.Milestones)-1].GetBundle().GetTailHash(), tailB}, 10, 0, nil)
+	require.NoError(t, err)
+
+	conf := te.IssueAndConfirmMilestoneOnTip(tailB, false)
+	require.Equal(t, 4, conf.TxsValue)
+	require.Equal(t, 4, conf.TxsConflicting)
+
+	merkleTreeHash, err := te.Milestones[len(te.Milestones)-1].GetBundle().GetMilestoneMerkleTreeHash()
+	require.NoError(t, err)
+	require.Equal(t, merkleTreeHash, simulated.MerkleTreeHash)
+	require.Equal(t, hornet.Hashes{tailA}, simulated.TailsIncluded)
+	require.Equal(t, hornet.Hashes{tailB}, simulated.TailsExcludedConflicting)
+
+	te.AssertAddressBalance(seed1, 0, 0)
+	te.AssertAddressBalance(seed2, 0, 100)
+}
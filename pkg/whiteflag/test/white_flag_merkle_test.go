@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto"
 	"encoding/hex"
+	"errors"
 	"testing"
 
 	_ "golang.org/x/crypto/blake2b" // import implementation
@@ -36,3 +37,51 @@ func TestWhiteFlagMerkleTreeHash(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, bytes.Equal(hash, expectedHash))
 }
+
+func TestWhiteFlagMerkleTreeHashStream(t *testing.T) {
+
+	hasher := whiteflag.NewHasher(crypto.BLAKE2b_512)
+
+	var hashes []hornet.Hash
+	for i := 0; i < 70; i++ {
+		stream := hasher.NewTreeHashStream()
+		for _, hash := range hashes {
+			stream.Add(hash)
+		}
+
+		require.Equal(t, len(hashes), stream.Count())
+		require.True(t, bytes.Equal(hasher.TreeHash(hashes), stream.Sum()))
+
+		hashes = append(hashes, hornet.Hash{byte(i), byte(i >> 8)})
+	}
+}
+
+func TestWhiteFlagMerkleAuditPath(t *testing.T) {
+
+	hasher := whiteflag.NewHasher(crypto.BLAKE2b_512)
+
+	_, err := hasher.AuditPath(nil, 0)
+	require.True(t, errors.Is(err, whiteflag.ErrAuditPathIndexOutOfRange))
+
+	var hashes []hornet.Hash
+	for i := 0; i < 40; i++ {
+		hashes = append(hashes, hornet.Hash{byte(i), byte(i >> 8)})
+		root := hasher.TreeHash(hashes)
+
+		for index, hash := range hashes {
+			path, err := hasher.AuditPath(hashes, index)
+			require.NoError(t, err)
+			require.True(t, hasher.VerifyAuditPath(hash, index, len(hashes), path, root))
+
+			// the proof is only valid for the given hash and position
+			require.False(t, hasher.VerifyAuditPath(hornet.Hash{0xff, 0xff}, index, len(hashes), path, root))
+			if len(hashes) > 1 {
+				require.False(t, hasher.VerifyAuditPath(hash, (index+1)%len(hashes), len(hashes), path, root))
+				require.False(t, hasher.VerifyAuditPath(hash, index, len(hashes), path[1:], root))
+			}
+		}
+
+		_, err := hasher.AuditPath(hashes, len(hashes))
+		require.True(t, errors.Is(err, whiteflag.ErrAuditPathIndexOutOfRange))
+	}
+}
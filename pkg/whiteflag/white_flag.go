@@ -4,6 +4,7 @@ import (
 	"crypto"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/iotaledger/iota.go/consts"
 	"github.com/iotaledger/iota.go/math"
@@ -20,6 +21,9 @@ var (
 
 	// ErrIncludedTailsSumDoesntMatch is returned when the sum of the included tails a milestone approves does not match the referenced tails minus the excluded tails.
 	ErrIncludedTailsSumDoesntMatch = errors.New("the sum of the included tails doesn't match the referenced tails minus the excluded tails")
+
+	// ErrTooManyTransactionsTraversed is returned when the cone contains more transactions than allowed to be traversed.
+	ErrTooManyTransactionsTraversed = errors.New("too many transactions traversed")
 )
 
 // Confirmation represents a confirmation done via a milestone under the "white-flag" approach.
@@ -38,6 +42,8 @@ type WhiteFlagMutations struct {
 	TailsIncluded hornet.Hashes
 	// The tails of bundles which were excluded as they were conflicting with the mutations.
 	TailsExcludedConflicting hornet.Hashes
+	// The reasons why the tails in TailsExcludedConflicting were excluded (in the same order).
+	Conflicts []*tangle.Conflict
 	// The tails which were excluded because they were part of a zero or spam value transfer.
 	TailsExcludedZeroValue hornet.Hashes
 	// The tails which were referenced by the milestone (should be the sum of TailsIncluded + TailsExcludedConflicting + TailsExcludedZeroValue).
@@ -60,15 +66,26 @@ type WhiteFlagMutations struct {
 // The ledger state must be write locked while this function is getting called in order to ensure consistency.
 // all cachedTxMetas and cachedBundles have to be released outside.
 func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata, cachedBundles map[string]*tangle.CachedBundle, merkleTreeHashFunc crypto.Hash, trunkHash hornet.Hash, branchHash ...hornet.Hash) (*WhiteFlagMutations, error) {
+	return computeWhiteFlagMutations(cachedTxMetas, cachedBundles, merkleTreeHashFunc, 0, nil, trunkHash, branchHash...)
+}
+
+// computeWhiteFlagMutations computes the ledger changes like ComputeWhiteFlagMutations.
+// The traversal is aborted if the abortSignal is closed or if more than maxTraversedTxs transactions were traversed (0 means no limit).
+func computeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata, cachedBundles map[string]*tangle.CachedBundle, merkleTreeHashFunc crypto.Hash, maxTraversedTxs int, abortSignal <-chan struct{}, trunkHash hornet.Hash, branchHash ...hornet.Hash) (*WhiteFlagMutations, error) {
 	wfConf := &WhiteFlagMutations{
 		TailsIncluded:            make(hornet.Hashes, 0),
 		TailsExcludedConflicting: make(hornet.Hashes, 0),
+		Conflicts:                make([]*tangle.Conflict, 0),
 		TailsExcludedZeroValue:   make(hornet.Hashes, 0),
 		TailsReferenced:          make(hornet.Hashes, 0),
 		NewAddressState:          make(map[string]int64),
 		AddressMutations:         make(map[string]int64),
 	}
 
+	// the tail of the last included bundle which spent from an address,
+	// used to tell which bundle a conflicting bundle double spends against.
+	lastSpenders := make(map[string]hornet.Hash)
+
 	// traversal stops if no more transactions pass the given condition
 	// Caution: condition func is not in DFS order
 	condition := func(cachedTxMeta *tangle.CachedMetadata) (bool, error) { // meta +1
@@ -77,6 +94,10 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 		if _, exists := cachedTxMetas[string(cachedTxMeta.GetMetadata().GetTxHash())]; !exists {
 			// release the tx metadata at the end to speed up calculation
 			cachedTxMetas[string(cachedTxMeta.GetMetadata().GetTxHash())] = cachedTxMeta.Retain()
+
+			if maxTraversedTxs > 0 && len(cachedTxMetas) > maxTraversedTxs {
+				return false, fmt.Errorf("%w: more than %d transactions", ErrTooManyTransactionsTraversed, maxTraversedTxs)
+			}
 		}
 
 		if !cachedTxMeta.GetMetadata().IsTail() {
@@ -123,7 +144,7 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			return nil
 		}
 
-		var conflicting bool
+		var conflict *tangle.Conflict
 
 		// contains the updated mutations from this bundle against the
 		// current mutations of the milestone's confirming cone (or previous ledger state).
@@ -132,7 +153,15 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 		patchedState := make(map[string]int64)
 		validMutations := make(map[string]int64)
 
-		for addr, change := range mutations {
+		// the addresses are sorted, so the same conflicting address is reported on every node
+		addresses := make([]string, 0, len(mutations))
+		for addr := range mutations {
+			addresses = append(addresses, addr)
+		}
+		sort.Strings(addresses)
+
+		for _, addr := range addresses {
+			change := mutations[addr]
 
 			// load state from milestone cone mutation or previous milestone
 			balance, has := wfConf.NewAddressState[addr]
@@ -151,7 +180,11 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 
 			// on below zero or above total supply the mutation is invalid
 			if newBalance < 0 || math.AbsInt64(newBalance) > consts.TotalSupply {
-				conflicting = true
+				var err error
+				conflict, err = conflictReason(cachedTxMeta.GetMetadata().GetTxHash(), addr, change, newBalance, lastSpenders)
+				if err != nil {
+					return err
+				}
 				break
 			}
 
@@ -161,14 +194,21 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 
 		wfConf.TailsReferenced = append(wfConf.TailsReferenced, cachedTxMeta.GetMetadata().GetTxHash())
 
-		if conflicting {
+		if conflict != nil {
 			wfConf.TailsExcludedConflicting = append(wfConf.TailsExcludedConflicting, cachedTxMeta.GetMetadata().GetTxHash())
+			wfConf.Conflicts = append(wfConf.Conflicts, conflict)
 			return nil
 		}
 
 		// mark the given tail to be part of milestone ledger changing tail inclusion set
 		wfConf.TailsIncluded = append(wfConf.TailsIncluded, cachedTxMeta.GetMetadata().GetTxHash())
 
+		for addr, change := range validMutations {
+			if change < 0 {
+				lastSpenders[addr] = cachedTxMeta.GetMetadata().GetTxHash()
+			}
+		}
+
 		// incorporate the mutations in accordance with the previous mutations
 		// in the milestone's confirming cone/previous ledger state.
 		for addr, balance := range patchedState {
@@ -198,7 +238,21 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			// called on solid entry points
 			// Ignore solid entry points (snapshot milestone included)
 			nil,
-			false, true, nil); err != nil {
+			false, true, abortSignal); err != nil {
+			return nil, err
+		}
+	} else if len(branchHash) > 1 {
+		// several tips given, walk them in the given order
+		if err := dag.TraverseApproveesMultiple(append(hornet.Hashes{trunkHash}, branchHash...),
+			condition,
+			consumer,
+			// called on missing approvees
+			// return error on missing approvees
+			nil,
+			// called on solid entry points
+			// Ignore solid entry points (snapshot milestone included)
+			nil,
+			false, true, abortSignal); err != nil {
 			return nil, err
 		}
 	} else {
@@ -212,7 +266,7 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 			// called on solid entry points
 			// Ignore solid entry points (snapshot milestone included)
 			nil,
-			false, true, nil); err != nil {
+			false, true, abortSignal); err != nil {
 			return nil, err
 		}
 	}
@@ -226,3 +280,33 @@ func ComputeWhiteFlagMutations(cachedTxMetas map[string]*tangle.CachedMetadata,
 
 	return wfConf, nil
 }
+
+// conflictReason determines why the mutation of the given address by the bundle of the given tail is conflicting.
+// The ledger state must be locked while this function is getting called.
+func conflictReason(tailHash hornet.Hash, addr string, change int64, newBalance int64, lastSpenders map[string]hornet.Hash) (*tangle.Conflict, error) {
+
+	conflict := &tangle.Conflict{
+		TailHash: tailHash,
+		Address:  hornet.Hash(addr),
+	}
+
+	if newBalance >= 0 {
+		conflict.Reason = tangle.ConflictExceedsTotalSupply
+		return conflict, nil
+	}
+
+	// the bundle double spends if the balance of the previous milestone would have been sufficient
+	balanceStateFromPreviousMilestone, _, err := tangle.GetBalanceForAddressWithoutLocking(hornet.Hash(addr))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to retrieve balance of address %s", err, addr)
+	}
+
+	if lastSpender, spent := lastSpenders[addr]; spent && int64(balanceStateFromPreviousMilestone)+change >= 0 {
+		conflict.Reason = tangle.ConflictDoubleSpend
+		conflict.ConflictingTailHash = lastSpender
+		return conflict, nil
+	}
+
+	conflict.Reason = tangle.ConflictInsufficientBalance
+	return conflict, nil
+}
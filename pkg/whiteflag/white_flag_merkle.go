@@ -1,12 +1,19 @@
 package whiteflag
 
 import (
+	"bytes"
 	"crypto"
+	"errors"
 	"math/bits"
 
 	"github.com/gohornet/hornet/pkg/model/hornet"
 )
 
+var (
+	// ErrAuditPathIndexOutOfRange is returned when the audit path for a hash outside of the Merkle tree is requested.
+	ErrAuditPathIndexOutOfRange = errors.New("index of the audit path is out of range")
+)
+
 // Domain separation prefixes
 const (
 	LeafHashPrefix = 0
@@ -58,6 +65,119 @@ func (t *Hasher) HashNode(l, r []byte) []byte {
 	return h.Sum(nil)
 }
 
+// AuditPath computes the audit path of the hash at the given index in the provided hashes (RFC6962 section 2.1.1).
+// The audit path contains the sibling nodes from the leaf up to the root and proves the inclusion of the hash in the Merkle tree.
+func (t *Hasher) AuditPath(tailHashes []hornet.Hash, index int) ([][]byte, error) {
+	if index < 0 || index >= len(tailHashes) {
+		return nil, ErrAuditPathIndexOutOfRange
+	}
+	return t.auditPath(tailHashes, index), nil
+}
+
+func (t *Hasher) auditPath(tailHashes []hornet.Hash, index int) [][]byte {
+	if len(tailHashes) == 1 {
+		return [][]byte{}
+	}
+
+	k := largestPowerOfTwo(len(tailHashes))
+	if index < k {
+		return append(t.auditPath(tailHashes[:k], index), t.TreeHash(tailHashes[k:]))
+	}
+	return append(t.auditPath(tailHashes[k:], index-k), t.TreeHash(tailHashes[:k]))
+}
+
+// VerifyAuditPath checks whether the given audit path proves the inclusion of the hash
+// at the given index in a Merkle tree with the given amount of leaves and the given root (RFC9162 section 2.1.3.2).
+func (t *Hasher) VerifyAuditPath(hash hornet.Hash, index int, count int, path [][]byte, root []byte) bool {
+	if index < 0 || index >= count {
+		return false
+	}
+
+	fn := index
+	sn := count - 1
+	node := t.HashLeaf(hash)
+
+	for _, sibling := range path {
+		if sn == 0 {
+			return false
+		}
+
+		if fn&1 == 1 || fn == sn {
+			node = t.HashNode(sibling, node)
+			// skip the levels on which the node has no right sibling
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			node = t.HashNode(node, sibling)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return sn == 0 && bytes.Equal(node, root)
+}
+
+// TreeHashStream computes the Merkle tree hash of a sequence of hashes without keeping all of them in memory.
+// The result is identical to TreeHash called with the same hashes in the same order.
+type TreeHashStream struct {
+	hasher *Hasher
+	count  int
+	// roots of the perfect subtrees, the subtree at position i contains 2^i leaves
+	subtrees [][]byte
+}
+
+// NewTreeHashStream creates a new TreeHashStream on the passed in hash function.
+func (t *Hasher) NewTreeHashStream() *TreeHashStream {
+	return &TreeHashStream{hasher: t}
+}
+
+// Add adds the next hash to the tree.
+func (s *TreeHashStream) Add(hash hornet.Hash) {
+	node := s.hasher.HashLeaf(hash)
+
+	// merge the perfect subtrees of equal size, like a binary counter
+	level := 0
+	for ; s.count&(1<<uint(level)) != 0; level++ {
+		node = s.hasher.HashNode(s.subtrees[level], node)
+		s.subtrees[level] = nil
+	}
+
+	if level == len(s.subtrees) {
+		s.subtrees = append(s.subtrees, nil)
+	}
+	s.subtrees[level] = node
+	s.count++
+}
+
+// Count returns the amount of hashes added to the tree.
+func (s *TreeHashStream) Count() int {
+	return s.count
+}
+
+// Sum returns the Merkle tree hash of all hashes added so far.
+func (s *TreeHashStream) Sum() []byte {
+	if s.count == 0 {
+		return s.hasher.EmptyRoot()
+	}
+
+	// the remaining perfect subtrees are combined from the smallest to the largest one
+	var root []byte
+	for _, subtree := range s.subtrees {
+		if subtree == nil {
+			continue
+		}
+		if root == nil {
+			root = subtree
+			continue
+		}
+		root = s.hasher.HashNode(subtree, root)
+	}
+	return root
+}
+
 // largestPowerOfTwo returns the largest power of two less than n.
 func largestPowerOfTwo(x int) int {
 	if x < 2 {
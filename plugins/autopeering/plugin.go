@@ -107,7 +107,7 @@ func configureEvents() {
 			return
 		}
 
-		if err := peering.Manager().Add(gossipAddr, false, "", ev.Peer); err != nil {
+		if err := peering.Manager().Add(gossipAddr, false, "", "", ev.Peer); err != nil {
 			log.Warnf("couldn't add autopeering peer %s", err)
 		}
 	})
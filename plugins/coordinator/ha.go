@@ -0,0 +1,185 @@
+package coordinator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/timeutil"
+	"go.uber.org/atomic"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/coordinator"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	tangleplugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+const (
+	// the amount of lease durations after which an instance holding the lease, but missing the last signed milestone,
+	// asks the operator to clear the record of the last signed milestone.
+	signedMilestoneGracePeriod = 4
+)
+
+var (
+	// the lease which decides which coordinator instance is active, nil if the active/standby mode is disabled.
+	lease         coordinator.Lease
+	instanceID    string
+	leaseDuration time.Duration
+
+	// whether this instance holds the lease and issues milestones.
+	isActive atomic.Bool
+	// signals that the lease was lost and the instance has to switch to standby.
+	leaseLostSignal = make(chan struct{}, 1)
+	// passes the milestones issued by the active instance to the standby instance.
+	replicationSignal = make(chan milestone.Index, 100)
+	// the time since which the instance holds the lease, but waits for the last milestone signed by the previous holder.
+	waitingForSignedMilestoneSince time.Time
+
+	onLatestMilestoneChanged *events.Closure
+)
+
+// highAvailabilityEnabled tells whether the coordinator runs in active/standby mode.
+func highAvailabilityEnabled() bool {
+	return config.NodeConfig.GetBool(config.CfgCoordinatorHAEnabled)
+}
+
+// configures the lease of the active/standby mode.
+func configureHighAvailability() {
+	instanceID = config.NodeConfig.GetString(config.CfgCoordinatorHAInstanceID)
+	if instanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Panic(err)
+		}
+		instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	leaseDuration = time.Duration(config.NodeConfig.GetInt(config.CfgCoordinatorHALeaseDurationSeconds)) * time.Second
+	lease = coordinator.NewFileLease(config.NodeConfig.GetString(config.CfgCoordinatorHALeaseFilePath), leaseDuration)
+	coo.SetLease(lease, instanceID)
+
+	onLatestMilestoneChanged = events.NewClosure(func(cachedBndl *tangle.CachedBundle) {
+		index := cachedBndl.GetBundle().GetMilestoneIndex()
+		cachedBndl.Release(true) // bundle -1
+
+		if isActive.Load() {
+			return
+		}
+
+		select {
+		case replicationSignal <- index:
+		default:
+			// the standby instance catches up with the next milestone
+		}
+	})
+
+	log.Infof("running in active/standby mode as instance '%s'", instanceID)
+}
+
+// waitForLease blocks until this instance holds the lease and caught up with the milestones signed by the previous
+// holder of the lease. In the meantime the milestones of the active instance are replicated to the state.
+// Returns false if the node was shut down.
+func waitForLease(shutdownSignal <-chan struct{}) bool {
+	tangleplugin.Events.LatestMilestoneChanged.Attach(onLatestMilestoneChanged)
+	defer tangleplugin.Events.LatestMilestoneChanged.Detach(onLatestMilestoneChanged)
+
+	ticker := time.NewTicker(leaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		if tryBecomeActive() {
+			return true
+		}
+
+		select {
+		case <-shutdownSignal:
+			return false
+
+		case index := <-replicationSignal:
+			if err := coo.ApplyMilestone(index); err != nil {
+				log.Warnf("replicating milestone %d failed: %s", index, err)
+			}
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// tries to acquire the lease. the instance only becomes active if it knows all milestones signed by previous holders,
+// otherwise the same milestone index could be signed twice.
+func tryBecomeActive() bool {
+	record, err := lease.Acquire(instanceID)
+	if err != nil {
+		if !errors.Is(err, coordinator.ErrLeaseHeld) {
+			log.Warnf("acquiring the lease failed: %s", err)
+		}
+		return false
+	}
+
+	if latestIndex := coo.State().LatestMilestoneIndex; latestIndex < record.LastSignedIndex {
+		if waitingForSignedMilestoneSince.IsZero() {
+			waitingForSignedMilestoneSince = time.Now()
+		}
+
+		// the previous instance may have failed to issue the milestone after recording it, which only the operator can tell
+		if time.Since(waitingForSignedMilestoneSince) > signedMilestoneGracePeriod*leaseDuration {
+			log.Errorf("holding the lease, but milestone %d signed by the previous instance is still missing (latest known milestone: %d). "+
+				"if the milestone was never issued, clear the record with 'hornet tool leaseclear %d'", record.LastSignedIndex, latestIndex, record.LastSignedIndex)
+			return false
+		}
+
+		log.Warnf("holding the lease, but waiting for milestone %d signed by the previous instance (latest known milestone: %d)", record.LastSignedIndex, latestIndex)
+		return false
+	}
+	waitingForSignedMilestoneSince = time.Time{}
+
+	// drop a signal of a previously lost lease
+	select {
+	case <-leaseLostSignal:
+	default:
+	}
+
+	isActive.Store(true)
+	log.Infof("became the active coordinator instance (term %d)", record.Term)
+	return true
+}
+
+// switches the instance to standby.
+func stepDown() {
+	if !isActive.CAS(true, false) {
+		return
+	}
+
+	select {
+	case leaseLostSignal <- struct{}{}:
+	default:
+	}
+}
+
+// renews the lease while this instance is active.
+func runLeaseRenewal() {
+	daemon.BackgroundWorker("Coordinator[Lease]", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(func() {
+			if !isActive.Load() {
+				return
+			}
+
+			if _, err := lease.Acquire(instanceID); err != nil {
+				log.Warnf("renewing the lease failed, switching to standby: %s", err)
+				stepDown()
+			}
+		}, leaseDuration/3, shutdownSignal)
+
+		// hand over to a standby instance without waiting for the lease to expire
+		if isActive.Load() {
+			if err := lease.Release(instanceID); err != nil {
+				log.Warnf("releasing the lease failed: %s", err)
+			}
+		}
+	}, shutdown.PriorityCoordinator)
+}
@@ -0,0 +1,61 @@
+package coordinator
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/coordinator"
+)
+
+var (
+	// the percentages of remaining Merkle tree keys at which a warning is logged.
+	merkleKeyWarningThresholds []float64
+	// the lowest threshold a warning was logged for, so every threshold is only warned about once.
+	lowestWarnedThreshold = math.Inf(1)
+	// guards lowestWarnedThreshold.
+	lowestWarnedThresholdLock sync.Mutex
+)
+
+// configures the warnings about the remaining keys of the Merkle tree.
+func configureMerkleKeyWarnings() {
+	for _, threshold := range config.NodeConfig.GetIntSlice(config.CfgCoordinatorMerkleKeyWarningThresholds) {
+		merkleKeyWarningThresholds = append(merkleKeyWarningThresholds, float64(threshold))
+	}
+
+	usage := coo.MerkleKeyUsage()
+	log.Infof("Merkle tree keys: used %d/%d, %d remaining (%0.2f%%)", usage.Used, usage.Capacity, usage.Remaining, usage.RemainingPercentage())
+
+	checkMerkleKeyUsage()
+}
+
+// logs a warning if the remaining keys of the Merkle tree dropped below a new threshold.
+func checkMerkleKeyUsage() {
+	usage := coo.MerkleKeyUsage()
+
+	threshold, reached := usage.LowestReachedThreshold(merkleKeyWarningThresholds)
+	if !reached {
+		return
+	}
+
+	lowestWarnedThresholdLock.Lock()
+	defer lowestWarnedThresholdLock.Unlock()
+
+	if threshold >= lowestWarnedThreshold {
+		return
+	}
+	lowestWarnedThreshold = threshold
+
+	log.Warnf("only %d of %d Merkle tree keys remaining (%0.2f%%), the keys are used up in about %v. Plan the rotation to a new Merkle tree with 'tool merkleplan'",
+		usage.Remaining, usage.Capacity, usage.RemainingPercentage(), usage.TimeLeft(coo.GetInterval()).Truncate(time.Minute))
+}
+
+// MerkleKeyUsage returns how many keys of the Merkle tree of the coordinator were used and how many remain.
+// Returns nil if the coordinator is not running.
+func MerkleKeyUsage() *coordinator.MerkleKeyUsage {
+	if coo == nil {
+		return nil
+	}
+	return coo.MerkleKeyUsage()
+}
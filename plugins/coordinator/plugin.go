@@ -12,11 +12,9 @@ import (
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
 	"github.com/iotaledger/hive.go/syncutils"
-	"github.com/iotaledger/hive.go/timeutil"
 	"github.com/iotaledger/iota.go/bundle"
 	"github.com/iotaledger/iota.go/consts"
 	"github.com/iotaledger/iota.go/transaction"
-	"github.com/iotaledger/iota.go/trinary"
 
 	"github.com/gohornet/hornet/pkg/config"
 	"github.com/gohornet/hornet/pkg/dag"
@@ -36,14 +34,16 @@ import (
 func init() {
 	flag.CommandLine.MarkHidden("cooBootstrap")
 	flag.CommandLine.MarkHidden("cooStartIndex")
+	flag.CommandLine.MarkHidden("cooRecoverState")
 }
 
 var (
 	PLUGIN = node.NewPlugin("Coordinator", node.Disabled, configure, run)
 	log    *logger.Logger
 
-	bootstrap  = flag.Bool("cooBootstrap", false, "bootstrap the network")
-	startIndex = flag.Uint32("cooStartIndex", 0, "index of the first milestone at bootstrap")
+	bootstrap    = flag.Bool("cooBootstrap", false, "bootstrap the network")
+	startIndex   = flag.Uint32("cooStartIndex", 0, "index of the first milestone at bootstrap")
+	recoverState = flag.Bool("cooRecoverState", false, "recover the state file from the latest milestone of the coordinator in the database")
 
 	maxTrackedTails int
 	belowMaxDepth   milestone.Index
@@ -52,7 +52,7 @@ var (
 	nextMilestoneSignal  chan struct{}
 
 	coo      *coordinator.Coordinator
-	selector *mselection.HeaviestSelector
+	selector mselection.MilestoneSelector
 
 	lastCheckpointIndex int
 	lastCheckpointHash  hornet.Hash
@@ -64,8 +64,9 @@ var (
 	onIssuedCheckpointTransaction *events.Closure
 	onIssuedMilestone             *events.Closure
 
-	ErrDatabaseTainted = errors.New("database is tainted. delete the coordinator database and start again with a local snapshot")
-	ErrTailTxNotFound  = errors.New("tail transaction not found in bundle")
+	ErrDatabaseTainted    = errors.New("database is tainted. delete the coordinator database and start again with a local snapshot")
+	ErrTailTxNotFound     = errors.New("tail transaction not found in bundle")
+	ErrRecoverAtBootstrap = errors.New("the state can't be recovered while bootstrapping the network")
 )
 
 func configure(plugin *node.Plugin) {
@@ -75,42 +76,48 @@ func configure(plugin *node.Plugin) {
 	tangleplugin.SetUpdateSyncedAtStartup(true)
 
 	var err error
-	coo, err = initCoordinator(*bootstrap, *startIndex, pow.Handler())
+	coo, err = initCoordinator(*bootstrap, *startIndex, *recoverState, pow.Handler())
 	if err != nil {
 		log.Panic(err)
 	}
 
+	if highAvailabilityEnabled() {
+		configureHighAvailability()
+	}
+
+	configureMerkleKeyWarnings()
+	configureScheduling()
 	configureEvents()
 }
 
-func initCoordinator(bootstrap bool, startIndex uint32, powHandler *powpackage.Handler) (*coordinator.Coordinator, error) {
+func initCoordinator(bootstrap bool, startIndex uint32, recoverState bool, powHandler *powpackage.Handler) (*coordinator.Coordinator, error) {
 
 	if tangle.IsDatabaseTainted() {
 		return nil, ErrDatabaseTainted
 	}
 
-	seed, err := config.LoadHashFromEnvironment("COO_SEED", consts.HashTrytesSize)
-	if err != nil {
-		return nil, err
+	if bootstrap && recoverState {
+		return nil, ErrRecoverAtBootstrap
 	}
 
-	// the last trit of the seed will be ignored, so it is important security information when that happens
-	lastTrits := trinary.MustTrytesToTrits(string(seed[consts.HashTrytesSize-1]))
-	if lastTrits[consts.TritsPerTryte-1] != 0 {
-		// print warning and set the 243rd trit to zero for consistency and to prevent warnings during key derivation
-		log.Warn("The trit at index 243 of the coordinator seed is non-zero. " +
-			"The value of this trit will be ignored by the key derivation.")
-		lastTrits[consts.TritsPerTryte-1] = 0
-		seed = seed[:consts.HashTrytesSize-1] + trinary.MustTritsToTrytes(lastTrits)
+	securityLvl := consts.SecurityLevel(config.NodeConfig.GetInt(config.CfgCoordinatorSecurityLevel))
+
+	milestoneSigner, err := initSigner(securityLvl)
+	if err != nil {
+		return nil, err
 	}
 
-	// use the heaviest branch tip selection for the milestones
-	selector = mselection.New(
+	// use the heaviest branch tip selection for the milestones, the branches are rated by the configured strategy
+	selector, err = mselection.NewSelector(
+		config.NodeConfig.GetString(config.CfgCoordinatorTipselectStrategy),
 		config.NodeConfig.GetInt(config.CfgCoordinatorTipselectMinHeaviestBranchUnconfirmedTransactionsThreshold),
 		config.NodeConfig.GetInt(config.CfgCoordinatorTipselectMaxHeaviestBranchTipsPerCheckpoint),
 		config.NodeConfig.GetInt(config.CfgCoordinatorTipselectRandomTipsPerCheckpoint),
 		time.Duration(config.NodeConfig.GetInt(config.CfgCoordinatorTipselectHeaviestBranchSelectionDeadlineMilliseconds))*time.Millisecond,
 	)
+	if err != nil {
+		return nil, err
+	}
 
 	nextCheckpointSignal = make(chan struct{})
 
@@ -123,8 +130,8 @@ func initCoordinator(bootstrap bool, startIndex uint32, powHandler *powpackage.H
 	belowMaxDepth = milestone.Index(config.NodeConfig.GetInt(config.CfgTipSelBelowMaxDepth))
 
 	coo := coordinator.New(
-		seed,
-		consts.SecurityLevel(config.NodeConfig.GetInt(config.CfgCoordinatorSecurityLevel)),
+		milestoneSigner,
+		securityLvl,
 		config.NodeConfig.GetInt(config.CfgCoordinatorMerkleTreeDepth),
 		config.NodeConfig.GetInt(config.CfgCoordinatorMWM),
 		config.NodeConfig.GetString(config.CfgCoordinatorStateFilePath),
@@ -138,7 +145,26 @@ func initCoordinator(bootstrap bool, startIndex uint32, powHandler *powpackage.H
 		return nil, err
 	}
 
-	if err := coo.InitState(bootstrap, milestone.Index(startIndex)); err != nil {
+	if recoverState {
+		// the state file was lost or corrupted, so it is recovered from the latest milestone of the coordinator in the database
+		if err := coo.RecoverState(config.NodeConfig.GetString(config.CfgCoordinatorAddress)); err != nil {
+			return nil, err
+		}
+		log.Infof("recovered the state from milestone %d", coo.State().LatestMilestoneIndex)
+	} else if highAvailabilityEnabled() && !bootstrap {
+		// standby instances follow the milestones of the active instance, so the state is created from the database
+		if err := coo.InitStateFromDatabase(); err != nil {
+			return nil, err
+		}
+	} else if err := coo.InitState(bootstrap, milestone.Index(startIndex)); err != nil {
+		return nil, err
+	}
+
+	if err := coo.CheckMerkleKeys(); err != nil {
+		return nil, err
+	}
+
+	if err := coo.CheckSigner(); err != nil {
 		return nil, err
 	}
 
@@ -147,19 +173,12 @@ func initCoordinator(bootstrap bool, startIndex uint32, powHandler *powpackage.H
 
 func run(plugin *node.Plugin) {
 
-	// create a background worker that signals to issue new milestones
-	daemon.BackgroundWorker("Coordinator[MilestoneTicker]", func(shutdownSignal <-chan struct{}) {
-
-		timeutil.Ticker(func() {
-			// issue next milestone
-			select {
-			case nextMilestoneSignal <- struct{}{}:
-			default:
-				// do not block if already another signal is waiting
-			}
-		}, coo.GetInterval(), shutdownSignal)
+	// create a background worker that signals to issue new milestones, depending on the scheduling mode
+	runMilestoneScheduler()
 
-	}, shutdown.PriorityCoordinator)
+	if lease != nil {
+		runLeaseRenewal()
+	}
 
 	// create a background worker that issues milestones
 	daemon.BackgroundWorker("Coordinator", func(shutdownSignal <-chan struct{}) {
@@ -168,95 +187,142 @@ func run(plugin *node.Plugin) {
 
 		attachEvents()
 
-		// bootstrap the network if not done yet
-		milestoneHash, criticalErr := coo.Bootstrap()
-		if criticalErr != nil {
-			log.Panic(criticalErr)
+		for {
+			// in active/standby mode, only the instance holding the lease issues milestones
+			if lease != nil && !waitForLease(shutdownSignal) {
+				break
+			}
+
+			if !issueMilestones(shutdownSignal) {
+				break
+			}
+
+			log.Warn("lost the lease, switching to standby")
 		}
 
-		// init the last milestone hash
-		lastMilestoneHash = milestoneHash
+		detachEvents()
+	}, shutdown.PriorityCoordinator)
 
-		// init the checkpoints
-		lastCheckpointHash = milestoneHash
-		lastCheckpointIndex = 0
+}
 
-	coordinatorLoop:
-		for {
-			select {
-			case <-nextCheckpointSignal:
-				// check the thresholds again, because a new milestone could have been issued in the meantime
-				if trackedTailsCount := selector.GetTrackedTailsCount(); trackedTailsCount < maxTrackedTails {
-					continue
-				}
+// issueMilestones issues checkpoints and milestones until the node is shut down or the lease was lost.
+// Returns false if the node was shut down.
+func issueMilestones(shutdownSignal <-chan struct{}) bool {
 
-				tips, err := selector.SelectTips(0)
-				if err != nil {
-					// issuing checkpoint failed => not critical
-					if err != mselection.ErrNoTipsAvailable {
-						log.Warn(err)
-					}
-					continue
-				}
+	// bootstrap the network if not done yet
+	milestoneHash, criticalErr := coo.Bootstrap()
+	if criticalErr != nil {
+		log.Panic(criticalErr)
+	}
+
+	// init the last milestone hash
+	lastMilestoneHash = milestoneHash
+
+	// init the checkpoints
+	lastCheckpointHash = milestoneHash
+	lastCheckpointIndex = 0
 
-				// issue a checkpoint
-				checkpointHash, err := coo.IssueCheckpoint(lastCheckpointIndex, lastCheckpointHash, tips)
-				if err != nil {
-					// issuing checkpoint failed => not critical
+	for {
+		select {
+		case <-nextCheckpointSignal:
+			// check the thresholds again, because a new milestone could have been issued in the meantime
+			if trackedTailsCount := selector.GetTrackedTailsCount(); trackedTailsCount < maxTrackedTails {
+				continue
+			}
+
+			tips, err := selector.SelectTips(0)
+			if err != nil {
+				// issuing checkpoint failed => not critical
+				if err != mselection.ErrNoTipsAvailable {
 					log.Warn(err)
-					continue
-				}
-				lastCheckpointIndex++
-				lastCheckpointHash = checkpointHash
-
-			case <-nextMilestoneSignal:
-
-				// issue a new checkpoint right in front of the milestone
-				tips, err := selector.SelectTips(1)
-				if err != nil {
-					// issuing checkpoint failed => not critical
-					if err != mselection.ErrNoTipsAvailable {
-						log.Warn(err)
-					}
-				} else {
-					checkpointHash, err := coo.IssueCheckpoint(lastCheckpointIndex, lastCheckpointHash, tips)
-					if err != nil {
-						// issuing checkpoint failed => not critical
-						log.Warn(err)
-					} else {
-						// use the new checkpoint hash
-						lastCheckpointHash = checkpointHash
-					}
 				}
+				continue
+			}
 
-				milestoneHash, err, criticalErr := coo.IssueMilestone(lastMilestoneHash, lastCheckpointHash)
-				if criticalErr != nil {
-					log.Panic(criticalErr)
+			// issue a checkpoint
+			checkpointHash, err := coo.IssueCheckpoint(lastCheckpointIndex, lastCheckpointHash, tips)
+			if err != nil {
+				// issuing checkpoint failed => not critical
+				log.Warn(err)
+				continue
+			}
+			lastCheckpointIndex++
+			lastCheckpointHash = checkpointHash
+
+		case <-nextMilestoneSignal:
+			if _, err := issueNextMilestone(); err != nil {
+				log.Warn(err)
+				if coordinator.IsLeaseError(err) {
+					stepDown()
+					return true
 				}
-				if err != nil {
-					if err == tangle.ErrNodeNotSynced {
-						// Coordinator is not synchronized, trigger the solidifier manually
-						tangleplugin.TriggerSolidifier()
-					}
-					log.Warn(err)
-					continue
+			}
+
+		case resultChan := <-milestoneRequests:
+			milestoneHash, err := issueNextMilestone()
+			if err != nil {
+				resultChan <- &milestoneResult{err: err}
+
+				log.Warn(err)
+				if coordinator.IsLeaseError(err) {
+					stepDown()
+					return true
 				}
+				continue
+			}
+			resultChan <- &milestoneResult{index: coo.State().LatestMilestoneIndex, hash: milestoneHash}
 
-				// remember the last milestone hash
-				lastMilestoneHash = milestoneHash
+		case <-leaseLostSignal:
+			return true
 
-				// reset the checkpoints
-				lastCheckpointHash = milestoneHash
-				lastCheckpointIndex = 0
+		case <-shutdownSignal:
+			return false
+		}
+	}
+}
 
-			case <-shutdownSignal:
-				break coordinatorLoop
-			}
+// issueNextMilestone issues a checkpoint right in front of the next milestone and the milestone itself.
+// Returns the hash of the milestone.
+func issueNextMilestone() (hornet.Hash, error) {
+
+	// issue a new checkpoint right in front of the milestone
+	tips, err := selector.SelectTips(1)
+	if err != nil {
+		// issuing checkpoint failed => not critical
+		if err != mselection.ErrNoTipsAvailable {
+			log.Warn(err)
 		}
+	} else {
+		checkpointHash, err := coo.IssueCheckpoint(lastCheckpointIndex, lastCheckpointHash, tips)
+		if err != nil {
+			// issuing checkpoint failed => not critical
+			log.Warn(err)
+		} else {
+			// use the new checkpoint hash
+			lastCheckpointHash = checkpointHash
+		}
+	}
 
-		detachEvents()
-	}, shutdown.PriorityCoordinator)
+	milestoneHash, err, criticalErr := coo.IssueMilestone(lastMilestoneHash, lastCheckpointHash)
+	if criticalErr != nil {
+		log.Panic(criticalErr)
+	}
+	if err != nil {
+		if err == tangle.ErrNodeNotSynced {
+			// Coordinator is not synchronized, trigger the solidifier manually
+			tangleplugin.TriggerSolidifier()
+		}
+		return nil, err
+	}
+
+	// remember the last milestone hash
+	lastMilestoneHash = milestoneHash
+
+	// reset the checkpoints
+	lastCheckpointHash = milestoneHash
+	lastCheckpointIndex = 0
 
+	return milestoneHash, nil
 }
 
 func sendBundle(b bundle.Bundle, isMilestone bool) error {
@@ -392,6 +458,7 @@ func configureEvents() {
 
 	onIssuedMilestone = events.NewClosure(func(index milestone.Index, tailTxHash hornet.Hash) {
 		log.Infof("milestone issued (%d): %v", index, tailTxHash.Trytes())
+		checkMerkleKeyUsage()
 	})
 }
 
@@ -0,0 +1,170 @@
+package coordinator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/coordinator"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+const (
+	// the interval in which the adaptive scheduling checks whether the next milestone is due.
+	adaptiveSchedulingCheckInterval = 500 * time.Millisecond
+)
+
+var (
+	// ErrCoordinatorNotRunning is returned if the coordinator plugin is disabled.
+	ErrCoordinatorNotRunning = errors.New("coordinator is not running")
+	// ErrNotOnDemand is returned if milestones are requested, but the coordinator doesn't run in the "onDemand" scheduling mode.
+	ErrNotOnDemand = errors.New("coordinator doesn't issue milestones on demand")
+	// ErrCoordinatorStandby is returned if milestones are requested from a standby instance.
+	ErrCoordinatorStandby = errors.New("coordinator instance is in standby")
+	// ErrMilestoneRequestAborted is returned if a milestone request was aborted before the milestone was issued.
+	ErrMilestoneRequestAborted = errors.New("milestone request aborted")
+	// ErrCoordinatorBusy is returned if the coordinator doesn't accept a milestone request in time,
+	// e.g. because it is still bootstrapping, waiting for the node to sync or issuing another milestone.
+	ErrCoordinatorBusy = errors.New("coordinator is not ready to issue a milestone")
+	// ErrMilestoneRequestTimeout is returned if an accepted milestone request was not answered in time.
+	// The milestone may still be issued.
+	ErrMilestoneRequestTimeout = errors.New("milestone request timed out")
+
+	// the time the coordinator has to accept a milestone request.
+	milestoneRequestAcceptTimeout = 5 * time.Second
+	// the time the coordinator has to issue the milestone of an accepted request.
+	milestoneRequestTimeout = 2 * time.Minute
+
+	schedulingMode   coordinator.SchedulingMode
+	adaptiveInterval *coordinator.AdaptiveInterval
+
+	// passes the milestone requests of the "onDemand" scheduling mode to the coordinator.
+	milestoneRequests chan chan *milestoneResult
+)
+
+// the result of a milestone request.
+type milestoneResult struct {
+	index milestone.Index
+	hash  hornet.Hash
+	err   error
+}
+
+// configures when milestones are issued.
+func configureScheduling() {
+	var err error
+	schedulingMode, err = coordinator.SchedulingModeWithName(config.NodeConfig.GetString(config.CfgCoordinatorSchedulingMode))
+	if err != nil {
+		log.Panic(err)
+	}
+
+	milestoneRequests = make(chan chan *milestoneResult)
+
+	switch schedulingMode {
+	case coordinator.SchedulingModeFixed:
+		log.Infof("issuing milestones every %v", coo.GetInterval())
+
+	case coordinator.SchedulingModeAdaptive:
+		minInterval := time.Duration(config.NodeConfig.GetInt(config.CfgCoordinatorSchedulingAdaptiveMinIntervalSeconds)) * time.Second
+		maxInterval := time.Duration(config.NodeConfig.GetInt(config.CfgCoordinatorSchedulingAdaptiveMaxIntervalSeconds)) * time.Second
+
+		adaptiveInterval, err = coordinator.NewAdaptiveInterval(minInterval, maxInterval, config.NodeConfig.GetInt(config.CfgCoordinatorSchedulingAdaptiveHighLoadTransactions))
+		if err != nil {
+			log.Panic(err)
+		}
+		log.Infof("issuing milestones every %v to %v depending on the load", minInterval, maxInterval)
+
+	case coordinator.SchedulingModeOnDemand:
+		log.Info("issuing milestones on demand")
+	}
+}
+
+// runs the background worker that signals to issue new milestones.
+func runMilestoneScheduler() {
+
+	switch schedulingMode {
+	case coordinator.SchedulingModeFixed:
+		daemon.BackgroundWorker("Coordinator[MilestoneTicker]", func(shutdownSignal <-chan struct{}) {
+			timeutil.Ticker(signalNextMilestone, coo.GetInterval(), shutdownSignal)
+		}, shutdown.PriorityCoordinator)
+
+	case coordinator.SchedulingModeAdaptive:
+		daemon.BackgroundWorker("Coordinator[MilestoneTicker]", func(shutdownSignal <-chan struct{}) {
+			timeutil.Ticker(adaptiveMilestoneTicker(), adaptiveSchedulingCheckInterval, shutdownSignal)
+		}, shutdown.PriorityCoordinator)
+
+	case coordinator.SchedulingModeOnDemand:
+		// milestones are only issued via IssueMilestone
+	}
+}
+
+// returns the ticker function of the "adaptive" scheduling mode,
+// which signals the next milestone if the interval for the current load has passed.
+func adaptiveMilestoneTicker() func() {
+	lastMilestoneSignal := time.Now()
+
+	return func() {
+		// the tracked tails of the selector are the unconfirmed transactions since the last milestone
+		if time.Since(lastMilestoneSignal) < adaptiveInterval.Interval(selector.GetTrackedTailsCount()) {
+			return
+		}
+
+		lastMilestoneSignal = time.Now()
+		signalNextMilestone()
+	}
+}
+
+// signals to issue the next milestone.
+func signalNextMilestone() {
+	select {
+	case nextMilestoneSignal <- struct{}{}:
+	default:
+		// do not block if already another signal is waiting
+	}
+}
+
+// IssueMilestone issues a new milestone if the coordinator runs in the "onDemand" scheduling mode.
+// Blocks until the milestone was issued, the request was aborted or timed out.
+func IssueMilestone(abortSignal <-chan struct{}) (milestone.Index, hornet.Hash, error) {
+	if coo == nil {
+		return 0, nil, ErrCoordinatorNotRunning
+	}
+
+	if schedulingMode != coordinator.SchedulingModeOnDemand {
+		return 0, nil, ErrNotOnDemand
+	}
+
+	if lease != nil && !isActive.Load() {
+		return 0, nil, ErrCoordinatorStandby
+	}
+
+	resultChan := make(chan *milestoneResult, 1)
+
+	// fail fast if the coordinator doesn't accept the request, e.g. while it waits for the node to sync
+	acceptTimer := time.NewTimer(milestoneRequestAcceptTimeout)
+	defer acceptTimer.Stop()
+
+	select {
+	case milestoneRequests <- resultChan:
+	case <-acceptTimer.C:
+		return 0, nil, ErrCoordinatorBusy
+	case <-abortSignal:
+		return 0, nil, ErrMilestoneRequestAborted
+	}
+
+	requestTimer := time.NewTimer(milestoneRequestTimeout)
+	defer requestTimer.Stop()
+
+	select {
+	case result := <-resultChan:
+		return result.index, result.hash, result.err
+	case <-requestTimer.C:
+		return 0, nil, ErrMilestoneRequestTimeout
+	case <-abortSignal:
+		return 0, nil, ErrMilestoneRequestAborted
+	}
+}
@@ -0,0 +1,106 @@
+package coordinator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/coordinator"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+// a selector which only reports a fixed amount of tracked tails.
+type trackedTailsSelector struct {
+	trackedTails int
+}
+
+func (s *trackedTailsSelector) OnNewSolidBundle(_ *tangle.Bundle) int {
+	return s.trackedTails
+}
+
+func (s *trackedTailsSelector) SelectTips(_ int) (hornet.Hashes, error) {
+	return nil, nil
+}
+
+func (s *trackedTailsSelector) GetTrackedTailsCount() int {
+	return s.trackedTails
+}
+
+func TestAdaptiveMilestoneTicker(t *testing.T) {
+	var err error
+	adaptiveInterval, err = coordinator.NewAdaptiveInterval(200*time.Millisecond, time.Hour, 100)
+	require.NoError(t, err)
+
+	testSelector := &trackedTailsSelector{}
+	selector = testSelector
+	nextMilestoneSignal = make(chan struct{}, 1)
+
+	tick := adaptiveMilestoneTicker()
+
+	// no milestone is due without load
+	tick()
+	require.Len(t, nextMilestoneSignal, 0)
+
+	// the minimum interval has to pass under high load
+	testSelector.trackedTails = 100
+	tick()
+	require.Len(t, nextMilestoneSignal, 0)
+
+	time.Sleep(200 * time.Millisecond)
+	tick()
+	require.Len(t, nextMilestoneSignal, 1)
+	<-nextMilestoneSignal
+
+	// the interval starts again after the signal
+	tick()
+	require.Len(t, nextMilestoneSignal, 0)
+}
+
+func TestIssueMilestoneOnDemand(t *testing.T) {
+	coo = &coordinator.Coordinator{}
+	defer func() { coo = nil }()
+
+	milestoneRequestAcceptTimeout = 100 * time.Millisecond
+	milestoneRequestTimeout = 100 * time.Millisecond
+	milestoneRequests = make(chan chan *milestoneResult)
+
+	// milestones are only issued on demand in the "onDemand" scheduling mode
+	schedulingMode = coordinator.SchedulingModeFixed
+	_, _, err := IssueMilestone(nil)
+	require.True(t, errors.Is(err, ErrNotOnDemand))
+
+	schedulingMode = coordinator.SchedulingModeOnDemand
+
+	// the request fails fast if the coordinator doesn't accept it
+	_, _, err = IssueMilestone(nil)
+	require.True(t, errors.Is(err, ErrCoordinatorBusy))
+
+	// the result of the coordinator is returned
+	msHash := hornet.NullHashBytes
+	go func() {
+		resultChan := <-milestoneRequests
+		resultChan <- &milestoneResult{index: 5, hash: msHash}
+	}()
+
+	msIndex, hash, err := IssueMilestone(nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, msIndex)
+	require.Equal(t, msHash, hash)
+
+	// an accepted request times out if the coordinator doesn't answer
+	go func() {
+		<-milestoneRequests
+	}()
+
+	_, _, err = IssueMilestone(nil)
+	require.True(t, errors.Is(err, ErrMilestoneRequestTimeout))
+
+	// the request is aborted by the abort signal
+	abortSignal := make(chan struct{})
+	close(abortSignal)
+	_, _, err = IssueMilestone(abortSignal)
+	require.True(t, errors.Is(err, ErrMilestoneRequestAborted))
+}
@@ -0,0 +1,65 @@
+package coordinator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iotaledger/iota.go/consts"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/coordinator/signer"
+)
+
+// creates the signer of the milestones configured in the config.
+func initSigner(securityLvl consts.SecurityLevel) (signer.Signer, error) {
+
+	signerType := config.NodeConfig.GetString(config.CfgCoordinatorSignerType)
+
+	switch strings.ToLower(signerType) {
+	case "inprocess":
+		seed, err := config.LoadHashFromEnvironment("COO_SEED", consts.HashTrytesSize)
+		if err != nil {
+			return nil, err
+		}
+
+		// the last trit of the seed will be ignored, so it is important security information when that happens
+		seed, modified := signer.NormalizeSeed(seed)
+		if modified {
+			log.Warn("The trit at index 243 of the coordinator seed is non-zero. " +
+				"The value of this trit will be ignored by the key derivation.")
+		}
+
+		inProcessSigner, err := signer.NewPersistentInProcessSigner(seed, securityLvl, config.NodeConfig.GetString(config.CfgCoordinatorSignerStateFilePath))
+		if err != nil {
+			return nil, err
+		}
+		return inProcessSigner, nil
+
+	case "remote":
+		socketPath := config.NodeConfig.GetString(config.CfgCoordinatorSignerRemoteSocketPath)
+		log.Infof("using the signing daemon at %s", socketPath)
+		return signer.NewRemoteSigner(socketPath), nil
+
+	case "softhsm":
+		pin, exists := os.LookupEnv("COO_HSM_PIN")
+		if !exists {
+			return nil, fmt.Errorf("environment variable '%s' not set", "COO_HSM_PIN")
+		}
+
+		hsm, err := signer.OpenSoftHSM(config.NodeConfig.GetString(config.CfgCoordinatorSignerSoftHSMTokenPath), securityLvl)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := hsm.Login(pin); err != nil {
+			return nil, err
+		}
+
+		log.Infof("using the software HSM token '%s'", hsm.Label())
+		return hsm, nil
+
+	default:
+		return nil, fmt.Errorf("unknown signer type '%s' in '%s'", signerType, config.CfgCoordinatorSignerType)
+	}
+}
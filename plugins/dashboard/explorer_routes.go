@@ -49,6 +49,7 @@ type ExplorerTx struct {
 	} `json:"confirmed"`
 	Approvers      []string        `json:"approvers"`
 	Solid          bool            `json:"solid"`
+	Retained       bool            `json:"retained"`
 	MWM            int             `json:"mwm"`
 	Previous       trinary.Hash    `json:"previous"`
 	Next           trinary.Hash    `json:"next"`
@@ -86,7 +87,8 @@ func createExplorerTx(cachedTx *tangle.CachedTransaction) (*ExplorerTx, error) {
 			Conflicting bool            `json:"conflicting"`
 			Milestone   milestone.Index `json:"milestone_index"`
 		}{confirmed, conflicting, by},
-		Solid: cachedTx.GetMetadata().IsSolid(),
+		Solid:    cachedTx.GetMetadata().IsSolid(),
+		Retained: cachedTx.GetMetadata().IsRetained(),
 	}
 
 	// Approvers
@@ -3,6 +3,7 @@ package dashboard
 import (
 	"net/http"
 	"runtime"
+	"sort"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -18,6 +19,7 @@ import (
 	"github.com/gohornet/hornet/pkg/basicauth"
 	"github.com/gohornet/hornet/pkg/config"
 	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/model/coordinator"
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
@@ -26,6 +28,7 @@ import (
 	"github.com/gohornet/hornet/pkg/shutdown"
 	"github.com/gohornet/hornet/plugins/autopeering"
 	"github.com/gohornet/hornet/plugins/cli"
+	coordinatorPlugin "github.com/gohornet/hornet/plugins/coordinator"
 	"github.com/gohornet/hornet/plugins/gossip"
 	metricsplugin "github.com/gohornet/hornet/plugins/metrics"
 	"github.com/gohornet/hornet/plugins/peering"
@@ -69,6 +72,8 @@ const (
 	MsgTypeSpamMetrics
 	// MsgTypeAvgSpamMetrics is the type of the AvgSpamMetric message.
 	MsgTypeAvgSpamMetrics
+	// MsgTypePeerHistoryEvent is the type of the peer connection lifecycle event message.
+	MsgTypePeerHistoryEvent
 )
 
 const (
@@ -158,18 +163,24 @@ func run(_ *node.Plugin) {
 		hub.BroadcastMsg(&Msg{Type: MsgTypeConfirmedMsMetrics, Data: []*tangleplugin.ConfirmedMilestoneMetric{metric}})
 	})
 
+	onPeerHistoryEvent := events.NewClosure(func(event *peer.HistoryEvent) {
+		hub.BroadcastMsg(&Msg{Type: MsgTypePeerHistoryEvent, Data: []*peer.HistoryEvent{event}})
+	})
+
 	daemon.BackgroundWorker("Dashboard[WSSend]", func(shutdownSignal <-chan struct{}) {
 		go hub.Run(shutdownSignal)
 		metricsplugin.Events.TPSMetricsUpdated.Attach(onTPSMetricsUpdated)
 		tangleplugin.Events.SolidMilestoneIndexChanged.Attach(onSolidMilestoneIndexChanged)
 		tangleplugin.Events.LatestMilestoneIndexChanged.Attach(onLatestMilestoneIndexChanged)
 		tangleplugin.Events.NewConfirmedMilestoneMetric.Attach(onNewConfirmedMilestoneMetric)
+		peering.Manager().Events.PeerHistoryEvent.Attach(onPeerHistoryEvent)
 		<-shutdownSignal
 		log.Info("Stopping Dashboard[WSSend] ...")
 		metricsplugin.Events.TPSMetricsUpdated.Detach(onTPSMetricsUpdated)
 		tangleplugin.Events.SolidMilestoneIndexChanged.Detach(onSolidMilestoneIndexChanged)
 		tangleplugin.Events.LatestMilestoneIndexChanged.Detach(onLatestMilestoneIndexChanged)
 		tangleplugin.Events.NewConfirmedMilestoneMetric.Detach(onNewConfirmedMilestoneMetric)
+		peering.Manager().Events.PeerHistoryEvent.Detach(onPeerHistoryEvent)
 
 		log.Info("Stopping Dashboard[WSSend] ... done")
 	}, shutdown.PriorityDashboard)
@@ -239,6 +250,8 @@ type NodeStatus struct {
 	ServerMetrics          *ServerMetrics  `json:"server_metrics"`
 	Mem                    *MemMetrics     `json:"mem"`
 	Caches                 *CachesMetric   `json:"caches"`
+	// only set if the node runs the coordinator
+	CoordinatorMerkleKeys *coordinator.MerkleKeyUsage `json:"coordinator_merkle_keys,omitempty"`
 }
 
 // ServerMetrics are global metrics of the server.
@@ -289,6 +302,7 @@ type PeerMetric struct {
 	Heartbeat        *sting.Heartbeat      `json:"heartbeat"`
 	Info             *peer.Info            `json:"info"`
 	Connected        bool                  `json:"connected"`
+	Score            float64               `json:"score"`
 }
 
 // CachesMetric represents cache metrics.
@@ -314,6 +328,7 @@ func peerMetrics() []*PeerMetric {
 		m := &PeerMetric{
 			OriginAddr: info.DomainWithPort,
 			Info:       info,
+			Score:      info.Score,
 		}
 		if info.Peer != nil && info.Peer.Protocol != nil {
 			m.Identity = info.Peer.ID
@@ -369,6 +384,7 @@ func currentNodeStatus() *NodeStatus {
 	status.RequestQueuePending = pending
 	status.RequestQueueProcessing = processing
 	status.RequestQueueAvgLatency = gossip.RequestQueue().AvgLatency()
+	status.CoordinatorMerkleKeys = coordinatorPlugin.MerkleKeyUsage()
 
 	// cache metrics
 	status.Caches = &CachesMetric{
@@ -429,3 +445,15 @@ func currentNodeStatus() *NodeStatus {
 	}
 	return status
 }
+
+// returns the recorded connection lifecycle events of all peers ordered by their time.
+func peerHistoryEvents() []*peer.HistoryEvent {
+	var historyEvents []*peer.HistoryEvent
+	for _, history := range peering.Manager().PeerHistories() {
+		historyEvents = append(historyEvents, history...)
+	}
+	sort.Slice(historyEvents, func(i, j int) bool {
+		return historyEvents[i].Timestamp.Before(historyEvents[j].Timestamp)
+	})
+	return historyEvents
+}
@@ -162,6 +162,9 @@ func websocketRoute(ctx echo.Context) error {
 		case MsgTypeDatabaseSizeMetric:
 			client.Send(&Msg{Type: MsgTypeDatabaseSizeMetric, Data: cachedDbSizeMetrics})
 
+		case MsgTypePeerHistoryEvent:
+			client.Send(&Msg{Type: MsgTypePeerHistoryEvent, Data: peerHistoryEvents()})
+
 		case MsgTypeDatabaseCleanupEvent:
 			client.Send(&Msg{Type: MsgTypeDatabaseCleanupEvent, Data: lastDbCleanup})
 
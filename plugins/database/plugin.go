@@ -65,7 +65,7 @@ func pruneTransactions(txsToCheckMap map[string]struct{}) int {
 	return len(txsToDeleteMap)
 }
 
-// pruneMilestone prunes the milestone metadata and the ledger diffs from the database for the given milestone
+// pruneMilestone prunes the milestone metadata, the ledger diffs, the conflict reasons and the white-flag confirmation results from the database for the given milestone
 func pruneMilestone(milestoneIndex milestone.Index) {
 
 	// state diffs
@@ -73,6 +73,16 @@ func pruneMilestone(milestoneIndex milestone.Index) {
 		log.Warn(err)
 	}
 
+	// conflict reasons
+	if err := tangle.DeleteConflictsForMilestone(milestoneIndex); err != nil {
+		log.Warn(err)
+	}
+
+	// white-flag confirmation results
+	if err := tangle.DeleteMilestoneConfirmation(milestoneIndex); err != nil {
+		log.Warn(err)
+	}
+
 	tangle.DeleteMilestone(milestoneIndex)
 }
 
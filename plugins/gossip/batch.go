@@ -0,0 +1,72 @@
+package gossip
+
+import (
+	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/message"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+	"github.com/gohornet/hornet/pkg/protocol/tlv"
+)
+
+// returns whether the given message is a transaction message.
+func isTransactionMessage(data []byte) bool {
+	return len(data) > int(tlv.HeaderMessageDefinition.MaxBytesLength) && message.Type(data[0]) == sting.MessageTypeTransaction
+}
+
+// batchTransactions merges the given transaction message and all further transaction messages
+// which are already queued with the same priority into a single transaction batch message.
+// batching never waits for further messages, so it doesn't add any latency.
+// returns the messages to send in order and the amount of transactions contained in the batch.
+func batchTransactions(p *peer.Peer, data []byte, prio peer.SendPriority) (msgs [][]byte, batchedTxs int) {
+	headerBytesLength := int(tlv.HeaderMessageDefinition.MaxBytesLength)
+
+	txsMsgs := [][]byte{data}
+	batchBytesLength := sting.TransactionBatchTransactionLengthBytesLength + len(data) - headerBytesLength
+
+	var pending []byte
+	for {
+		next, ok := p.SendQueue.TryNext(prio)
+		if !ok {
+			break
+		}
+
+		if !isTransactionMessage(next) || batchBytesLength+sting.TransactionBatchTransactionLengthBytesLength+len(next)-headerBytesLength > sting.MaxTransactionBatchBytesLength {
+			// keep the order of the messages with the same priority
+			pending = next
+			break
+		}
+
+		txsMsgs = append(txsMsgs, next)
+		batchBytesLength += sting.TransactionBatchTransactionLengthBytesLength + len(next) - headerBytesLength
+	}
+
+	if len(txsMsgs) > 1 {
+		txsData := make([][]byte, len(txsMsgs))
+		for i, txMsg := range txsMsgs {
+			txsData[i] = txMsg[headerBytesLength:]
+		}
+
+		batchMsg, err := sting.NewTransactionBatchMessage(txsData)
+		if err == nil {
+			msgs = append(msgs, batchMsg)
+			batchedTxs = len(txsMsgs)
+		} else {
+			// fall back to single transaction messages
+			msgs = append(msgs, txsMsgs...)
+		}
+	} else {
+		msgs = append(msgs, data)
+	}
+
+	if pending != nil {
+		msgs = append(msgs, pending)
+	}
+
+	return msgs, batchedTxs
+}
+
+// increases the metrics of the transactions sent in a transaction batch message.
+func transactionBatchSent(p *peer.Peer, batchedTxs int) {
+	p.Metrics.SentTransactions.Add(uint32(batchedTxs))
+	metrics.SharedServerMetrics.SentTransactions.Add(uint32(batchedTxs))
+}
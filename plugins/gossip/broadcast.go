@@ -1,13 +1,21 @@
 package gossip
 
 import (
+	"go.uber.org/atomic"
+
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/peering/peer"
+	"github.com/gohornet/hornet/pkg/protocol/handshake"
 	"github.com/gohornet/hornet/pkg/protocol/helpers"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
 )
 
+var (
+	// used to spread the milestone cone requests over the peers.
+	milestoneConeRequestsCounter atomic.Uint32
+)
+
 // BroadcastHeartbeat broadcasts a heartbeat message to every connected peer who supports STING.
 func BroadcastHeartbeat(filter func(p *peer.Peer) bool) {
 	snapshotInfo := tangle.GetSnapshotInfo()
@@ -17,6 +25,7 @@ func BroadcastHeartbeat(filter func(p *peer.Peer) bool) {
 
 	connected, synced := manager.ConnectedAndSyncedPeerCount()
 	heartbeatMsg, _ := sting.NewHeartbeatMessage(tangle.GetSolidMilestoneIndex(), snapshotInfo.PruningIndex, tangle.GetLatestMilestoneIndex(), connected, synced)
+	heartbeatWithFlagsMsg, _ := sting.NewHeartbeatMessage(tangle.GetSolidMilestoneIndex(), snapshotInfo.PruningIndex, tangle.GetLatestMilestoneIndex(), connected, synced, heartbeatFlags())
 
 	manager.ForAllConnected(func(p *peer.Peer) bool {
 		if !p.Protocol.Supports(sting.FeatureSet) {
@@ -27,6 +36,11 @@ func BroadcastHeartbeat(filter func(p *peer.Peer) bool) {
 			return true
 		}
 
+		if p.Protocol.SupportsExtension(handshake.ExtensionHeartbeatFlags) {
+			p.EnqueueForSending(heartbeatWithFlagsMsg)
+			return true
+		}
+
 		p.EnqueueForSending(heartbeatMsg)
 		return true
 	})
@@ -75,3 +89,43 @@ func BroadcastMilestoneRequests(rangeToRequest int, onExistingMilestoneInRange f
 	}
 	return requested
 }
+
+// RequestMilestoneCones requests the cones of the milestones in the given range in bulk.
+// The range is split into batches which are distributed over the connected peers who support
+// the milestone cone sync extension and have the data. Returns the number of milestones requested.
+func RequestMilestoneCones(startIndex milestone.Index, endIndex milestone.Index) int {
+	var conePeers []*peer.Peer
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		if p.Protocol.Supports(sting.FeatureSet) && p.Protocol.SupportsExtension(handshake.ExtensionMilestoneConeSync) {
+			conePeers = append(conePeers, p)
+		}
+		return true
+	})
+
+	if len(conePeers) == 0 {
+		return 0
+	}
+
+	var requested int
+	for startIndex <= endIndex {
+		batchEnd := startIndex + sting.MaxMilestoneConeRequestRange - 1
+		if batchEnd > endIndex {
+			batchEnd = endIndex
+		}
+
+		// rotate the peers to spread the batches
+		offset := int(milestoneConeRequestsCounter.Inc())
+		for i := 0; i < len(conePeers); i++ {
+			p := conePeers[(offset+i)%len(conePeers)]
+			if !p.HasDataFor(startIndex) || !p.HasDataFor(batchEnd) {
+				continue
+			}
+			helpers.SendMilestoneConeRequest(p, startIndex, batchEnd)
+			requested += int(batchEnd-startIndex) + 1
+			break
+		}
+
+		startIndex = batchEnd + 1
+	}
+	return requested
+}
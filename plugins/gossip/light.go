@@ -0,0 +1,74 @@
+package gossip
+
+import (
+	"github.com/iotaledger/iota.go/address"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
+)
+
+var (
+	// whether the node runs in light mode.
+	lightMode bool
+	// the addresses whose unconfirmed transactions are processed in light mode.
+	watchedAddresses = make(map[string]struct{})
+)
+
+// configures the light mode from the config.
+func configureLightMode() {
+	lightMode = config.NodeConfig.GetBool(config.CfgNetGossipLightModeEnabled)
+	if !lightMode {
+		return
+	}
+
+	addresses := config.NodeConfig.GetStringSlice(config.CfgNetGossipLightModeWatchedAddresses)
+	if len(addresses) > sting.MaxWatchedAddresses {
+		log.Fatalf("too many watched addresses in '%s', the maximum is %d", config.CfgNetGossipLightModeWatchedAddresses, sting.MaxWatchedAddresses)
+	}
+
+	for _, addr := range addresses {
+		if err := address.ValidAddress(addr); err != nil {
+			log.Fatalf("invalid watched address '%s' in '%s': %s", addr, config.CfgNetGossipLightModeWatchedAddresses, err)
+		}
+		watchedAddresses[string(hornet.HashFromAddressTrytes(addr))] = struct{}{}
+	}
+
+	log.Infof("running in light mode, received transactions are not relayed and %d addresses are watched", len(watchedAddresses))
+}
+
+// LightMode tells whether the node runs in light mode, in which received transactions aren't relayed
+// and only milestone cones and transactions of the watched addresses are processed.
+// The watched addresses are announced to peers, so that they forward the transactions of these addresses.
+func LightMode() bool {
+	return lightMode
+}
+
+// returns the watched addresses which are announced to peers in light mode.
+func watchedAddressHashes() hornet.Hashes {
+	addresses := make(hornet.Hashes, 0, len(watchedAddresses))
+	for addr := range watchedAddresses {
+		addresses = append(addresses, hornet.Hash(addr))
+	}
+	return addresses
+}
+
+// returns the flags which are sent to peers in heartbeat messages.
+func heartbeatFlags() sting.HeartbeatFlag {
+	var flags sting.HeartbeatFlag
+	if lightMode {
+		flags |= sting.HeartbeatFlagLight
+	}
+	return flags
+}
+
+// tells whether a transaction which wasn't requested should be processed.
+// in light mode, only the transactions of the watched addresses are processed.
+func acceptUnrequestedTransaction(tx *hornet.Transaction) bool {
+	if !lightMode {
+		return true
+	}
+
+	_, watched := watchedAddresses[string(tx.GetAddress())]
+	return watched
+}
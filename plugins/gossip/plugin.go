@@ -16,10 +16,12 @@ import (
 	"github.com/gohornet/hornet/pkg/peering/peer"
 	"github.com/gohornet/hornet/pkg/profile"
 	"github.com/gohornet/hornet/pkg/protocol/bqueue"
+	"github.com/gohornet/hornet/pkg/protocol/message"
 	"github.com/gohornet/hornet/pkg/protocol/processor"
 	"github.com/gohornet/hornet/pkg/protocol/rqueue"
 	"github.com/gohornet/hornet/pkg/protocol/sting"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/utils"
 	peeringplugin "github.com/gohornet/hornet/plugins/peering"
 )
 
@@ -56,8 +58,10 @@ func BroadcastQueue() bqueue.Queue {
 func Processor() *processor.Processor {
 	msgProcessorOnce.Do(func() {
 		msgProcessor = processor.New(requestQueue, peeringplugin.Manager(), &processor.Options{
-			ValidMWM:          config.NodeConfig.GetUint64(config.CfgCoordinatorMWM),
-			WorkUnitCacheOpts: profile.LoadProfile().Caches.IncomingTransactionFilter,
+			ValidMWM:                     config.NodeConfig.GetUint64(config.CfgCoordinatorMWM),
+			WorkUnitCacheOpts:            profile.LoadProfile().Caches.IncomingTransactionFilter,
+			UnrequestedTransactionFilter: acceptUnrequestedTransaction,
+			DisableRelaying:              lightMode,
 		})
 	})
 	return msgProcessor
@@ -68,6 +72,8 @@ func configure(plugin *node.Plugin) {
 
 	manager = peeringplugin.Manager()
 
+	configureLightMode()
+
 	// create networking queues
 	RequestQueue()
 	BroadcastQueue()
@@ -82,33 +88,71 @@ func configure(plugin *node.Plugin) {
 	manager.Events.PeerConnected.Attach(events.NewClosure(func(p *peer.Peer) {
 
 		if p.Protocol.Supports(sting.FeatureSet) {
+			p.MilestoneConeLimiter = utils.NewRateLimiter(config.NodeConfig.GetInt(config.CfgWarpSyncMilestoneConesPerSecond))
 			addSTINGMessageEventHandlers(p)
 
 			// send heartbeat and latest milestone request
 			if snapshotInfo := tangle.GetSnapshotInfo(); snapshotInfo != nil {
 				connected, synced := manager.ConnectedAndSyncedPeerCount()
-				helpers.SendHeartbeat(p, tangle.GetSolidMilestoneIndex(), snapshotInfo.PruningIndex, tangle.GetLatestMilestoneIndex(), connected, synced)
+				helpers.SendHeartbeat(p, tangle.GetSolidMilestoneIndex(), snapshotInfo.PruningIndex, tangle.GetLatestMilestoneIndex(), connected, synced, heartbeatFlags())
 				helpers.SendLatestMilestoneRequest(p)
 			}
+
+			if lightMode {
+				helpers.SendWatchedAddresses(p, watchedAddressHashes())
+			}
 		}
 
 		disconnectSignal := make(chan struct{})
 		p.Conn.Events.Close.Attach(events.NewClosure(func() {
 			removeMessageEventHandlers(p)
+			requestQueue.RemovePeer(p.ID)
 			close(disconnectSignal)
 		}))
 
 		// fire up send queue consumer
 		daemon.BackgroundWorker(fmt.Sprintf("send queue %s", p.ID), func(shutdownSignal <-chan struct{}) {
-			for {
+			abortSignal := make(chan struct{})
+			go func() {
 				select {
 				case <-disconnectSignal:
-					return
 				case <-shutdownSignal:
+				}
+				close(abortSignal)
+			}()
+
+			// returns false if the sending was aborted
+			send := func(data []byte) bool {
+				if !p.UploadLimiter.Wait(len(data), abortSignal) {
+					return false
+				}
+
+				if err := p.Protocol.Send(data); err != nil {
+					p.Protocol.Events.Error.Trigger(err)
+				}
+				return true
+			}
+
+			for {
+				data, prio, ok := p.SendQueue.Next(abortSignal)
+				if !ok {
 					return
-				case data := <-p.SendQueue:
-					if err := p.Protocol.Send(data); err != nil {
-						p.Protocol.Events.Error.Trigger(err)
+				}
+
+				if !p.Protocol.Supports(sting.TransactionBatchFeatureSet) || !isTransactionMessage(data) {
+					if !send(data) {
+						return
+					}
+					continue
+				}
+
+				msgs, batchedTxs := batchTransactions(p, data, prio)
+				for _, msg := range msgs {
+					if !send(msg) {
+						return
+					}
+					if message.Type(msg[0]) == sting.MessageTypeTransactionBatch {
+						transactionBatchSent(p, batchedTxs)
 					}
 				}
 			}
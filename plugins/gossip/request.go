@@ -6,6 +6,7 @@ import (
 
 	"github.com/iotaledger/hive.go/daemon"
 
+	"github.com/gohornet/hornet/pkg/config"
 	"github.com/gohornet/hornet/pkg/dag"
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
@@ -57,6 +58,8 @@ func runRequestWorkers() {
 		}
 	}, shutdown.PriorityRequestsProcessor)
 
+	maxRequestsInFlightPerPeer := config.NodeConfig.GetInt(config.CfgNetGossipMaxRequestsInFlightPerPeer)
+
 	daemon.BackgroundWorker("STINGRequester", func(shutdownSignal <-chan struct{}) {
 		for {
 			select {
@@ -71,46 +74,64 @@ func runRequestWorkers() {
 
 				// drain request queue
 				for r := RequestQueue().Next(); r != nil; r = RequestQueue().Next() {
-					requested := false
-					manager.ForAllConnected(func(p *peer.Peer) bool {
-						if !p.Protocol.Supports(sting.FeatureSet) {
-							return true
-						}
-						// we only send a request message if the peer actually has the data
-						// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex)
-						if !p.HasDataFor(r.MilestoneIndex) {
-							return true
-						}
-
-						helpers.SendTransactionRequest(p, r.Hash)
-						requested = true
-						return false
-					})
-
-					if !requested {
-						// We have no neighbor that has the data for sure,
-						// so we ask all neighbors that could have the data
-						// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= LatestMilestoneIndex)
-						manager.ForAllConnected(func(p *peer.Peer) bool {
-							if !p.Protocol.Supports(sting.FeatureSet) {
-								return true
-							}
-
-							// we only send a request message if the peer could have the data
-							if !p.CouldHaveDataFor(r.MilestoneIndex) {
-								return true
-							}
-
-							helpers.SendTransactionRequest(p, r.Hash)
-							return true
-						})
-					}
+					sendRequest(r, maxRequestsInFlightPerPeer)
 				}
 			}
 		}
 	}, shutdown.PriorityRequestsProcessor)
 }
 
+// sends the given request to the fastest peer which has the data for sure.
+// if no such peer is available, the request is sent to all peers which could have the data.
+func sendRequest(r *rqueue.Request, maxRequestsInFlightPerPeer int) {
+	var candidates []string
+	peers := make(map[string]*peer.Peer)
+
+	// the candidates are ordered by their reputation, which decides on equal latency
+	manager.ForAllConnectedByScore(func(p *peer.Peer) bool {
+		if !p.Protocol.Supports(sting.FeatureSet) {
+			return true
+		}
+		// we only send a request message if the peer actually has the data
+		// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex)
+		if !p.HasDataFor(r.MilestoneIndex) {
+			return true
+		}
+		candidates = append(candidates, p.ID)
+		peers[p.ID] = p
+		return true
+	})
+
+	if len(candidates) > 0 {
+		peerID := RequestQueue().BestPeer(r, candidates, maxRequestsInFlightPerPeer)
+		if peerID == "" {
+			// all peers which have the data are busy, the request stays pending and is enqueued again later
+			return
+		}
+
+		helpers.SendTransactionRequest(peers[peerID], r.Hash)
+		RequestQueue().Requested(r, peerID)
+		return
+	}
+
+	// We have no neighbor that has the data for sure,
+	// so we ask all neighbors that could have the data
+	// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= LatestMilestoneIndex)
+	manager.ForAllConnected(func(p *peer.Peer) bool {
+		if !p.Protocol.Supports(sting.FeatureSet) {
+			return true
+		}
+
+		// we only send a request message if the peer could have the data
+		if !p.CouldHaveDataFor(r.MilestoneIndex) {
+			return true
+		}
+
+		helpers.SendTransactionRequest(p, r.Hash)
+		return true
+	})
+}
+
 // adds the request to the request queue and signals the request to drain it.
 func enqueueAndSignal(r *rqueue.Request) bool {
 	if !RequestQueue().Enqueue(r) {
@@ -27,6 +27,14 @@ func addSTINGMessageEventHandlers(p *peer.Peer) {
 		metrics.SharedServerMetrics.SentTransactions.Inc()
 	}))
 
+	p.Protocol.Events.Received[sting.MessageTypeTransactionBatch].Attach(events.NewClosure(func(data []byte) {
+		msgProcessor.Process(p, sting.MessageTypeTransactionBatch, data)
+	}))
+
+	p.Protocol.Events.Sent[sting.MessageTypeTransactionBatch].Attach(events.NewClosure(func() {
+		p.Metrics.SentPackets.Inc()
+	}))
+
 	p.Protocol.Events.Received[sting.MessageTypeTransactionRequest].Attach(events.NewClosure(func(data []byte) {
 		p.Metrics.ReceivedTransactionRequests.Inc()
 		metrics.SharedServerMetrics.ReceivedTransactionRequests.Inc()
@@ -51,11 +59,37 @@ func addSTINGMessageEventHandlers(p *peer.Peer) {
 		metrics.SharedServerMetrics.SentMilestoneRequests.Inc()
 	}))
 
+	p.Protocol.Events.Received[sting.MessageTypeMilestoneConeRequest].Attach(events.NewClosure(func(data []byte) {
+		p.Metrics.ReceivedMilestoneRequests.Inc()
+		metrics.SharedServerMetrics.ReceivedMilestoneRequests.Inc()
+		msgProcessor.Process(p, sting.MessageTypeMilestoneConeRequest, data)
+	}))
+
+	p.Protocol.Events.Sent[sting.MessageTypeMilestoneConeRequest].Attach(events.NewClosure(func() {
+		p.Metrics.SentPackets.Inc()
+		p.Metrics.SentMilestoneRequests.Inc()
+		metrics.SharedServerMetrics.SentMilestoneRequests.Inc()
+	}))
+
+	p.Protocol.Events.Received[sting.MessageTypeMilestoneCone].Attach(events.NewClosure(func(data []byte) {
+		msgProcessor.ProcessMilestoneConeChunk(p, data)
+	}))
+
+	p.Protocol.Events.Sent[sting.MessageTypeMilestoneCone].Attach(events.NewClosure(func() {
+		p.Metrics.SentPackets.Inc()
+	}))
+
 	p.Protocol.Events.Received[sting.MessageTypeHeartbeat].Attach(events.NewClosure(func(data []byte) {
 		p.Metrics.ReceivedHeartbeats.Inc()
 		metrics.SharedServerMetrics.ReceivedHeartbeats.Inc()
 
-		p.LatestHeartbeat = sting.ParseHeartbeat(data)
+		heartbeat, err := sting.ParseHeartbeat(data)
+		if err != nil {
+			p.Protocol.Events.Error.Trigger(err)
+			return
+		}
+
+		p.LatestHeartbeat = heartbeat
 		p.HeartbeatReceivedTime = time.Now()
 
 		if p.Autopeering != nil && p.LatestHeartbeat.SolidMilestoneIndex < tangle.GetSnapshotInfo().PruningIndex {
@@ -69,6 +103,20 @@ func addSTINGMessageEventHandlers(p *peer.Peer) {
 		p.Events.HeartbeatUpdated.Trigger(p.LatestHeartbeat)
 	}))
 
+	p.Protocol.Events.Received[sting.MessageTypeWatchedAddresses].Attach(events.NewClosure(func(data []byte) {
+		addresses, err := sting.ParseWatchedAddresses(data)
+		if err != nil {
+			p.Protocol.Events.Error.Trigger(err)
+			return
+		}
+
+		p.SetWatchedAddresses(addresses)
+	}))
+
+	p.Protocol.Events.Sent[sting.MessageTypeWatchedAddresses].Attach(events.NewClosure(func() {
+		p.Metrics.SentPackets.Inc()
+	}))
+
 	p.Protocol.Events.Sent[sting.MessageTypeHeartbeat].Attach(events.NewClosure(func() {
 		p.Metrics.SentPackets.Inc()
 		p.Metrics.SentHeartbeats.Inc()
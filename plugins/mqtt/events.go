@@ -10,6 +10,8 @@ import (
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+	"github.com/gohornet/hornet/plugins/snapshot"
 )
 
 var (
@@ -93,6 +95,20 @@ func onSpentAddress(addr trinary.Hash) {
 	}
 }
 
+func onPruningMilestoneIndexChanged(_ milestone.Index) {
+	if err := publishPruningStatus(); err != nil {
+		log.Warn(err.Error())
+	}
+}
+
+func onMilestoneConfirmed(confirmation *whiteflag.Confirmation) {
+	for _, conflict := range confirmation.Mutations.Conflicts {
+		if err := publishConflict(conflict, confirmation.MilestoneIndex); err != nil {
+			log.Warn(err.Error())
+		}
+	}
+}
+
 // Publish latest milestone index
 func publishLMI(lmi milestone.Index) error {
 
@@ -210,3 +226,36 @@ func publishTx(iotaTx *transaction.Transaction) error {
 func publishSpentAddress(addr trinary.Hash) error {
 	return mqttBroker.Send(topicSpentAddress, addr)
 }
+
+// Publish the progress of the running pruning
+func publishPruningStatus() error {
+
+	status, err := snapshot.GetPruningStatus()
+	if err != nil {
+		return err
+	}
+
+	return mqttBroker.Send(topicPruning, fmt.Sprintf(`{"pruningIndex":%d,"targetIndex":%d,"percentage":%0.2f,"eta":%d,"timestamp":"%s"}`,
+		status.PruningIndex,         // Index up to which the database is pruned
+		status.TargetIndex,          // Target index of the pruning
+		status.Percentage,           // Finished percentage of the pruning
+		int64(status.ETA.Seconds()), // Estimated remaining time in seconds
+		time.Now().UTC().Format(time.RFC3339)))
+}
+
+// Publish the reason why a bundle was excluded as conflicting by a milestone
+func publishConflict(conflict *tangle.Conflict, msIndex milestone.Index) error {
+
+	var conflictingTailHash trinary.Hash
+	if len(conflict.ConflictingTailHash) > 0 {
+		conflictingTailHash = conflict.ConflictingTailHash.Trytes()
+	}
+
+	return mqttBroker.Send(topicConflict, fmt.Sprintf(`{"msIndex":%d,"tailHash":"%v","reason":"%v","address":"%v","conflictingTailHash":"%v","timestamp":"%s"}`,
+		msIndex,                    // Index of the milestone that excluded the bundle
+		conflict.TailHash.Trytes(), // Tail transaction hash of the conflicting bundle
+		conflict.Reason,            // Reason of the conflict
+		conflict.Address.Trytes(),  // Address which caused the conflict
+		conflictingTailHash,        // Tail transaction hash of the bundle which spent the balance before (double spends only)
+		time.Now().UTC().Format(time.RFC3339)))
+}
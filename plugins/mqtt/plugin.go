@@ -12,6 +12,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	tanglePackage "github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/whiteflag"
 	"github.com/gohornet/hornet/plugins/tangle"
 )
 
@@ -40,6 +41,14 @@ var (
 	spentAddressWorkerQueueSize = 1000
 	spentAddressWorkerPool      *workerpool.WorkerPool
 
+	pruningWorkerCount     = 1
+	pruningWorkerQueueSize = 100
+	pruningWorkerPool      *workerpool.WorkerPool
+
+	conflictWorkerCount     = 1
+	conflictWorkerQueueSize = 100
+	conflictWorkerPool      *workerpool.WorkerPool
+
 	wasSyncBefore = false
 
 	mqttBroker *Broker
@@ -74,6 +83,16 @@ func configure(plugin *node.Plugin) {
 		task.Return(nil)
 	}, workerpool.WorkerCount(spentAddressWorkerCount), workerpool.QueueSize(spentAddressWorkerQueueSize))
 
+	pruningWorkerPool = workerpool.New(func(task workerpool.Task) {
+		onPruningMilestoneIndexChanged(task.Param(0).(milestone.Index))
+		task.Return(nil)
+	}, workerpool.WorkerCount(pruningWorkerCount), workerpool.QueueSize(pruningWorkerQueueSize))
+
+	conflictWorkerPool = workerpool.New(func(task workerpool.Task) {
+		onMilestoneConfirmed(task.Param(0).(*whiteflag.Confirmation))
+		task.Return(nil)
+	}, workerpool.WorkerCount(conflictWorkerCount), workerpool.QueueSize(conflictWorkerQueueSize))
+
 	var err error
 	mqttBroker, err = NewBroker()
 	if err != nil {
@@ -146,6 +165,24 @@ func run(plugin *node.Plugin) {
 		spentAddressWorkerPool.TrySubmit(addr)
 	})
 
+	onPruningMilestoneIndexChanged := events.NewClosure(func(msIndex milestone.Index) {
+		pruningWorkerPool.TrySubmit(msIndex)
+	})
+
+	onMilestoneConfirmation := events.NewClosure(func(confirmation *whiteflag.Confirmation) {
+		if !wasSyncBefore {
+			// Not sync
+			return
+		}
+
+		// only milestones with conflicting bundles are of interest
+		if len(confirmation.Mutations.Conflicts) == 0 {
+			return
+		}
+
+		conflictWorkerPool.TrySubmit(confirmation)
+	})
+
 	daemon.BackgroundWorker("MQTT Broker", func(shutdownSignal <-chan struct{}) {
 		go func() {
 			if err := startBroker(plugin); err != nil {
@@ -230,6 +267,28 @@ func run(plugin *node.Plugin) {
 		spentAddressWorkerPool.StopAndWait()
 		log.Info("Stopping MQTT[SpentAddress] ... done")
 	}, shutdown.PriorityMetricsPublishers)
+
+	daemon.BackgroundWorker("MQTT[Pruning]", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting MQTT[Pruning] ... done")
+		tangle.Events.PruningMilestoneIndexChanged.Attach(onPruningMilestoneIndexChanged)
+		pruningWorkerPool.Start()
+		<-shutdownSignal
+		log.Info("Stopping MQTT[Pruning] ...")
+		tangle.Events.PruningMilestoneIndexChanged.Detach(onPruningMilestoneIndexChanged)
+		pruningWorkerPool.StopAndWait()
+		log.Info("Stopping MQTT[Pruning] ... done")
+	}, shutdown.PriorityMetricsPublishers)
+
+	daemon.BackgroundWorker("MQTT[Conflicts]", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting MQTT[Conflicts] ... done")
+		tangle.Events.MilestoneConfirmed.Attach(onMilestoneConfirmation)
+		conflictWorkerPool.Start()
+		<-shutdownSignal
+		log.Info("Stopping MQTT[Conflicts] ...")
+		tangle.Events.MilestoneConfirmed.Detach(onMilestoneConfirmation)
+		conflictWorkerPool.StopAndWait()
+		log.Info("Stopping MQTT[Conflicts] ... done")
+	}, shutdown.PriorityMetricsPublishers)
 }
 
 // Start the mqtt broker.
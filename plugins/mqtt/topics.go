@@ -12,6 +12,8 @@ const (
 	topicTxTrytes     = "trytes"
 	topicTX           = "tx"
 	topicSpentAddress = "spent_address"
+	topicPruning      = "pruning"
+	topicConflict     = "conflict"
 	//topicPrefixAddress = "addr/"
 )
 
@@ -46,7 +46,7 @@ func runConfigWatcher() {
 					log.Warn(err)
 				}
 				// and re-add it with the updated info
-				if err := Manager().Add(p.ID, p.PreferIPv6, p.Alias); err != nil {
+				if err := Manager().Add(p.ID, p.PreferIPv6, p.Alias, p.PublicKey); err != nil {
 					log.Warn("was unable to re-add modified peer %s", p.ID)
 				}
 			}
@@ -56,7 +56,7 @@ func runConfigWatcher() {
 		if len(added) > 0 {
 			log.Infof("adding peers due to config change")
 			for _, p := range added {
-				if err := Manager().Add(p.ID, p.PreferIPv6, p.Alias); err != nil {
+				if err := Manager().Add(p.ID, p.PreferIPv6, p.Alias, p.PublicKey); err != nil {
 					log.Warn("was unable to re-add modified peer %s", p.ID)
 				}
 			}
@@ -79,11 +79,16 @@ func getPeerConfigDiff() (modified, added, removed []config.PeerConfig) {
 			continue
 		}
 
+		if sourceSyncer != nil && sourceSyncer.Contains(currentPeer.Address, currentPeer.DomainWithPort) {
+			// ignore neighbors managed by the peer sources
+			continue
+		}
+
 		found := false
 		for _, configPeer := range configPeers {
 			if strings.EqualFold(currentPeer.Address, configPeer.ID) || strings.EqualFold(currentPeer.DomainWithPort, configPeer.ID) {
 				found = true
-				if (currentPeer.PreferIPv6 != configPeer.PreferIPv6) || (currentPeer.Alias != configPeer.Alias) {
+				if (currentPeer.PreferIPv6 != configPeer.PreferIPv6) || (currentPeer.Alias != configPeer.Alias) || !strings.EqualFold(currentPeer.PinnedPublicKey, configPeer.PublicKey) {
 					modified = append(modified, configPeer)
 				}
 				break
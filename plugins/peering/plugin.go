@@ -19,6 +19,7 @@ import (
 	"github.com/gohornet/hornet/pkg/peering/peer"
 	"github.com/gohornet/hornet/pkg/protocol"
 	"github.com/gohornet/hornet/pkg/protocol/handshake"
+	"github.com/gohornet/hornet/pkg/protocol/sting"
 	"github.com/gohornet/hornet/pkg/shutdown"
 )
 
@@ -44,6 +45,11 @@ func Manager() *peering.Manager {
 			log.Fatalf("couldn't initialize protocol: %s", err)
 		}
 
+		if !config.NodeConfig.GetBool(config.CfgNetGossipTransactionBatching) {
+			// don't advertise the support for transaction batches, so peers send single transactions
+			protocol.SupportedOptionalFeatureSets &^= sting.TransactionBatchFeatureSet
+		}
+
 		// load initial config peers
 		var peers []*config.PeerConfig
 		if err := config.PeeringConfig.UnmarshalKey(config.CfgPeers, &peers); err != nil {
@@ -63,6 +69,21 @@ func Manager() *peering.Manager {
 			peers = append(peers, &config.PeerConfig{ID: p})
 		}
 
+		// load the identity for encrypted connections
+		var identity *peering.Identity
+		if config.NodeConfig.GetBool(config.CfgNetGossipEncryptionEnabled) {
+			var err error
+			identity, err = peering.LoadOrCreateIdentity(config.NodeConfig.GetString(config.CfgNetGossipEncryptionIdentityPrivateKeyPath))
+			if err != nil {
+				log.Fatalf("couldn't load gossip identity: %s", err)
+			}
+			log.Infof("encrypted gossip transport enabled, public key of this node: %s", identity.PublicKeyHex())
+		}
+
+		if identity == nil && config.NodeConfig.GetBool(config.CfgNetGossipEncryptionRequired) {
+			log.Fatalf("'%s' needs '%s' to be enabled", config.CfgNetGossipEncryptionRequired, config.CfgNetGossipEncryptionEnabled)
+		}
+
 		// init peer manager
 		manager = peering.NewManager(peering.Options{
 			BindAddress: config.NodeConfig.GetString(config.CfgNetGossipBindAddress),
@@ -70,9 +91,30 @@ func Manager() *peering.Manager {
 				ByteEncodedCooAddress: cooAddrBytes,
 				MWM:                   byte(mwm),
 			},
-			MaxConnected:  config.PeeringConfig.GetInt(config.CfgPeeringMaxPeers),
-			AcceptAnyPeer: config.PeeringConfig.GetBool(config.CfgPeeringAcceptAnyConnection),
+			MaxConnected:      config.PeeringConfig.GetInt(config.CfgPeeringMaxPeers),
+			AcceptAnyPeer:     config.PeeringConfig.GetBool(config.CfgPeeringAcceptAnyConnection),
+			Identity:          identity,
+			RequireEncryption: config.NodeConfig.GetBool(config.CfgNetGossipEncryptionRequired),
+			Reputation: peering.ReputationOptions{
+				BlacklistThreshold:   config.NodeConfig.GetFloat64(config.CfgNetReputationBlacklistThreshold),
+				BlacklistDuration:    time.Duration(config.NodeConfig.GetInt(config.CfgNetReputationBlacklistDurationSeconds)) * time.Second,
+				MaxBlacklistDuration: time.Duration(config.NodeConfig.GetInt(config.CfgNetReputationMaxBlacklistDurationSeconds)) * time.Second,
+			},
+			MaxUploadBytesPerSecond:   config.NodeConfig.GetInt(config.CfgNetGossipMaxUploadBytesPerSecond),
+			MaxDownloadBytesPerSecond: config.NodeConfig.GetInt(config.CfgNetGossipMaxDownloadBytesPerSecond),
+			HistorySize:               config.NodeConfig.GetInt(config.CfgNetPeerHistorySize),
+			InboundLimits: peering.InboundLimitOptions{
+				MaxHandshaking:               config.NodeConfig.GetInt(config.CfgNetInboundLimitsMaxHandshaking),
+				MaxConnectionsPerIPPerMinute: config.NodeConfig.GetInt(config.CfgNetInboundLimitsMaxConnectionsPerIPPerMinute),
+				HandshakeTimeout:             time.Duration(config.NodeConfig.GetInt(config.CfgNetInboundLimitsHandshakeTimeoutSeconds)) * time.Second,
+				MaxPeersPerSubnet:            config.NodeConfig.GetInt(config.CfgNetInboundLimitsMaxPeersPerSubnet),
+			},
 		}, peers...)
+
+		// restore the scores of the known peers
+		if err := manager.LoadScores(config.NodeConfig.GetString(config.CfgNetReputationScoresPath)); err != nil {
+			log.Warnf("couldn't load peer scores: %s", err)
+		}
 	})
 	return manager
 }
@@ -88,6 +130,9 @@ func configure(plugin *node.Plugin) {
 
 	// react to peer config changes
 	configurePeerConfigWatcher()
+
+	// resolve static peers from DNS records and seed lists
+	configurePeerSources()
 }
 
 func configureManagerEventHandlers() {
@@ -124,6 +169,14 @@ func configureManagerEventHandlers() {
 		log.Infof("disconnected %s", p.ID)
 	}))
 
+	manager.Events.PeerPenalized.Attach(events.NewClosure(func(p *peer.Peer, duration time.Duration) {
+		log.Infof("disconnected %s and blacklisted it for %v because of its behavior (score %0.2f)", p.ID, duration, p.Score.Value())
+	}))
+
+	manager.Events.InboundConnectionRejected.Attach(events.NewClosure(func(addr string, reason string) {
+		log.Debugf("rejected inbound connection from %s: %s", addr, reason)
+	}))
+
 	manager.Events.AutopeeredPeerHandshaking.Attach(events.NewClosure(func(p *peer.Peer) {
 		log.Infof("handshaking with autopeered peer %s / %s", p.ID, p.Autopeering.ID())
 	}))
@@ -144,6 +197,7 @@ func configureManagerEventHandlers() {
 func run(_ *node.Plugin) {
 
 	runConfigWatcher()
+	runPeerSources()
 
 	peeringBindAddr := config.NodeConfig.GetString(config.CfgNetGossipBindAddress)
 	daemon.BackgroundWorker("Peering Server", func(shutdownSignal <-chan struct{}) {
@@ -182,6 +236,23 @@ func run(_ *node.Plugin) {
 		}
 	}, shutdown.PriorityPeerReconnecter)
 
+	scoresPath := config.NodeConfig.GetString(config.CfgNetReputationScoresPath)
+	daemon.BackgroundWorker("Peering Reputation", func(shutdownSignal <-chan struct{}) {
+
+		checkScores := func() {
+			manager.CheckScores()
+			if err := manager.StoreScores(scoresPath); err != nil {
+				log.Warnf("couldn't store peer scores: %s", err)
+			}
+		}
+
+		timeutil.Ticker(checkScores, 60*time.Second, shutdownSignal)
+
+		if err := manager.StoreScores(scoresPath); err != nil {
+			log.Warnf("couldn't store peer scores: %s", err)
+		}
+	}, shutdown.PriorityPeerReconnecter)
+
 	if config.NodeConfig.GetInt(config.CfgNetAutopeeringMaxDroppedPacketsPercentage) != 0 {
 		// create a background worker that checks for staled autopeers every minute
 		daemon.BackgroundWorker("Peering StaleCheck", func(shutdownSignal <-chan struct{}) {
@@ -0,0 +1,85 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering"
+	"github.com/gohornet/hornet/pkg/peering/source"
+	"github.com/gohornet/hornet/pkg/shutdown"
+)
+
+var (
+	// keeps the peers of the configured peer sources in sync, nil if no sources are configured.
+	sourceSyncer *source.Syncer
+)
+
+// creates the peer sources from the config.
+func configurePeerSources() {
+	preferIPv6 := config.NodeConfig.GetBool(config.CfgNetPreferIPv6)
+
+	var sources []source.Source
+	for _, addr := range config.NodeConfig.GetStringSlice(config.CfgNetPeerSourcesDNS) {
+		dnsSource, err := source.NewDNSSource(addr, preferIPv6)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sources = append(sources, dnsSource)
+	}
+
+	for _, name := range config.NodeConfig.GetStringSlice(config.CfgNetPeerSourcesSRV) {
+		sources = append(sources, source.NewSRVSource(name, preferIPv6))
+	}
+
+	seedLists := config.NodeConfig.GetStringSlice(config.CfgNetPeerSourcesSeedLists)
+	if len(seedLists) > 0 {
+		publicKey, err := peering.ParsePublicKey(config.NodeConfig.GetString(config.CfgNetPeerSourcesSeedListPublicKey))
+		if err != nil {
+			log.Fatalf("invalid '%s': %s", config.CfgNetPeerSourcesSeedListPublicKey, err)
+		}
+		if publicKey == nil {
+			log.Fatalf("'%s' must be set to verify the seed lists", config.CfgNetPeerSourcesSeedListPublicKey)
+		}
+
+		for _, location := range seedLists {
+			sources = append(sources, source.NewSeedListSource(location, publicKey))
+		}
+	}
+
+	if len(sources) == 0 {
+		return
+	}
+
+	sourceSyncer = source.NewSyncer(manager, sources...)
+}
+
+// syncs the peers of the peer sources.
+func syncPeerSources() {
+	result := sourceSyncer.Sync()
+
+	for _, id := range result.Added {
+		log.Infof("added peer %s from peer sources", id)
+	}
+	for _, id := range result.Removed {
+		log.Infof("removed peer %s as it vanished from the peer sources", id)
+	}
+	for _, err := range result.Errors {
+		log.Warn(err)
+	}
+}
+
+func runPeerSources() {
+	if sourceSyncer == nil {
+		return
+	}
+
+	refreshInterval := time.Duration(config.NodeConfig.GetInt(config.CfgNetPeerSourcesRefreshIntervalSeconds)) * time.Second
+
+	daemon.BackgroundWorker("Peering Sources", func(shutdownSignal <-chan struct{}) {
+		syncPeerSources()
+		timeutil.Ticker(syncPeerSources, refreshInterval, shutdownSignal)
+	}, shutdown.PriorityPeerReconnecter)
+}
@@ -0,0 +1,51 @@
+package prometheus
+
+import (
+	"github.com/iotaledger/hive.go/node"
+	"github.com/prometheus/client_golang/prometheus"
+
+	coordinatorplugin "github.com/gohornet/hornet/plugins/coordinator"
+)
+
+var (
+	coordinatorMerkleKeysCapacity  prometheus.Gauge
+	coordinatorMerkleKeysUsed      prometheus.Gauge
+	coordinatorMerkleKeysRemaining prometheus.Gauge
+)
+
+// registers the metrics of the coordinator, if the node runs the coordinator.
+func configureCoordinator() {
+	if node.IsSkipped(coordinatorplugin.PLUGIN) {
+		return
+	}
+
+	coordinatorMerkleKeysCapacity = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_coordinator_merkle_keys_capacity",
+		Help: "Number of keys of the Merkle tree of the coordinator.",
+	})
+	coordinatorMerkleKeysUsed = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_coordinator_merkle_keys_used",
+		Help: "Number of used keys of the Merkle tree of the coordinator.",
+	})
+	coordinatorMerkleKeysRemaining = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_coordinator_merkle_keys_remaining",
+		Help: "Number of remaining keys of the Merkle tree of the coordinator.",
+	})
+
+	registry.MustRegister(coordinatorMerkleKeysCapacity)
+	registry.MustRegister(coordinatorMerkleKeysUsed)
+	registry.MustRegister(coordinatorMerkleKeysRemaining)
+
+	addCollect(collectCoordinator)
+}
+
+func collectCoordinator() {
+	usage := coordinatorplugin.MerkleKeyUsage()
+	if usage == nil {
+		return
+	}
+
+	coordinatorMerkleKeysCapacity.Set(float64(usage.Capacity))
+	coordinatorMerkleKeysUsed.Set(float64(usage.Used))
+	coordinatorMerkleKeysRemaining.Set(float64(usage.Remaining))
+}
@@ -2,7 +2,9 @@ package prometheus
 
 import (
 	"net"
+	"sync"
 
+	peeringPackage "github.com/gohornet/hornet/pkg/peering"
 	"github.com/gohornet/hornet/plugins/peering"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -21,6 +23,14 @@ var (
 	peersSentHeartbeats              *prometheus.GaugeVec
 	peersDroppedSentPackets          *prometheus.GaugeVec
 	peersConnected                   *prometheus.GaugeVec
+	peersScore                       *prometheus.GaugeVec
+	peersSendQueueSize               *prometheus.GaugeVec
+	peersInboundRejections           *prometheus.CounterVec
+	peersHandshaking                 prometheus.Gauge
+
+	// the inbound rejections which were already added to the counter by their reason.
+	collectedInboundRejections   = make(map[peeringPackage.RejectionReason]uint32)
+	collectedInboundRejectionsMu sync.Mutex
 )
 
 func init() {
@@ -115,6 +125,32 @@ func init() {
 		},
 		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
 	)
+	peersScore = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peers_score",
+			Help: "Reputation score by peer.",
+		},
+		[]string{"address", "port", "domain", "alias", "type", "autopeering_id"},
+	)
+
+	peersSendQueueSize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "iota_peers_send_queue_size",
+			Help: "Number of messages waiting to be sent by peer and priority.",
+		},
+		[]string{"address", "port", "domain", "alias", "type", "autopeering_id", "priority"},
+	)
+	peersInboundRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "iota_peers_inbound_rejections_total",
+			Help: "Number of rejected inbound connections by reason.",
+		},
+		[]string{"reason"},
+	)
+	peersHandshaking = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iota_peers_handshaking",
+		Help: "Number of inbound connections which are handshaking.",
+	})
 
 	registry.MustRegister(peersAllTransactions)
 	registry.MustRegister(peersNewTransactions)
@@ -129,6 +165,10 @@ func init() {
 	registry.MustRegister(peersSentHeartbeats)
 	registry.MustRegister(peersDroppedSentPackets)
 	registry.MustRegister(peersConnected)
+	registry.MustRegister(peersScore)
+	registry.MustRegister(peersSendQueueSize)
+	registry.MustRegister(peersInboundRejections)
+	registry.MustRegister(peersHandshaking)
 
 	addCollect(collectPeers)
 }
@@ -147,6 +187,11 @@ func collectPeers() {
 	peersSentHeartbeats.Reset()
 	peersDroppedSentPackets.Reset()
 	peersConnected.Reset()
+	peersScore.Reset()
+	peersSendQueueSize.Reset()
+
+	collectInboundRejections()
+	peersHandshaking.Set(float64(peering.Manager().HandshakingCount()))
 
 	for _, peer := range peering.Manager().PeerInfos() {
 		address, port, _ := net.SplitHostPort(peer.Address)
@@ -171,9 +216,32 @@ func collectPeers() {
 		peersSentMilestoneRequests.With(labels).Set(float64(peer.NumberOfSentMilestoneReq))
 		peersSentHeartbeats.With(labels).Set(float64(peer.NumberOfSentHeartbeats))
 		peersDroppedSentPackets.With(labels).Set(float64(peer.NumberOfDroppedSentPackets))
+		peersScore.With(labels).Set(peer.Score)
+		for priority, size := range peer.SendQueueSizes {
+			peersSendQueueSize.With(prometheus.Labels{
+				"address":        address,
+				"port":           port,
+				"domain":         peer.Domain,
+				"alias":          peer.Alias,
+				"type":           peer.ConnectionType,
+				"autopeering_id": peer.AutopeeringID,
+				"priority":       priority,
+			}).Set(float64(size))
+		}
 		peersConnected.With(labels).Set(0)
 		if peer.Connected {
 			peersConnected.With(labels).Set(1)
 		}
 	}
 }
+
+// adds the inbound rejections since the last collection to the counter.
+func collectInboundRejections() {
+	collectedInboundRejectionsMu.Lock()
+	defer collectedInboundRejectionsMu.Unlock()
+
+	for reason, count := range peering.Manager().InboundRejections() {
+		peersInboundRejections.WithLabelValues(string(reason)).Add(float64(count - collectedInboundRejections[reason]))
+		collectedInboundRejections[reason] = count
+	}
+}
@@ -38,6 +38,8 @@ func configure(plugin *node.Plugin) {
 	if config.NodeConfig.GetBool(config.CfgPrometheusProcessMetrics) {
 		registry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
 	}
+
+	configureCoordinator()
 }
 
 func addCollect(collect func()) {
@@ -15,12 +15,26 @@ import (
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/spentaddresses"
 	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
 
 func loadSpentAddresses(filePathSpent string) (int, error) {
 	log.Infof("Importing initial spent addresses from %v", filePathSpent)
 
+	if spentaddresses.IsExportFile(filePathSpent) {
+		// the file was created by "exportSpentAddresses" and contains a Merkle root that gets verified during import
+		header, _, err := importSpentAddressesWithoutLocking(filePathSpent, nil)
+		if err != nil {
+			return 0, err
+		}
+
+		log.Infof("Finished loading spent addresses from %v", filePathSpent)
+
+		// all addresses of the file count, also if they were already known from a previous file
+		return int(header.SpentAddressesCount), nil
+	}
+
 	spentAddressesCount := 0
 
 	spentFile, err := os.OpenFile(filePathSpent, os.O_RDONLY, 0666)
@@ -151,28 +151,6 @@ func getMilestoneApprovees(milestoneIndex milestone.Index, msTailTxHash hornet.H
 	return approvees, nil
 }
 
-func shouldTakeSnapshot(solidMilestoneIndex milestone.Index) bool {
-
-	snapshotInfo := tangle.GetSnapshotInfo()
-	if snapshotInfo == nil {
-		log.Panic("No snapshotInfo found!")
-	}
-
-	var snapshotInterval milestone.Index
-	if tangle.IsNodeSynced() {
-		snapshotInterval = snapshotIntervalSynced
-	} else {
-		snapshotInterval = snapshotIntervalUnsynced
-	}
-
-	if (solidMilestoneIndex < snapshotDepth+snapshotInterval) || (solidMilestoneIndex-snapshotDepth) < snapshotInfo.PruningIndex+1+SolidEntryPointCheckThresholdPast {
-		// Not enough history to calculate solid entry points
-		return false
-	}
-
-	return solidMilestoneIndex-(snapshotDepth+snapshotInterval) >= snapshotInfo.SnapshotIndex
-}
-
 func getSolidEntryPoints(targetIndex milestone.Index, abortSignal <-chan struct{}) (map[string]milestone.Index, error) {
 
 	solidEntryPoints := make(map[string]milestone.Index)
@@ -573,19 +551,24 @@ func (ls *localSnapshotHeader) WriteToBuffer(buf io.Writer, abortSignal <-chan s
 	return nil
 }
 
-func LoadSnapshotFromFile(filePath string) error {
-	log.Info("Loading snapshot file...")
+// localSnapshotFileHeader is the fixed size header at the beginning of a local snapshot file.
+type localSnapshotFileHeader struct {
+	msHash                hornet.Hash
+	msIndex               milestone.Index
+	msTimestamp           int64
+	solidEntryPointsCount int32
+	seenMilestonesCount   int32
+	ledgerEntriesCount    int32
+	spentAddrsCount       int32
+}
 
-	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// readLocalSnapshotFileHeader checks the file version and reads the header of a local snapshot file.
+func readLocalSnapshotFileHeader(r io.Reader) (*localSnapshotFileHeader, error) {
 
 	// check file version
 	var fileVersion byte
-	if err := binary.Read(file, binary.LittleEndian, &fileVersion); err != nil {
-		return err
+	if err := binary.Read(r, binary.LittleEndian, &fileVersion); err != nil {
+		return nil, err
 	}
 
 	var supported bool
@@ -596,45 +579,83 @@ func LoadSnapshotFromFile(filePath string) error {
 		}
 	}
 	if !supported {
-		return errors.Wrapf(ErrUnsupportedLSFileVersion, "local snapshot file version is %d but this HORNET version only supports %v", fileVersion, SupportedLocalSnapshotFileVersions)
+		return nil, errors.Wrapf(ErrUnsupportedLSFileVersion, "local snapshot file version is %d but this HORNET version only supports %v", fileVersion, SupportedLocalSnapshotFileVersions)
 	}
 
-	msHash := make(hornet.Hash, 49)
-	if _, err := file.Read(msHash); err != nil {
-		return err
+	header := &localSnapshotFileHeader{
+		msHash: make(hornet.Hash, 49),
 	}
 
-	tangle.WriteLockSolidEntryPoints()
-	tangle.ResetSolidEntryPoints()
+	if _, err := io.ReadFull(r, header.msHash); err != nil {
+		return nil, err
+	}
 
 	var msIndex int32
-	var msTimestamp int64
-	var solidEntryPointsCount, seenMilestonesCount, ledgerEntriesCount, spentAddrsCount int32
+	if err := binary.Read(r, binary.LittleEndian, &msIndex); err != nil {
+		return nil, err
+	}
+	header.msIndex = milestone.Index(msIndex)
 
-	if err := binary.Read(file, binary.LittleEndian, &msIndex); err != nil {
-		return err
+	if err := binary.Read(r, binary.LittleEndian, &header.msTimestamp); err != nil {
+		return nil, err
 	}
 
-	if err := binary.Read(file, binary.LittleEndian, &msTimestamp); err != nil {
-		return err
+	if err := binary.Read(r, binary.LittleEndian, &header.solidEntryPointsCount); err != nil {
+		return nil, err
 	}
 
-	if err := binary.Read(file, binary.LittleEndian, &solidEntryPointsCount); err != nil {
-		return err
+	if err := binary.Read(r, binary.LittleEndian, &header.seenMilestonesCount); err != nil {
+		return nil, err
 	}
 
-	if err := binary.Read(file, binary.LittleEndian, &seenMilestonesCount); err != nil {
-		return err
+	if err := binary.Read(r, binary.LittleEndian, &header.ledgerEntriesCount); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &header.spentAddrsCount); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// readLocalSnapshotFileHeaderFromFile reads the header of the given local snapshot file.
+func readLocalSnapshotFileHeaderFromFile(filePath string) (*localSnapshotFileHeader, error) {
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
+
+	return readLocalSnapshotFileHeader(file)
+}
 
-	if err := binary.Read(file, binary.LittleEndian, &ledgerEntriesCount); err != nil {
+func LoadSnapshotFromFile(filePath string) error {
+	log.Info("Loading snapshot file...")
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
 		return err
 	}
+	defer file.Close()
 
-	if err := binary.Read(file, binary.LittleEndian, &spentAddrsCount); err != nil {
+	header, err := readLocalSnapshotFileHeader(file)
+	if err != nil {
 		return err
 	}
 
+	msHash := header.msHash
+	msIndex := header.msIndex
+	msTimestamp := header.msTimestamp
+	solidEntryPointsCount := header.solidEntryPointsCount
+	seenMilestonesCount := header.seenMilestonesCount
+	ledgerEntriesCount := header.ledgerEntriesCount
+	spentAddrsCount := header.spentAddrsCount
+
+	tangle.WriteLockSolidEntryPoints()
+	tangle.ResetSolidEntryPoints()
+
 	coordinatorAddress := hornet.HashFromAddressTrytes(config.NodeConfig.GetString(config.CfgCoordinatorAddress))
 	tangle.SetSnapshotMilestone(coordinatorAddress, msHash, milestone.Index(msIndex), milestone.Index(msIndex), milestone.Index(msIndex), msTimestamp, spentAddrsCount != 0 && config.NodeConfig.GetBool("spentAddresses.enabled"))
 	tangle.SolidEntryPointsAdd(msHash, milestone.Index(msIndex))
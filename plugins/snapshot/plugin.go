@@ -21,6 +21,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/whiteflag"
 	"github.com/gohornet/hornet/plugins/gossip"
 	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
@@ -83,6 +84,11 @@ func configure(plugin *node.Plugin) {
 		pruningDelay = pruningDelayMin
 	}
 
+	configureSnapshotPolicy()
+	configurePruningBySize()
+	configureRetentionFilter()
+	configurePruningThrottle()
+
 	gossip.AddRequestBackpressureSignal(isSnapshottingOrPruning)
 
 	snapshotInfo := tangle.GetSnapshotInfo()
@@ -171,11 +177,23 @@ func run(_ *node.Plugin) {
 		}
 	})
 
+	onMilestoneConfirmed := events.NewClosure(func(confirmation *whiteflag.Confirmation) {
+		if isPruningBySizeEnabled() {
+			recordMilestoneStats(confirmation)
+		}
+	})
+
 	daemon.BackgroundWorker("LocalSnapshots", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting LocalSnapshots ... done")
 
 		tanglePlugin.Events.SolidMilestoneIndexChanged.Attach(onSolidMilestoneIndexChanged)
 		defer tanglePlugin.Events.SolidMilestoneIndexChanged.Detach(onSolidMilestoneIndexChanged)
+		tanglePlugin.Events.MilestoneConfirmed.Attach(onMilestoneConfirmed)
+		defer tanglePlugin.Events.MilestoneConfirmed.Detach(onMilestoneConfirmed)
+
+		if pruningEnabled {
+			resumeInterruptedPruning(shutdownSignal)
+		}
 
 		for {
 			select {
@@ -187,26 +205,49 @@ func run(_ *node.Plugin) {
 			case solidMilestoneIndex := <-newSolidMilestoneSignal:
 				localSnapshotLock.Lock()
 
-				if shouldTakeSnapshot(solidMilestoneIndex) {
+				if targetIndex, takeSnapshot := shouldTakeSnapshot(solidMilestoneIndex); takeSnapshot {
 					localSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
-					if err := createLocalSnapshotWithoutLocking(solidMilestoneIndex-snapshotDepth, localSnapshotPath, true, shutdownSignal); err != nil {
+					if err := createLocalSnapshotWithoutLocking(targetIndex, localSnapshotPath, true, shutdownSignal); err != nil {
 						if errors.Is(err, ErrCritical) {
 							log.Panic(errors.Wrap(ErrSnapshotCreationFailed, err.Error()))
 						}
 						log.Warn(errors.Wrap(ErrSnapshotCreationFailed, err.Error()))
+					} else if err := rotateSnapshotFiles(localSnapshotPath, targetIndex); err != nil {
+						log.Warnf("rotating local snapshot files failed: %v", err)
 					}
 				}
 
+				createPendingPinnedSnapshots(solidMilestoneIndex, shutdownSignal)
+
 				if pruningEnabled {
-					if solidMilestoneIndex <= pruningDelay {
-						// Not enough history
-						localSnapshotLock.Unlock()
-						continue
+					var pruningTargetIndex milestone.Index
+					if isPruningBySizeEnabled() {
+						if !milestoneStatsLoaded {
+							loadMilestoneStats(tangle.GetSnapshotInfo().PruningIndex, solidMilestoneIndex, shutdownSignal)
+						}
+
+						targetIndex, pruningNeeded := getPruningTargetIndexBySize(solidMilestoneIndex)
+						if !pruningNeeded {
+							localSnapshotLock.Unlock()
+							continue
+						}
+						pruningTargetIndex = targetIndex
+					} else {
+						if solidMilestoneIndex <= pruningDelay {
+							// Not enough history
+							localSnapshotLock.Unlock()
+							continue
+						}
+						pruningTargetIndex = solidMilestoneIndex - pruningDelay
 					}
 
-					if err := pruneDatabase(solidMilestoneIndex-pruningDelay, shutdownSignal); err != nil {
+					if err := pruneDatabase(pruningTargetIndex, shutdownSignal); err != nil {
 						log.Debugf("pruning aborted: %v", err.Error())
 					}
+
+					if isPruningBySizeEnabled() {
+						cleanupMilestoneStats(tangle.GetSnapshotInfo().PruningIndex)
+					}
 				}
 
 				localSnapshotLock.Unlock()
@@ -215,6 +256,35 @@ func run(_ *node.Plugin) {
 	}, shutdown.PriorityLocalSnapshots)
 }
 
+// resumeInterruptedPruning finishes a pruning that was interrupted by a shutdown of the node.
+func resumeInterruptedPruning(abortSignal <-chan struct{}) {
+	localSnapshotLock.Lock()
+	defer localSnapshotLock.Unlock()
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return
+	}
+
+	if err := removeStalePruningState(snapshotInfo); err != nil {
+		log.Warn(err)
+		return
+	}
+
+	pruningState, err := getInterruptedPruningState(snapshotInfo)
+	if err != nil {
+		log.Warn(err)
+		return
+	}
+	if pruningState == nil {
+		return
+	}
+
+	if err := pruneDatabase(pruningState.TargetIndex, abortSignal); err != nil {
+		log.Debugf("pruning aborted: %v", err.Error())
+	}
+}
+
 func PruneDatabaseByDepth(depth milestone.Index) error {
 	localSnapshotLock.Lock()
 	defer localSnapshotLock.Unlock()
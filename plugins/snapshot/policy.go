@@ -0,0 +1,446 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+const (
+	// local snapshots are created every "intervalSynced" or "intervalUnsynced" milestones
+	ScheduleModeInterval = "interval"
+	// local snapshots are created at target indexes which are a multiple of "milestoneModulo"
+	ScheduleModeModulo = "modulo"
+	// local snapshots are created at the times given by a cron-like schedule
+	ScheduleModeCron = "cron"
+
+	// the latest local snapshot file, which is used to bootstrap the node
+	SnapshotFileTypeLatest = "latest"
+	// an older local snapshot file that is kept because of the retention policy
+	SnapshotFileTypeRotated = "rotated"
+	// a named local snapshot file at a fixed milestone index
+	SnapshotFileTypePinned = "pinned"
+	// a local snapshot file created by the "createSnapshotFile" API call
+	SnapshotFileTypeManual = "manual"
+
+	// the amount of milestones after which the creation of a failed pinned snapshot is retried
+	PinnedSnapshotRetryInterval = 10
+)
+
+var (
+	ErrInvalidSnapshotSchedule = errors.New("invalid snapshot schedule")
+	ErrInvalidPinnedSnapshot   = errors.New("invalid pinned snapshot")
+
+	pinnedSnapshotNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+
+	scheduleMode            string
+	scheduleMilestoneModulo milestone.Index
+	scheduleCron            *utils.CronSchedule
+	nextCronSnapshotTime    time.Time
+
+	retentionKeepFiles int
+
+	// pinned snapshots by milestone index
+	pinnedSnapshots map[milestone.Index]string
+	// pinned snapshots which were not created yet by milestone index
+	pendingPinnedSnapshots map[milestone.Index]*pendingPinnedSnapshot
+)
+
+// pendingPinnedSnapshot is a pinned snapshot which was not created yet.
+type pendingPinnedSnapshot struct {
+	// the name of the pinned snapshot
+	name string
+	// the solid milestone index at which the creation is retried after it failed
+	retryIndex milestone.Index
+}
+
+// SnapshotFileInfo holds information about a local snapshot file.
+type SnapshotFileInfo struct {
+	// the type of the snapshot file
+	Type string
+	// the name of a pinned snapshot
+	Name string
+	// the path to the snapshot file
+	FilePath string
+	// the milestone index of the snapshot
+	MilestoneIndex milestone.Index
+	// the timestamp of the snapshot milestone
+	MilestoneTimestamp int64
+	// the size of the snapshot file in bytes
+	FileSize int64
+	// the modification time of the snapshot file
+	ModTime time.Time
+}
+
+func configureSnapshotPolicy() {
+
+	scheduleMode = strings.ToLower(config.NodeConfig.GetString(config.CfgLocalSnapshotsScheduleMode))
+	switch scheduleMode {
+	case ScheduleModeInterval:
+
+	case ScheduleModeModulo:
+		scheduleMilestoneModulo = milestone.Index(config.NodeConfig.GetInt(config.CfgLocalSnapshotsScheduleMilestoneModulo))
+		if scheduleMilestoneModulo == 0 {
+			log.Fatal(errors.Wrapf(ErrInvalidSnapshotSchedule, "'%s' must be greater than 0", config.CfgLocalSnapshotsScheduleMilestoneModulo))
+		}
+
+	case ScheduleModeCron:
+		var err error
+		if scheduleCron, err = utils.ParseCronSchedule(config.NodeConfig.GetString(config.CfgLocalSnapshotsScheduleCron)); err != nil {
+			log.Fatal(errors.Wrapf(ErrInvalidSnapshotSchedule, "'%s': %v", config.CfgLocalSnapshotsScheduleCron, err))
+		}
+
+		nextCronSnapshotTime = scheduleCron.Next(time.Now())
+		if nextCronSnapshotTime.IsZero() {
+			log.Warnf("cron schedule '%s' never matches, no local snapshots will be created", config.NodeConfig.GetString(config.CfgLocalSnapshotsScheduleCron))
+		}
+
+	default:
+		log.Fatal(errors.Wrapf(ErrInvalidSnapshotSchedule, "invalid schedule mode under config option '%s': %s", config.CfgLocalSnapshotsScheduleMode, scheduleMode))
+	}
+
+	retentionKeepFiles = config.NodeConfig.GetInt(config.CfgLocalSnapshotsRetentionKeepFiles)
+	if retentionKeepFiles < 0 {
+		retentionKeepFiles = 0
+	}
+
+	var pinnedSnapshotsConfig []config.PinnedSnapshotConfig
+	if err := config.NodeConfig.UnmarshalKey(config.CfgLocalSnapshotsPinned, &pinnedSnapshotsConfig); err != nil {
+		log.Fatal(errors.Wrapf(ErrInvalidPinnedSnapshot, "'%s': %v", config.CfgLocalSnapshotsPinned, err))
+	}
+
+	localSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
+
+	pinnedSnapshots = make(map[milestone.Index]string)
+	pendingPinnedSnapshots = make(map[milestone.Index]*pendingPinnedSnapshot)
+	for _, pinned := range pinnedSnapshotsConfig {
+		if !pinnedSnapshotNameRegex.MatchString(pinned.Name) {
+			log.Fatal(errors.Wrapf(ErrInvalidPinnedSnapshot, "name '%s' may only contain letters, digits, '_' and '-'", pinned.Name))
+		}
+		if pinned.Index <= 0 {
+			log.Fatal(errors.Wrapf(ErrInvalidPinnedSnapshot, "index of '%s' must be greater than 0", pinned.Name))
+		}
+
+		index := milestone.Index(pinned.Index)
+		if name, exists := pinnedSnapshots[index]; exists {
+			log.Fatal(errors.Wrapf(ErrInvalidPinnedSnapshot, "'%s' and '%s' are pinned at the same index %d", name, pinned.Name, index))
+		}
+		pinnedSnapshots[index] = pinned.Name
+
+		if _, err := os.Stat(pinnedSnapshotFilePath(localSnapshotPath, pinned.Name, index)); os.IsNotExist(err) {
+			pendingPinnedSnapshots[index] = &pendingPinnedSnapshot{name: pinned.Name}
+		}
+	}
+}
+
+// shouldTakeSnapshot returns the target index of the next local snapshot according to the schedule,
+// and whether a local snapshot should be taken at all.
+func shouldTakeSnapshot(solidMilestoneIndex milestone.Index) (milestone.Index, bool) {
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		log.Panic("No snapshotInfo found!")
+	}
+
+	return shouldTakeSnapshotAt(solidMilestoneIndex, snapshotInfo, tangle.IsNodeSynced(), time.Now())
+}
+
+// shouldTakeSnapshotAt returns the target index of the next local snapshot according to the schedule
+// for the given snapshot info, sync status and time.
+func shouldTakeSnapshotAt(solidMilestoneIndex milestone.Index, snapshotInfo *tangle.SnapshotInfo, synced bool, now time.Time) (milestone.Index, bool) {
+
+	if solidMilestoneIndex < snapshotDepth {
+		// Not enough history
+		return 0, false
+	}
+
+	targetIndex := solidMilestoneIndex - snapshotDepth
+
+	switch scheduleMode {
+	case ScheduleModeModulo:
+		targetIndex = (targetIndex / scheduleMilestoneModulo) * scheduleMilestoneModulo
+		if targetIndex <= snapshotInfo.SnapshotIndex {
+			return 0, false
+		}
+
+	case ScheduleModeCron:
+		if nextCronSnapshotTime.IsZero() || now.Before(nextCronSnapshotTime) || targetIndex <= snapshotInfo.SnapshotIndex {
+			return 0, false
+		}
+
+	default:
+		var snapshotInterval milestone.Index
+		if synced {
+			snapshotInterval = snapshotIntervalSynced
+		} else {
+			snapshotInterval = snapshotIntervalUnsynced
+		}
+
+		if (solidMilestoneIndex < snapshotDepth+snapshotInterval) || solidMilestoneIndex-(snapshotDepth+snapshotInterval) < snapshotInfo.SnapshotIndex {
+			return 0, false
+		}
+	}
+
+	if targetIndex < snapshotInfo.PruningIndex+1+SolidEntryPointCheckThresholdPast {
+		// Not enough history to calculate solid entry points
+		return 0, false
+	}
+
+	if scheduleMode == ScheduleModeCron {
+		// the schedule only advances if a snapshot is taken, so missed times are caught up as soon as there is enough history
+		nextCronSnapshotTime = scheduleCron.Next(now)
+	}
+
+	return targetIndex, true
+}
+
+// rotatedSnapshotFilePath returns the path of an older local snapshot file that is kept because of the retention policy.
+func rotatedSnapshotFilePath(localSnapshotPath string, index milestone.Index) string {
+	ext := filepath.Ext(localSnapshotPath)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(localSnapshotPath, ext), index, ext)
+}
+
+// pinnedSnapshotFilePath returns the path of a named local snapshot file at a fixed milestone index.
+func pinnedSnapshotFilePath(localSnapshotPath string, name string, index milestone.Index) string {
+	ext := filepath.Ext(localSnapshotPath)
+	return fmt.Sprintf("%s-pinned-%s-%d%s", strings.TrimSuffix(localSnapshotPath, ext), name, index, ext)
+}
+
+// snapshotFileRegexes returns the regexes to match rotated and pinned snapshot file names.
+func snapshotFileRegexes(localSnapshotPath string) (rotated *regexp.Regexp, pinned *regexp.Regexp) {
+	base := filepath.Base(localSnapshotPath)
+	ext := filepath.Ext(base)
+	prefix := regexp.QuoteMeta(strings.TrimSuffix(base, ext))
+	suffix := regexp.QuoteMeta(ext)
+
+	return regexp.MustCompile(fmt.Sprintf(`^%s-(\d+)%s$`, prefix, suffix)),
+		regexp.MustCompile(fmt.Sprintf(`^%s-pinned-(.+)-(\d+)%s$`, prefix, suffix))
+}
+
+// copyFile copies the content of the source file to the destination file.
+func copyFile(srcPath string, dstPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dstPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return err
+	}
+
+	return dst.Close()
+}
+
+// rotateSnapshotFiles keeps a copy of the latest local snapshot file and removes
+// the oldest rotated snapshot files which exceed the configured retention.
+func rotateSnapshotFiles(localSnapshotPath string, targetIndex milestone.Index) error {
+
+	if retentionKeepFiles == 0 {
+		// only the latest snapshot file is kept, it gets overwritten by the next snapshot
+		return nil
+	}
+
+	rotatedPath := rotatedSnapshotFilePath(localSnapshotPath, targetIndex)
+	os.Remove(rotatedPath)
+
+	// the latest snapshot file is replaced by renaming, so a hard link keeps the content of this snapshot
+	if err := os.Link(localSnapshotPath, rotatedPath); err != nil {
+		if err := copyFile(localSnapshotPath, rotatedPath); err != nil {
+			return err
+		}
+	}
+
+	rotatedRegex, _ := snapshotFileRegexes(localSnapshotPath)
+
+	files, err := ioutil.ReadDir(filepath.Dir(localSnapshotPath))
+	if err != nil {
+		return err
+	}
+
+	var rotatedIndexes []milestone.Index
+	for _, file := range files {
+		matches := rotatedRegex.FindStringSubmatch(file.Name())
+		if matches == nil {
+			continue
+		}
+
+		index, err := strconv.ParseUint(matches[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		rotatedIndexes = append(rotatedIndexes, milestone.Index(index))
+	}
+
+	if len(rotatedIndexes) <= retentionKeepFiles {
+		return nil
+	}
+
+	sort.Slice(rotatedIndexes, func(i, j int) bool { return rotatedIndexes[i] < rotatedIndexes[j] })
+
+	for _, index := range rotatedIndexes[:len(rotatedIndexes)-retentionKeepFiles] {
+		log.Infof("removing rotated local snapshot file for index %d", index)
+		if err := os.Remove(rotatedSnapshotFilePath(localSnapshotPath, index)); err != nil {
+			log.Warnf("removing rotated local snapshot file for index %d failed: %v", index, err)
+		}
+	}
+
+	return nil
+}
+
+// duePinnedSnapshots returns the indexes of the pending pinned snapshots which can be created at the given solid milestone index.
+// Pending pinned snapshots whose history was already pruned are dropped.
+func duePinnedSnapshots(solidMilestoneIndex milestone.Index, pruningIndex milestone.Index) []milestone.Index {
+
+	var due []milestone.Index
+	for index, pending := range pendingPinnedSnapshots {
+		if index < pruningIndex+1+SolidEntryPointCheckThresholdPast {
+			// the history of the pinned index is not available anymore, it can't be created at all
+			log.Error(errors.Wrapf(ErrSnapshotCreationFailed, "pinned snapshot '%s' at index %d: not enough history, pruning index is %d", pending.name, index, pruningIndex))
+			delete(pendingPinnedSnapshots, index)
+			continue
+		}
+
+		if solidMilestoneIndex < index+SolidEntryPointCheckThresholdFuture {
+			// not enough history yet
+			continue
+		}
+
+		if solidMilestoneIndex < pending.retryIndex {
+			// the creation failed before, wait until it is retried
+			continue
+		}
+
+		due = append(due, index)
+	}
+
+	sort.Slice(due, func(i, j int) bool { return due[i] < due[j] })
+
+	return due
+}
+
+// createPendingPinnedSnapshots creates the pinned snapshot files as soon as there is enough history.
+// The creation of failed pinned snapshots is retried every PinnedSnapshotRetryInterval milestones.
+func createPendingPinnedSnapshots(solidMilestoneIndex milestone.Index, abortSignal <-chan struct{}) {
+
+	localSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
+
+	for _, index := range duePinnedSnapshots(solidMilestoneIndex, tangle.GetSnapshotInfo().PruningIndex) {
+		pending := pendingPinnedSnapshots[index]
+
+		if err := createLocalSnapshotWithoutLocking(index, pinnedSnapshotFilePath(localSnapshotPath, pending.name, index), false, abortSignal); err != nil {
+			if errors.Is(err, ErrSnapshotCreationWasAborted) || errors.Is(err, tangle.ErrOperationAborted) {
+				return
+			}
+
+			if errors.Is(err, ErrCritical) {
+				log.Panic(errors.Wrapf(ErrSnapshotCreationFailed, "pinned snapshot '%s': %v", pending.name, err))
+			}
+
+			// the pinned snapshot stays pending and pruning is still limited by it
+			pending.retryIndex = solidMilestoneIndex + PinnedSnapshotRetryInterval
+			log.Warnf("creating pinned snapshot '%s' failed, retrying at milestone %d: %v", pending.name, pending.retryIndex, err)
+			continue
+		}
+
+		delete(pendingPinnedSnapshots, index)
+	}
+}
+
+// getPinnedSnapshotsPruningLimit returns the maximum pruning target index that still allows
+// to create all pending pinned snapshots, and whether there is such a limit.
+func getPinnedSnapshotsPruningLimit() (milestone.Index, bool) {
+
+	var limit milestone.Index
+	limited := false
+
+	for index := range pendingPinnedSnapshots {
+		if index <= SolidEntryPointCheckThresholdPast {
+			continue
+		}
+
+		if indexLimit := index - SolidEntryPointCheckThresholdPast - 1; !limited || indexLimit < limit {
+			limit = indexLimit
+			limited = true
+		}
+	}
+
+	return limit, limited
+}
+
+// ListSnapshots returns information about all local snapshot files in the snapshot directory.
+func ListSnapshots() ([]*SnapshotFileInfo, error) {
+
+	localSnapshotPath := config.NodeConfig.GetString(config.CfgLocalSnapshotsPath)
+	snapshotDir := filepath.Dir(localSnapshotPath)
+
+	files, err := ioutil.ReadDir(snapshotDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*SnapshotFileInfo{}, nil
+		}
+		return nil, err
+	}
+
+	rotatedRegex, pinnedRegex := snapshotFileRegexes(localSnapshotPath)
+
+	snapshots := []*SnapshotFileInfo{}
+	for _, file := range files {
+		if file.IsDir() || strings.HasSuffix(file.Name(), "_tmp") {
+			continue
+		}
+
+		filePath := filepath.Join(snapshotDir, file.Name())
+
+		header, err := readLocalSnapshotFileHeaderFromFile(filePath)
+		if err != nil {
+			// not a local snapshot file
+			continue
+		}
+
+		info := &SnapshotFileInfo{
+			Type:               SnapshotFileTypeManual,
+			FilePath:           filePath,
+			MilestoneIndex:     header.msIndex,
+			MilestoneTimestamp: header.msTimestamp,
+			FileSize:           file.Size(),
+			ModTime:            file.ModTime(),
+		}
+
+		switch {
+		case file.Name() == filepath.Base(localSnapshotPath):
+			info.Type = SnapshotFileTypeLatest
+		case rotatedRegex.MatchString(file.Name()):
+			info.Type = SnapshotFileTypeRotated
+		case pinnedRegex.MatchString(file.Name()):
+			info.Type = SnapshotFileTypePinned
+			info.Name = pinnedRegex.FindStringSubmatch(file.Name())[1]
+		}
+
+		snapshots = append(snapshots, info)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].MilestoneIndex < snapshots[j].MilestoneIndex })
+
+	return snapshots, nil
+}
@@ -0,0 +1,190 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/hive.go/logger"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+func init() {
+	log = logger.NewNopLogger()
+}
+
+func TestShouldTakeSnapshotInterval(t *testing.T) {
+	scheduleMode = ScheduleModeInterval
+	snapshotDepth = 50
+	snapshotIntervalSynced = 50
+	snapshotIntervalUnsynced = 1000
+
+	snapshotInfo := &tangle.SnapshotInfo{SnapshotIndex: 100}
+
+	// not enough history
+	_, take := shouldTakeSnapshotAt(40, snapshotInfo, true, time.Now())
+	require.False(t, take)
+
+	_, take = shouldTakeSnapshotAt(199, snapshotInfo, true, time.Now())
+	require.False(t, take)
+
+	targetIndex, take := shouldTakeSnapshotAt(200, snapshotInfo, true, time.Now())
+	require.True(t, take)
+	require.Equal(t, milestone.Index(150), targetIndex)
+
+	// unsynced nodes use the larger interval
+	_, take = shouldTakeSnapshotAt(200, snapshotInfo, false, time.Now())
+	require.False(t, take)
+
+	// not enough history to calculate the solid entry points
+	snapshotInfo.PruningIndex = 150
+	_, take = shouldTakeSnapshotAt(200, snapshotInfo, true, time.Now())
+	require.False(t, take)
+}
+
+func TestShouldTakeSnapshotModulo(t *testing.T) {
+	scheduleMode = ScheduleModeModulo
+	scheduleMilestoneModulo = 100
+	snapshotDepth = 50
+
+	snapshotInfo := &tangle.SnapshotInfo{SnapshotIndex: 100}
+
+	_, take := shouldTakeSnapshotAt(249, snapshotInfo, true, time.Now())
+	require.False(t, take)
+
+	targetIndex, take := shouldTakeSnapshotAt(250, snapshotInfo, true, time.Now())
+	require.True(t, take)
+	require.Equal(t, milestone.Index(200), targetIndex)
+
+	targetIndex, take = shouldTakeSnapshotAt(399, snapshotInfo, true, time.Now())
+	require.True(t, take)
+	require.Equal(t, milestone.Index(300), targetIndex)
+}
+
+func TestShouldTakeSnapshotCron(t *testing.T) {
+	var err error
+	scheduleMode = ScheduleModeCron
+	scheduleCron, err = utils.ParseCronSchedule("0 0 * * *")
+	require.NoError(t, err)
+	snapshotDepth = 50
+
+	now := time.Date(2020, time.November, 15, 10, 0, 0, 0, time.UTC)
+	nextCronSnapshotTime = scheduleCron.Next(now)
+
+	snapshotInfo := &tangle.SnapshotInfo{SnapshotIndex: 100}
+
+	// not due yet
+	_, take := shouldTakeSnapshotAt(1000, snapshotInfo, true, now)
+	require.False(t, take)
+
+	// due, but not enough history
+	now = now.Add(14 * time.Hour)
+	_, take = shouldTakeSnapshotAt(150, snapshotInfo, true, now)
+	require.False(t, take)
+	require.Equal(t, time.Date(2020, time.November, 16, 0, 0, 0, 0, time.UTC), nextCronSnapshotTime)
+
+	// the missed time is caught up and the schedule advances
+	targetIndex, take := shouldTakeSnapshotAt(1000, snapshotInfo, true, now)
+	require.True(t, take)
+	require.Equal(t, milestone.Index(950), targetIndex)
+	require.Equal(t, time.Date(2020, time.November, 17, 0, 0, 0, 0, time.UTC), nextCronSnapshotTime)
+
+	_, take = shouldTakeSnapshotAt(1001, snapshotInfo, true, now)
+	require.False(t, take)
+}
+
+func TestRotateSnapshotFiles(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	localSnapshotPath := filepath.Join(tempDir, "export.bin")
+	retentionKeepFiles = 2
+
+	for index := milestone.Index(1); index <= 4; index++ {
+		// the latest snapshot file is replaced by renaming
+		require.NoError(t, ioutil.WriteFile(localSnapshotPath+"_tmp", []byte{byte(index)}, 0660))
+		require.NoError(t, os.Rename(localSnapshotPath+"_tmp", localSnapshotPath))
+		require.NoError(t, rotateSnapshotFiles(localSnapshotPath, index))
+	}
+
+	files, err := ioutil.ReadDir(tempDir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	require.ElementsMatch(t, []string{"export.bin", "export-3.bin", "export-4.bin"}, names)
+
+	for _, index := range []milestone.Index{3, 4} {
+		data, err := ioutil.ReadFile(rotatedSnapshotFilePath(localSnapshotPath, index))
+		require.NoError(t, err)
+		require.Equal(t, []byte{byte(index)}, data)
+	}
+
+	// pinned snapshot files are not affected by the retention
+	pinnedPath := pinnedSnapshotFilePath(localSnapshotPath, "genesis", 1)
+	require.NoError(t, ioutil.WriteFile(pinnedPath, []byte{1}, 0660))
+
+	retentionKeepFiles = 1
+	require.NoError(t, rotateSnapshotFiles(localSnapshotPath, 5))
+
+	for _, path := range []string{pinnedPath, rotatedSnapshotFilePath(localSnapshotPath, 5)} {
+		_, err = os.Stat(path)
+		require.NoError(t, err)
+	}
+	for _, index := range []milestone.Index{3, 4} {
+		_, err = os.Stat(rotatedSnapshotFilePath(localSnapshotPath, index))
+		require.True(t, os.IsNotExist(err))
+	}
+
+	// without retention the rotated files are kept as they are
+	retentionKeepFiles = 0
+	require.NoError(t, rotateSnapshotFiles(localSnapshotPath, 6))
+	_, err = os.Stat(rotatedSnapshotFilePath(localSnapshotPath, 6))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestPinnedSnapshots(t *testing.T) {
+	pendingPinnedSnapshots = map[milestone.Index]*pendingPinnedSnapshot{
+		100: {name: "pruned"},
+		500: {name: "first"},
+		800: {name: "second"},
+	}
+
+	// the pruned pinned snapshot is dropped, the others limit pruning
+	require.Empty(t, duePinnedSnapshots(400, 50))
+	require.Len(t, pendingPinnedSnapshots, 2)
+
+	limit, limited := getPinnedSnapshotsPruningLimit()
+	require.True(t, limited)
+	require.Equal(t, milestone.Index(500-SolidEntryPointCheckThresholdPast-1), limit)
+
+	require.Equal(t, []milestone.Index{500}, duePinnedSnapshots(500+SolidEntryPointCheckThresholdFuture, 50))
+	require.Equal(t, []milestone.Index{500, 800}, duePinnedSnapshots(800+SolidEntryPointCheckThresholdFuture, 50))
+
+	// a failed pinned snapshot stays pending and is retried later
+	retryIndex := milestone.Index(800 + SolidEntryPointCheckThresholdFuture + PinnedSnapshotRetryInterval)
+	pendingPinnedSnapshots[500].retryIndex = retryIndex
+
+	require.Equal(t, []milestone.Index{800}, duePinnedSnapshots(retryIndex-1, 50))
+	require.Equal(t, []milestone.Index{500, 800}, duePinnedSnapshots(retryIndex, 50))
+
+	limit, limited = getPinnedSnapshotsPruningLimit()
+	require.True(t, limited)
+	require.Equal(t, milestone.Index(500-SolidEntryPointCheckThresholdPast-1), limit)
+
+	delete(pendingPinnedSnapshots, 500)
+	delete(pendingPinnedSnapshots, 800)
+
+	_, limited = getPinnedSnapshotsPruningLimit()
+	require.False(t, limited)
+}
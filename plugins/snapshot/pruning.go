@@ -63,13 +63,14 @@ loopOverUnconfirmed:
 		txsToCheckMap[string(txHash)] = struct{}{}
 	}
 
-	txCountDeleted = pruneTransactions(txsToCheckMap)
+	// unconfirmed transactions are never retained
+	txCountDeleted = pruneTransactions(txsToCheckMap, nil)
 	tangle.DeleteUnconfirmedTxs(targetIndex)
 
 	return txCountDeleted, len(txsToCheckMap)
 }
 
-// pruneMilestone prunes the milestone metadata and the ledger diffs from the database for the given milestone
+// pruneMilestone prunes the milestone metadata, the ledger diffs, the conflict reasons and the white-flag confirmation results from the database for the given milestone
 func pruneMilestone(milestoneIndex milestone.Index) {
 
 	// state diffs
@@ -77,11 +78,22 @@ func pruneMilestone(milestoneIndex milestone.Index) {
 		log.Warn(err)
 	}
 
+	// conflict reasons
+	if err := tangle.DeleteConflictsForMilestone(milestoneIndex); err != nil {
+		log.Warn(err)
+	}
+
+	// white-flag confirmation results
+	if err := tangle.DeleteMilestoneConfirmation(milestoneIndex); err != nil {
+		log.Warn(err)
+	}
+
 	tangle.DeleteMilestone(milestoneIndex)
 }
 
-// pruneTransactions prunes the approvers, bundles, bundle txs, addresses, tags and transaction metadata from the database
-func pruneTransactions(txsToCheckMap map[string]struct{}) int {
+// pruneTransactions prunes the approvers, bundles, bundle txs, addresses, tags and transaction metadata from the database.
+// transactions matching the given retention filter are kept.
+func pruneTransactions(txsToCheckMap map[string]struct{}, filter *retentionFilter) int {
 
 	txsToDeleteMap := make(map[string]struct{})
 
@@ -93,6 +105,12 @@ func pruneTransactions(txsToCheckMap map[string]struct{}) int {
 			continue
 		}
 
+		if filter != nil && filter.isTransactionRetained(cachedTxMeta.GetMetadata()) {
+			// the transaction, its bundle and the index entries are kept as retained history
+			cachedTxMeta.Release(true) // tx -1
+			continue
+		}
+
 		for txToRemove := range tangle.RemoveTransactionFromBundle(cachedTxMeta.GetMetadata()) {
 			txsToDeleteMap[txToRemove] = struct{}{}
 		}
@@ -135,58 +153,97 @@ func pruneDatabase(targetIndex milestone.Index, abortSignal <-chan struct{}) err
 		log.Panic("No snapshotInfo found!")
 	}
 
-	if snapshotInfo.SnapshotIndex < SolidEntryPointCheckThresholdPast+AdditionalPruningThreshold+1 {
-		// Not enough history
-		return errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", SolidEntryPointCheckThresholdPast+AdditionalPruningThreshold+1, targetIndex)
+	if err := removeStalePruningState(snapshotInfo); err != nil {
+		return err
 	}
 
-	targetIndexMax := snapshotInfo.SnapshotIndex - SolidEntryPointCheckThresholdPast - AdditionalPruningThreshold - 1
-	if targetIndex > targetIndexMax {
-		targetIndex = targetIndexMax
+	pruningState, err := getInterruptedPruningState(snapshotInfo)
+	if err != nil {
+		return err
 	}
 
-	if snapshotInfo.PruningIndex >= targetIndex {
-		// no pruning needed
-		return errors.Wrapf(ErrNoPruningNeeded, "pruning index: %d, target index: %d", snapshotInfo.PruningIndex, targetIndex)
+	if pruningState != nil {
+		// the last pruning was interrupted. the solid entry points were already calculated for its target index,
+		// so it has to be finished first. a newer target index is pruned with the next run.
+		log.Infof("Resuming interrupted pruning from milestone %d to %d", snapshotInfo.PruningIndex, pruningState.TargetIndex)
+		targetIndex = pruningState.TargetIndex
+	} else {
+		if snapshotInfo.SnapshotIndex < SolidEntryPointCheckThresholdPast+AdditionalPruningThreshold+1 {
+			// Not enough history
+			return errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", SolidEntryPointCheckThresholdPast+AdditionalPruningThreshold+1, targetIndex)
+		}
+
+		targetIndexMax := snapshotInfo.SnapshotIndex - SolidEntryPointCheckThresholdPast - AdditionalPruningThreshold - 1
+		if targetIndex > targetIndexMax {
+			targetIndex = targetIndexMax
+		}
+
+		if pinnedTargetIndexMax, limited := getPinnedSnapshotsPruningLimit(); limited && targetIndex > pinnedTargetIndexMax {
+			// the history of pending pinned snapshots must not be pruned
+			targetIndex = pinnedTargetIndexMax
+		}
+
+		if snapshotInfo.PruningIndex >= targetIndex {
+			// no pruning needed
+			return errors.Wrapf(ErrNoPruningNeeded, "pruning index: %d, target index: %d", snapshotInfo.PruningIndex, targetIndex)
+		}
+
+		if snapshotInfo.EntryPointIndex+AdditionalPruningThreshold+1 > targetIndex {
+			// we prune in "AdditionalPruningThreshold" steps to recalculate the solidEntryPoints
+			return errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", snapshotInfo.EntryPointIndex+AdditionalPruningThreshold+1, targetIndex)
+		}
 	}
 
-	if snapshotInfo.EntryPointIndex+AdditionalPruningThreshold+1 > targetIndex {
-		// we prune in "AdditionalPruningThreshold" steps to recalculate the solidEntryPoints
-		return errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", snapshotInfo.EntryPointIndex+AdditionalPruningThreshold+1, targetIndex)
+	// remove a stale abort request
+	select {
+	case <-abortPruningSignal:
+	default:
 	}
 
 	setIsPruning(true)
 	defer setIsPruning(false)
 
-	// calculate solid entry points for the new end of the tangle history
-	newSolidEntryPoints, err := getSolidEntryPoints(targetIndex, abortSignal)
-	if err != nil {
-		return err
-	}
+	if pruningState == nil {
+		// calculate solid entry points for the new end of the tangle history
+		newSolidEntryPoints, err := getSolidEntryPoints(targetIndex, abortSignal)
+		if err != nil {
+			return err
+		}
+
+		tangle.WriteLockSolidEntryPoints()
+		tangle.ResetSolidEntryPoints()
+		for solidEntryPoint, index := range newSolidEntryPoints {
+			tangle.SolidEntryPointsAdd(hornet.Hash(solidEntryPoint), index)
+		}
+		tangle.StoreSolidEntryPoints()
+		tangle.WriteUnlockSolidEntryPoints()
+
+		// we have to set the new solid entry point index.
+		// this way we can cleanly prune even if the pruning was aborted last time
+		snapshotInfo.EntryPointIndex = targetIndex
+		tangle.SetSnapshotInfo(snapshotInfo)
 
-	tangle.WriteLockSolidEntryPoints()
-	tangle.ResetSolidEntryPoints()
-	for solidEntryPoint, index := range newSolidEntryPoints {
-		tangle.SolidEntryPointsAdd(hornet.Hash(solidEntryPoint), index)
+		// the pruning state is persisted, so an interrupted pruning can be resumed with the same solid entry points
+		pruningState = &tangle.PruningState{
+			StartIndex:  snapshotInfo.PruningIndex,
+			TargetIndex: targetIndex,
+			Timestamp:   time.Now().Unix(),
+		}
+		if err := tangle.StorePruningState(pruningState); err != nil {
+			return err
+		}
 	}
-	tangle.StoreSolidEntryPoints()
-	tangle.WriteUnlockSolidEntryPoints()
 
-	// we have to set the new solid entry point index.
-	// this way we can cleanly prune even if the pruning was aborted last time
-	snapshotInfo.EntryPointIndex = targetIndex
-	tangle.SetSnapshotInfo(snapshotInfo)
+	startPruningProgress(pruningState, snapshotInfo.PruningIndex)
 
 	// unconfirmed txs have to be pruned for PruningIndex as well, since this could be LSI at startup of the node
 	pruneUnconfirmedTransactions(snapshotInfo.PruningIndex)
 
 	// Iterate through all milestones that have to be pruned
 	for milestoneIndex := snapshotInfo.PruningIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
-		select {
-		case <-abortSignal:
-			// Stop pruning the next milestone
+		if isPruningAborted(abortSignal) {
+			// Stop pruning the next milestone, the pruning is resumed with the next run
 			return ErrPruningAborted
-		default:
 		}
 
 		log.Infof("Pruning milestone (%d)...", milestoneIndex)
@@ -234,16 +291,32 @@ func pruneDatabase(targetIndex milestone.Index, abortSignal <-chan struct{}) err
 		}
 
 		txCountChecked += len(txsToCheckMap)
-		txCountDeleted += pruneTransactions(txsToCheckMap)
+		filter := newRetentionFilter(milestoneIndex)
+		txCountDeleted += pruneTransactions(txsToCheckMap, filter)
+		if filter != nil {
+			filter.storeRetainedTransactions()
+		}
 
 		pruneMilestone(milestoneIndex)
 
+		// the retained transactions of older milestones are pruned after the retention window
+		txCountDeleted += pruneRetainedTransactions(milestoneIndex)
+
 		snapshotInfo.PruningIndex = milestoneIndex
 		tangle.SetSnapshotInfo(snapshotInfo)
 
 		log.Infof("Pruning milestone (%d) took %v. Pruned %d/%d transactions. ", milestoneIndex, time.Since(ts), txCountDeleted, txCountChecked)
 
+		addPruningProgress(txCountDeleted)
 		tanglePlugin.Events.PruningMilestoneIndexChanged.Trigger(milestoneIndex)
+
+		if !throttlePruning(ts, txCountDeleted, abortSignal) {
+			return ErrPruningAborted
+		}
+	}
+
+	if err := tangle.DeletePruningState(); err != nil {
+		log.Warn(err)
 	}
 
 	database.RunGarbageCollection()
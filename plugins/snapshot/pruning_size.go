@@ -0,0 +1,217 @@
+package snapshot
+
+import (
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+var (
+	ErrInvalidTargetDatabaseSize = errors.New("invalid target database size")
+
+	// the maximum size of the database in bytes (0 = prune by delay only)
+	pruningTargetDatabaseSize int64
+	// the percentage the database size is reduced below the target size if pruning by size was triggered
+	pruningTargetDatabaseSizeThresholdPercentage float64
+
+	// the amount of referenced bundles per milestone, used to weight the storage size of the milestones
+	milestoneStatsLock         syncutils.Mutex
+	milestoneReferencedBundles = make(map[milestone.Index]int)
+	// whether the statistics of the milestones confirmed before the node was started were loaded
+	milestoneStatsLoaded bool
+
+	// whether the last pruning target by size was limited by the snapshot index
+	pruningBySizeLimitedBySnapshot bool
+)
+
+func configurePruningBySize() {
+
+	targetDatabaseSize := config.NodeConfig.GetString(config.CfgPruningTargetDatabaseSize)
+	if targetDatabaseSize == "" {
+		return
+	}
+
+	size, err := humanize.ParseBytes(targetDatabaseSize)
+	if err != nil {
+		log.Fatal(errors.Wrapf(ErrInvalidTargetDatabaseSize, "'%s': %v", config.CfgPruningTargetDatabaseSize, err))
+	}
+	pruningTargetDatabaseSize = int64(size)
+
+	pruningTargetDatabaseSizeThresholdPercentage = config.NodeConfig.GetFloat64(config.CfgPruningTargetDatabaseSizeThresholdPercentage)
+	if pruningTargetDatabaseSizeThresholdPercentage < 0.0 || pruningTargetDatabaseSizeThresholdPercentage >= 100.0 {
+		log.Fatal(errors.Wrapf(ErrInvalidTargetDatabaseSize, "'%s' must be between 0 and 100", config.CfgPruningTargetDatabaseSizeThresholdPercentage))
+	}
+
+	log.Infof("pruning by database size enabled, target size: %s, minimum history: %d milestones", humanize.Bytes(size), pruningDelay)
+}
+
+// isPruningBySizeEnabled returns whether the pruning target is derived from the database size.
+func isPruningBySizeEnabled() bool {
+	return pruningTargetDatabaseSize > 0
+}
+
+// recordMilestoneStats stores the amount of referenced bundles of a confirmed milestone.
+func recordMilestoneStats(confirmation *whiteflag.Confirmation) {
+	milestoneStatsLock.Lock()
+	defer milestoneStatsLock.Unlock()
+
+	milestoneReferencedBundles[confirmation.MilestoneIndex] = len(confirmation.Mutations.TailsReferenced)
+}
+
+// cleanupMilestoneStats removes the statistics of all pruned milestones.
+func cleanupMilestoneStats(pruningIndex milestone.Index) {
+	milestoneStatsLock.Lock()
+	defer milestoneStatsLock.Unlock()
+
+	for msIndex := range milestoneReferencedBundles {
+		if msIndex <= pruningIndex {
+			delete(milestoneReferencedBundles, msIndex)
+		}
+	}
+}
+
+// loadMilestoneStats rebuilds the statistics of the milestones between the pruning index and the solid milestone index
+// from the stored confirmations, so they are not lost after a restart of the node.
+func loadMilestoneStats(pruningIndex milestone.Index, solidMilestoneIndex milestone.Index, abortSignal <-chan struct{}) {
+	milestoneStatsLock.Lock()
+	defer milestoneStatsLock.Unlock()
+
+	loaded := 0
+	for msIndex := pruningIndex + 1; msIndex <= solidMilestoneIndex; msIndex++ {
+		select {
+		case <-abortSignal:
+			// loaded again on the next try
+			return
+		default:
+		}
+
+		if _, exists := milestoneReferencedBundles[msIndex]; exists {
+			continue
+		}
+
+		referencedBundles, stored, err := tangle.GetMilestoneConfirmationReferencedCount(msIndex)
+		if err != nil {
+			log.Warnf("loading the statistics of milestone %d failed: %v", msIndex, err)
+			continue
+		}
+
+		if !stored {
+			// confirmed by an older version of the node, it is weighted with the average
+			continue
+		}
+
+		milestoneReferencedBundles[msIndex] = referencedBundles
+		loaded++
+	}
+	milestoneStatsLoaded = true
+
+	log.Infof("loaded the statistics of %d milestones for pruning by size", loaded)
+}
+
+// getPruningTargetIndexByWeights returns the index up to which the milestones have to be pruned to free the given amount of bytes.
+// The size of the tangle database is distributed over the milestones between the pruning index and the solid milestone index
+// by their weights, which are proportional to the estimated storage size of the milestones.
+// Milestones without statistics (e.g. confirmed by an older version of the node) are weighted with the average of the known milestones.
+func getPruningTargetIndexByWeights(pruningIndex milestone.Index, solidMilestoneIndex milestone.Index, tangleSize int64, excessSize float64) milestone.Index {
+	milestoneStatsLock.Lock()
+	defer milestoneStatsLock.Unlock()
+
+	knownSum := 0
+	knownCount := 0
+	for msIndex, referencedBundles := range milestoneReferencedBundles {
+		if msIndex <= pruningIndex || msIndex > solidMilestoneIndex {
+			continue
+		}
+		knownSum += referencedBundles
+		knownCount++
+	}
+
+	// the milestone bundle itself is always stored, so every milestone has a weight of at least 1
+	averageWeight := 1.0
+	if knownCount > 0 {
+		averageWeight += float64(knownSum) / float64(knownCount)
+	}
+
+	weight := func(msIndex milestone.Index) float64 {
+		if referencedBundles, known := milestoneReferencedBundles[msIndex]; known {
+			return 1.0 + float64(referencedBundles)
+		}
+		return averageWeight
+	}
+
+	weightSum := 0.0
+	for msIndex := pruningIndex + 1; msIndex <= solidMilestoneIndex; msIndex++ {
+		weightSum += weight(msIndex)
+	}
+
+	if weightSum == 0 {
+		return pruningIndex
+	}
+	bytesPerWeight := float64(tangleSize) / weightSum
+
+	targetIndex := pruningIndex
+	freedSize := 0.0
+	for freedSize < excessSize && targetIndex < solidMilestoneIndex {
+		targetIndex++
+		freedSize += weight(targetIndex) * bytesPerWeight
+	}
+
+	return targetIndex
+}
+
+// getPruningTargetIndexBySize computes the pruning target index that is needed to bring the database
+// below the configured target size, and whether pruning is needed at all.
+// The target index keeps at least "pruningDelay" milestones and respects the limits of the snapshot index.
+func getPruningTargetIndexBySize(solidMilestoneIndex milestone.Index) (milestone.Index, bool) {
+
+	tangleSize, snapshotSize, spentSize := tangle.GetDatabaseUsedSizes()
+	databaseSize := tangleSize + snapshotSize + spentSize
+
+	if databaseSize <= pruningTargetDatabaseSize {
+		return 0, false
+	}
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		log.Panic("No snapshotInfo found!")
+	}
+
+	if solidMilestoneIndex <= snapshotInfo.PruningIndex || solidMilestoneIndex <= pruningDelay {
+		// Not enough history
+		return 0, false
+	}
+
+	// the amount of bytes that has to be freed
+	excessSize := float64(databaseSize) - float64(pruningTargetDatabaseSize)*(1.0-pruningTargetDatabaseSizeThresholdPercentage/100.0)
+
+	// only the tangle database shrinks by pruning
+	targetIndex := getPruningTargetIndexByWeights(snapshotInfo.PruningIndex, solidMilestoneIndex, tangleSize, excessSize)
+
+	if targetIndexMax := solidMilestoneIndex - pruningDelay; targetIndex > targetIndexMax {
+		// the minimum history is kept, even if the target size can't be reached
+		log.Debugf("pruning by size limited by '%s': target index %d, maximum %d", config.CfgPruningDelay, targetIndex, targetIndexMax)
+		targetIndex = targetIndexMax
+	}
+
+	targetIndexMaxSnapshot := snapshotInfo.SnapshotIndex - SolidEntryPointCheckThresholdPast - AdditionalPruningThreshold - 1
+	if limited := snapshotInfo.SnapshotIndex < SolidEntryPointCheckThresholdPast+AdditionalPruningThreshold+1 || targetIndex > targetIndexMaxSnapshot; limited != pruningBySizeLimitedBySnapshot {
+		pruningBySizeLimitedBySnapshot = limited
+		if limited {
+			log.Warnf("pruning by size is limited by the snapshot index (%d), the target database size can't be reached until the next local snapshot is created", snapshotInfo.SnapshotIndex)
+		}
+	}
+
+	if targetIndex <= snapshotInfo.PruningIndex {
+		return 0, false
+	}
+
+	log.Debugf("database size (%s) exceeds the target size (%s), pruning up to milestone %d", humanize.Bytes(uint64(databaseSize)), humanize.Bytes(uint64(pruningTargetDatabaseSize)), targetIndex)
+
+	return targetIndex, true
+}
@@ -0,0 +1,47 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+func recordTestMilestoneStats(msIndex milestone.Index, referencedBundles int) {
+	recordMilestoneStats(&whiteflag.Confirmation{
+		MilestoneIndex: msIndex,
+		Mutations: &whiteflag.WhiteFlagMutations{
+			TailsReferenced: make(hornet.Hashes, referencedBundles),
+		},
+	})
+}
+
+func TestPruningTargetIndexByWeights(t *testing.T) {
+	milestoneReferencedBundles = make(map[milestone.Index]int)
+
+	// without statistics all milestones are weighted equally
+	require.Equal(t, milestone.Index(10), getPruningTargetIndexByWeights(0, 100, 1000, 100))
+	require.Equal(t, milestone.Index(100), getPruningTargetIndexByWeights(0, 100, 1000, 2000))
+	require.Equal(t, milestone.Index(0), getPruningTargetIndexByWeights(0, 100, 1000, 0))
+
+	// milestones 1-10 reference 99 bundles each, the others are weighted with the average of the known milestones
+	for msIndex := milestone.Index(1); msIndex <= 10; msIndex++ {
+		recordTestMilestoneStats(msIndex, 99)
+	}
+	require.Equal(t, milestone.Index(1), getPruningTargetIndexByWeights(0, 100, 1000, 10))
+
+	// milestones 11-100 reference 9 bundles each, so the first 10 milestones hold more than half of the size
+	for msIndex := milestone.Index(11); msIndex <= 100; msIndex++ {
+		recordTestMilestoneStats(msIndex, 9)
+	}
+	require.Equal(t, milestone.Index(10), getPruningTargetIndexByWeights(0, 100, 1900, 950))
+	require.Equal(t, milestone.Index(15), getPruningTargetIndexByWeights(0, 100, 1900, 1050))
+
+	// the statistics of pruned milestones are removed
+	cleanupMilestoneStats(10)
+	require.Len(t, milestoneReferencedBundles, 90)
+	require.Equal(t, milestone.Index(20), getPruningTargetIndexByWeights(10, 100, 900, 100))
+}
@@ -0,0 +1,240 @@
+package snapshot
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+var (
+	ErrPruningNotRunning = errors.New("no pruning is running")
+
+	// the maximum amount of milestones pruned per second (0 = unlimited)
+	pruningMaxMilestonesPerSecond float64
+	// the maximum amount of transactions deleted per second (0 = unlimited)
+	pruningMaxTransactionsPerSecond int
+
+	// signal to abort a running pruning
+	abortPruningSignal = make(chan struct{}, 1)
+
+	// progress of the running pruning
+	pruningProgressLock     syncutils.RWMutex
+	pruningRunStartIndex    milestone.Index
+	pruningRunStartTime     time.Time
+	pruningRunTargetIndex   milestone.Index
+	pruningRunInitialIndex  milestone.Index
+	pruningRunInitialTime   time.Time
+	pruningRunTxCountPruned int
+)
+
+// PruningStatus is the progress of a running or interrupted pruning.
+type PruningStatus struct {
+	// whether a pruning is running at the moment
+	IsPruning bool
+	// whether an interrupted pruning is pending and will be resumed with the next pruning
+	IsInterrupted bool
+	// the index the pruning was started at (before it was interrupted)
+	StartIndex milestone.Index
+	// the start time of the pruning (before it was interrupted)
+	StartTime time.Time
+	// the index up to which the database is pruned
+	PruningIndex milestone.Index
+	// the target index of the pruning
+	TargetIndex milestone.Index
+	// the amount of transactions deleted by the running pruning
+	TransactionsPruned int
+	// the finished percentage of the running pruning
+	Percentage float64
+	// the estimated remaining time of the running pruning
+	ETA time.Duration
+}
+
+func configurePruningThrottle() {
+	pruningMaxMilestonesPerSecond = config.NodeConfig.GetFloat64(config.CfgPruningMaxMilestonesPerSecond)
+	pruningMaxTransactionsPerSecond = config.NodeConfig.GetInt(config.CfgPruningMaxTransactionsPerSecond)
+
+	if pruningMaxMilestonesPerSecond > 0 || pruningMaxTransactionsPerSecond > 0 {
+		log.Infof("pruning throttle enabled: %0.2f milestones/s, %d transactions/s", pruningMaxMilestonesPerSecond, pruningMaxTransactionsPerSecond)
+	}
+}
+
+// getInterruptedPruningState returns the persisted state of a pruning that was interrupted before the target index was reached.
+// The solid entry points of that pruning were already stored, so it has to be finished before a new target can be pruned.
+func getInterruptedPruningState(snapshotInfo *tangle.SnapshotInfo) (*tangle.PruningState, error) {
+
+	pruningState, err := tangle.ReadPruningState()
+	if err != nil {
+		return nil, err
+	}
+
+	if pruningState == nil || isPruningStateStale(pruningState, snapshotInfo) {
+		return nil, nil
+	}
+
+	return pruningState, nil
+}
+
+// isPruningStateStale returns whether the given pruning state was finished
+// or its solid entry points were replaced (e.g. by loading a snapshot).
+func isPruningStateStale(pruningState *tangle.PruningState, snapshotInfo *tangle.SnapshotInfo) bool {
+	return pruningState.TargetIndex != snapshotInfo.EntryPointIndex || snapshotInfo.PruningIndex >= pruningState.TargetIndex
+}
+
+// removeStalePruningState removes a persisted pruning state that can't be resumed anymore.
+// It has to be called while holding the localSnapshotLock, so no new pruning state is stored in the meantime.
+func removeStalePruningState(snapshotInfo *tangle.SnapshotInfo) error {
+
+	pruningState, err := tangle.ReadPruningState()
+	if err != nil {
+		return err
+	}
+
+	if pruningState == nil || !isPruningStateStale(pruningState, snapshotInfo) {
+		return nil
+	}
+
+	log.Infof("removing stale pruning state from milestone %d to %d", pruningState.StartIndex, pruningState.TargetIndex)
+
+	return tangle.DeletePruningState()
+}
+
+// startPruningProgress resets the progress for a new pruning run.
+func startPruningProgress(pruningState *tangle.PruningState, pruningIndex milestone.Index) {
+	pruningProgressLock.Lock()
+	defer pruningProgressLock.Unlock()
+
+	pruningRunInitialIndex = pruningState.StartIndex
+	pruningRunInitialTime = time.Unix(pruningState.Timestamp, 0)
+	pruningRunStartIndex = pruningIndex
+	pruningRunStartTime = time.Now()
+	pruningRunTargetIndex = pruningState.TargetIndex
+	pruningRunTxCountPruned = 0
+}
+
+func addPruningProgress(txCountDeleted int) {
+	pruningProgressLock.Lock()
+	defer pruningProgressLock.Unlock()
+
+	pruningRunTxCountPruned += txCountDeleted
+}
+
+// throttlePruning waits until the configured pruning rate is respected.
+// It returns false if the pruning was aborted while waiting.
+func throttlePruning(ts time.Time, txCountDeleted int, abortSignal <-chan struct{}) bool {
+
+	var wait time.Duration
+	if pruningMaxMilestonesPerSecond > 0 {
+		wait = time.Duration(float64(time.Second) / pruningMaxMilestonesPerSecond)
+	}
+	if pruningMaxTransactionsPerSecond > 0 {
+		if txWait := time.Duration(txCountDeleted) * time.Second / time.Duration(pruningMaxTransactionsPerSecond); txWait > wait {
+			wait = txWait
+		}
+	}
+
+	wait -= time.Since(ts)
+	if wait <= 0 {
+		return true
+	}
+
+	select {
+	case <-time.After(wait):
+		return true
+	case <-abortSignal:
+		return false
+	case <-abortPruningSignal:
+		return false
+	}
+}
+
+// isPruningAborted returns whether the pruning was aborted by the given signal or by AbortPruning.
+func isPruningAborted(abortSignal <-chan struct{}) bool {
+	select {
+	case <-abortSignal:
+		return true
+	case <-abortPruningSignal:
+		return true
+	default:
+		return false
+	}
+}
+
+// AbortPruning aborts the running pruning after the current milestone.
+// The pruning is resumed with the next pruning run.
+func AbortPruning() error {
+	statusLock.RLock()
+	defer statusLock.RUnlock()
+
+	if !isPruning {
+		return ErrPruningNotRunning
+	}
+
+	select {
+	case abortPruningSignal <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// GetPruningStatus returns the progress of the running pruning, or of an interrupted pruning that is pending.
+func GetPruningStatus() (*PruningStatus, error) {
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return nil, errors.Wrap(ErrCritical, "no snapshot info found")
+	}
+
+	statusLock.RLock()
+	running := isPruning
+	statusLock.RUnlock()
+
+	status := &PruningStatus{
+		IsPruning:    running,
+		PruningIndex: snapshotInfo.PruningIndex,
+		TargetIndex:  snapshotInfo.PruningIndex,
+	}
+
+	if !running {
+		pruningState, err := getInterruptedPruningState(snapshotInfo)
+		if err != nil {
+			return nil, err
+		}
+		if pruningState != nil {
+			status.IsInterrupted = true
+			status.StartIndex = pruningState.StartIndex
+			status.StartTime = time.Unix(pruningState.Timestamp, 0)
+			status.TargetIndex = pruningState.TargetIndex
+			if total := pruningState.TargetIndex - pruningState.StartIndex; total > 0 {
+				status.Percentage = float64(snapshotInfo.PruningIndex-pruningState.StartIndex) / float64(total) * 100.0
+			}
+		}
+		return status, nil
+	}
+
+	pruningProgressLock.RLock()
+	defer pruningProgressLock.RUnlock()
+
+	status.StartIndex = pruningRunInitialIndex
+	status.StartTime = pruningRunInitialTime
+	status.TargetIndex = pruningRunTargetIndex
+	status.TransactionsPruned = pruningRunTxCountPruned
+
+	if total := pruningRunTargetIndex - pruningRunInitialIndex; total > 0 {
+		status.Percentage = float64(status.PruningIndex-pruningRunInitialIndex) / float64(total) * 100.0
+	}
+
+	// the ETA is based on the milestones pruned since the last (re)start
+	if current := status.PruningIndex - pruningRunStartIndex; current > 0 {
+		_, status.ETA = utils.EstimateRemainingTime(pruningRunStartTime, int64(current), int64(pruningRunTargetIndex-pruningRunStartIndex))
+	}
+
+	return status, nil
+}
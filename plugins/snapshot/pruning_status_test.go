@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/testsuite"
+)
+
+func TestPruningStateFromBytes(t *testing.T) {
+
+	state := &tangle.PruningState{
+		StartIndex:  100,
+		TargetIndex: 200,
+		Timestamp:   1600000000,
+	}
+
+	parsed, err := tangle.PruningStateFromBytes(state.GetBytes())
+	require.NoError(t, err)
+	require.Equal(t, state, parsed)
+
+	_, err = tangle.PruningStateFromBytes(state.GetBytes()[:15])
+	require.Error(t, err)
+}
+
+func TestInterruptedPruningState(t *testing.T) {
+
+	te := testsuite.SetupTestEnvironment(t, make(map[string]uint64), 0, false)
+	defer te.CleanupTestEnvironment(true)
+
+	state := &tangle.PruningState{
+		StartIndex:  100,
+		TargetIndex: 200,
+		Timestamp:   1600000000,
+	}
+	require.NoError(t, tangle.StorePruningState(state))
+
+	// the pruning was interrupted after milestone 150
+	snapshotInfo := &tangle.SnapshotInfo{
+		SnapshotIndex:   300,
+		EntryPointIndex: 200,
+		PruningIndex:    150,
+	}
+
+	require.NoError(t, removeStalePruningState(snapshotInfo))
+	interrupted, err := getInterruptedPruningState(snapshotInfo)
+	require.NoError(t, err)
+	require.Equal(t, state, interrupted)
+
+	// the solid entry points were replaced, the state can't be resumed anymore
+	snapshotInfo.EntryPointIndex = 250
+
+	interrupted, err = getInterruptedPruningState(snapshotInfo)
+	require.NoError(t, err)
+	require.Nil(t, interrupted)
+
+	// the stale state is only read by the status, it is removed with the next pruning run
+	stored, err := tangle.ReadPruningState()
+	require.NoError(t, err)
+	require.Equal(t, state, stored)
+
+	require.NoError(t, removeStalePruningState(snapshotInfo))
+	stored, err = tangle.ReadPruningState()
+	require.NoError(t, err)
+	require.Nil(t, stored)
+
+	// a finished pruning is stale as well
+	require.NoError(t, tangle.StorePruningState(state))
+	snapshotInfo.EntryPointIndex = 200
+	snapshotInfo.PruningIndex = 200
+
+	require.NoError(t, removeStalePruningState(snapshotInfo))
+	stored, err = tangle.ReadPruningState()
+	require.NoError(t, err)
+	require.Nil(t, stored)
+
+	// nothing to remove
+	require.NoError(t, removeStalePruningState(snapshotInfo))
+}
@@ -0,0 +1,226 @@
+package snapshot
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+var (
+	ErrInvalidRetentionFilter = errors.New("invalid pruning retention filter")
+
+	retentionAddresses map[string]struct{}
+	retentionTags      map[string]struct{}
+	retentionBundles   map[string]struct{}
+
+	// the amount of milestones the retained transactions are kept after their milestone was pruned (0 = keep forever)
+	retentionMilestones milestone.Index
+
+	// whether the retained transactions which are overdue since the node was started were pruned
+	overdueRetainedTransactionsPruned bool
+)
+
+func configureRetentionFilter() {
+
+	retentionAddresses = make(map[string]struct{})
+	retentionTags = make(map[string]struct{})
+	retentionBundles = make(map[string]struct{})
+
+	for _, addressTrytes := range config.NodeConfig.GetStringSlice(config.CfgPruningRetentionAddresses) {
+		if err := address.ValidAddress(addressTrytes); err != nil {
+			log.Fatal(errors.Wrapf(ErrInvalidRetentionFilter, "address invalid: %s", addressTrytes))
+		}
+		retentionAddresses[string(hornet.HashFromAddressTrytes(addressTrytes))] = struct{}{}
+	}
+
+	for _, tagTrytes := range config.NodeConfig.GetStringSlice(config.CfgPruningRetentionTags) {
+		if err := trinary.ValidTrytes(tagTrytes); err != nil || len(tagTrytes) > 27 {
+			log.Fatal(errors.Wrapf(ErrInvalidRetentionFilter, "tag invalid: %s", tagTrytes))
+		}
+		if len(tagTrytes) < 27 {
+			tagTrytes = trinary.MustPad(tagTrytes, 27)
+		}
+		retentionTags[string(hornet.HashFromTagTrytes(tagTrytes))] = struct{}{}
+	}
+
+	for _, bundleTrytes := range config.NodeConfig.GetStringSlice(config.CfgPruningRetentionBundles) {
+		if !guards.IsTrytesOfExactLength(bundleTrytes, 81) {
+			log.Fatal(errors.Wrapf(ErrInvalidRetentionFilter, "bundle hash invalid: %s", bundleTrytes))
+		}
+		retentionBundles[string(hornet.HashFromHashTrytes(bundleTrytes))] = struct{}{}
+	}
+
+	retentionMilestonesConfig := config.NodeConfig.GetInt(config.CfgPruningRetentionMilestones)
+	if retentionMilestonesConfig < 0 {
+		log.Fatal(errors.Wrapf(ErrInvalidRetentionFilter, "'%s' must not be negative", config.CfgPruningRetentionMilestones))
+	}
+	retentionMilestones = milestone.Index(retentionMilestonesConfig)
+
+	if isRetentionFilterEnabled() {
+		log.Infof("pruning retention filter enabled: %d addresses, %d tags, %d bundles, kept for %d milestones", len(retentionAddresses), len(retentionTags), len(retentionBundles), retentionMilestones)
+	}
+}
+
+// isRetentionFilterEnabled returns whether transactions are kept in the database while pruning.
+func isRetentionFilterEnabled() bool {
+	return len(retentionAddresses) > 0 || len(retentionTags) > 0 || len(retentionBundles) > 0
+}
+
+// retentionFilter decides which bundles are kept in the database while a milestone is pruned.
+// the results are cached per bundle hash, because a bundle is either retained as a whole or not at all.
+type retentionFilter struct {
+	// the index of the pruned milestone
+	msIndex milestone.Index
+	bundles map[string]bool
+	// the hashes of the retained transactions
+	retained hornet.Hashes
+}
+
+// newRetentionFilter returns the retention filter for the pruning of the given milestone,
+// or nil if the retention filter is disabled.
+func newRetentionFilter(msIndex milestone.Index) *retentionFilter {
+	if !isRetentionFilterEnabled() {
+		return nil
+	}
+
+	return &retentionFilter{
+		msIndex: msIndex,
+		bundles: make(map[string]bool),
+	}
+}
+
+// isBundleRetained returns whether the bundle with the given hash contains a transaction that matches the retention filter.
+func (f *retentionFilter) isBundleRetained(bundleHash hornet.Hash) bool {
+
+	if retained, exists := f.bundles[string(bundleHash)]; exists {
+		return retained
+	}
+
+	retained := false
+	if _, exists := retentionBundles[string(bundleHash)]; exists {
+		retained = true
+	}
+
+	if !retained && (len(retentionAddresses) > 0 || len(retentionTags) > 0) {
+		for _, txHash := range tangle.GetBundleTransactionHashes(bundleHash, true) {
+			cachedTx := tangle.GetCachedTransactionOrNil(txHash) // tx +1
+			if cachedTx == nil {
+				continue
+			}
+
+			_, addressMatches := retentionAddresses[string(cachedTx.GetTransaction().GetAddress())]
+			_, tagMatches := retentionTags[string(cachedTx.GetTransaction().GetTag())]
+
+			// do not force release, since it is loaded again for pruning
+			cachedTx.Release() // tx -1
+
+			if addressMatches || tagMatches {
+				retained = true
+				break
+			}
+		}
+	}
+
+	f.bundles[string(bundleHash)] = retained
+	return retained
+}
+
+// isTransactionRetained returns whether the given transaction has to be kept in the database while pruning.
+// only confirmed transactions are retained, since unconfirmed transactions are not part of the payment history.
+func (f *retentionFilter) isTransactionRetained(txMeta *hornet.TransactionMetadata) bool {
+
+	if !txMeta.IsConfirmed() {
+		return false
+	}
+
+	if !f.isBundleRetained(txMeta.GetBundleHash()) {
+		return false
+	}
+
+	txMeta.SetRetained(true)
+	f.retained = append(f.retained, txMeta.GetTxHash())
+	return true
+}
+
+// storeRetainedTransactions stores the hashes of the retained transactions,
+// so they can be pruned after the retention window.
+func (f *retentionFilter) storeRetainedTransactions() {
+	if len(f.retained) == 0 {
+		return
+	}
+
+	if err := tangle.StoreRetainedTransactions(f.msIndex, f.retained); err != nil {
+		log.Warn(err)
+	}
+}
+
+// pruneRetainedTransactions prunes the transactions which were retained while the milestone
+// "retentionMilestones" before the given pruned milestone was pruned.
+// the retention window may have been enabled or shrunk since older milestones were pruned,
+// so the retained transactions of all milestones before the window are pruned once after the node was started.
+func pruneRetainedTransactions(prunedIndex milestone.Index) int {
+
+	if retentionMilestones == 0 || prunedIndex <= retentionMilestones {
+		return 0
+	}
+	msIndex := prunedIndex - retentionMilestones
+
+	txCountDeleted := 0
+
+	if !overdueRetainedTransactionsPruned {
+		overdueRetainedTransactionsPruned = true
+
+		msIndexes, err := tangle.GetRetainedTransactionsMilestoneIndexes()
+		if err != nil {
+			log.Warn(err)
+		}
+
+		for _, overdueIndex := range msIndexes {
+			if overdueIndex < msIndex {
+				txCountDeleted += pruneRetainedTransactionsOfMilestone(overdueIndex)
+			}
+		}
+	}
+
+	return txCountDeleted + pruneRetainedTransactionsOfMilestone(msIndex)
+}
+
+// pruneRetainedTransactionsOfMilestone prunes the transactions which were retained while the given milestone was pruned.
+func pruneRetainedTransactionsOfMilestone(msIndex milestone.Index) int {
+
+	txHashes, err := tangle.GetRetainedTransactions(msIndex)
+	if err != nil {
+		log.Warn(err)
+		return 0
+	}
+
+	if len(txHashes) == 0 {
+		return 0
+	}
+
+	txsToCheckMap := make(map[string]struct{})
+	for _, txHash := range txHashes {
+		if !tangle.ContainsTransaction(txHash) {
+			// the transaction was already pruned together with the retained transactions of another milestone
+			continue
+		}
+		txsToCheckMap[string(txHash)] = struct{}{}
+	}
+
+	txCountDeleted := pruneTransactions(txsToCheckMap, nil)
+
+	if err := tangle.DeleteRetainedTransactions(msIndex); err != nil {
+		log.Warn(err)
+	}
+
+	log.Infof("pruned %d retained transactions of milestone %d", txCountDeleted, msIndex)
+
+	return txCountDeleted
+}
@@ -0,0 +1,93 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/testsuite/utils"
+)
+
+func TestRetainedTransactions(t *testing.T) {
+
+	te := testsuite.SetupTestEnvironment(t, make(map[string]uint64), 2, false)
+	defer te.CleanupTestEnvironment(true)
+
+	retentionAddresses = make(map[string]struct{})
+	retentionBundles = make(map[string]struct{})
+	retentionTags = map[string]struct{}{
+		string(hornet.HashFromTagTrytes(trinary.MustPad("RETAINED", 27))): {},
+	}
+	retentionMilestones = 10
+	overdueRetainedTransactionsPruned = false
+	defer func() { retentionTags = make(map[string]struct{}) }()
+
+	bundleA := te.AttachAndStoreBundle(te.Milestones[0].GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ZeroValueTx(t, "RETAINED"))
+	bundleB := te.AttachAndStoreBundle(bundleA.GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ZeroValueTx(t, "PRUNED"))
+	bundleC := te.AttachAndStoreBundle(bundleB.GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ZeroValueTx(t, "RETAINED"))
+
+	tailA := bundleA.GetBundle().GetTailHash()
+	tailB := bundleB.GetBundle().GetTailHash()
+	tailC := bundleC.GetBundle().GetTailHash()
+
+	// bundle C is not confirmed, so it is not retained
+	confStats := te.IssueAndConfirmMilestoneOnTip(tailB, false)
+	msIndex := confStats.Index
+
+	filter := newRetentionFilter(msIndex)
+	require.NotNil(t, filter)
+
+	prunedCount := pruneTransactions(map[string]struct{}{
+		string(tailA): {},
+		string(tailB): {},
+		string(tailC): {},
+	}, filter)
+	filter.storeRetainedTransactions()
+
+	require.Equal(t, 2, prunedCount)
+	require.True(t, tangle.ContainsTransaction(tailA))
+	require.False(t, tangle.ContainsTransaction(tailB))
+	require.False(t, tangle.ContainsTransaction(tailC))
+
+	cachedTxMeta := tangle.GetCachedTxMetadataOrNil(tailA) // tx +1
+	require.NotNil(t, cachedTxMeta)
+	require.True(t, cachedTxMeta.GetMetadata().IsRetained())
+	cachedTxMeta.Release(true) // tx -1
+
+	retained, err := tangle.GetRetainedTransactions(msIndex)
+	require.NoError(t, err)
+	require.Equal(t, hornet.Hashes{tailA}, retained)
+
+	// the retained transactions are kept within the retention window
+	require.Equal(t, 0, pruneRetainedTransactions(msIndex+retentionMilestones-1))
+	require.True(t, tangle.ContainsTransaction(tailA))
+
+	require.Equal(t, 1, pruneRetainedTransactions(msIndex+retentionMilestones))
+	require.False(t, tangle.ContainsTransaction(tailA))
+
+	retained, err = tangle.GetRetainedTransactions(msIndex)
+	require.NoError(t, err)
+	require.Empty(t, retained)
+
+	// the retained transactions skipped by a shrunk retention window are pruned once after the start
+	bundleD := te.AttachAndStoreBundle(te.Milestones[0].GetBundle().GetTailHash(), te.Milestones[1].GetBundle().GetTailHash(), utils.ZeroValueTx(t, "RETAINED"))
+	tailD := bundleD.GetBundle().GetTailHash()
+	require.NoError(t, tangle.StoreRetainedTransactions(msIndex, hornet.Hashes{tailD}))
+
+	overdueRetainedTransactionsPruned = false
+	require.Equal(t, 1, pruneRetainedTransactions(msIndex+retentionMilestones+5))
+	require.False(t, tangle.ContainsTransaction(tailD))
+
+	retained, err = tangle.GetRetainedTransactions(msIndex)
+	require.NoError(t, err)
+	require.Empty(t, retained)
+
+	// the retention filter is disabled without any rules
+	retentionTags = make(map[string]struct{})
+	require.Nil(t, newRetentionFilter(msIndex))
+}
@@ -0,0 +1,156 @@
+package snapshot
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/daemon"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/spentaddresses"
+)
+
+var (
+	ErrSpentAddressesDisabled         = errors.New("spent addresses are disabled in this node")
+	ErrSpentAddressesExportWasAborted = errors.New("spent addresses export was aborted")
+	ErrSpentAddressesImportWasAborted = errors.New("spent addresses import was aborted")
+	ErrSpentAddressesFileOutsideDir   = errors.New("spent addresses file is outside of the snapshot directory")
+)
+
+// SpentAddressesDirectory returns the directory to which spent addresses are exported and from which they can be imported.
+func SpentAddressesDirectory() string {
+	return filepath.Dir(config.NodeConfig.GetString(config.CfgLocalSnapshotsPath))
+}
+
+// SpentAddressesFilePath returns the path of the given spent addresses file in the snapshot directory.
+// Relative paths are resolved against the snapshot directory, paths outside of it are rejected.
+func SpentAddressesFilePath(fileName string) (string, error) {
+
+	dir, err := filepath.Abs(SpentAddressesDirectory())
+	if err != nil {
+		return "", err
+	}
+
+	filePath := fileName
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(dir, filePath)
+	}
+	filePath = filepath.Clean(filePath)
+
+	relPath, err := filepath.Rel(dir, filePath)
+	if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", errors.Wrap(ErrSpentAddressesFileOutsideDir, fileName)
+	}
+
+	return filePath, nil
+}
+
+// ExportSpentAddresses writes all spent addresses of the database to a compressed file in the snapshot directory,
+// together with a header containing the amount of spent addresses and their Merkle root.
+// It returns the path of the created file and its header.
+func ExportSpentAddresses(abortSignal <-chan struct{}) (string, *spentaddresses.FileHeader, error) {
+	localSnapshotLock.Lock()
+	defer localSnapshotLock.Unlock()
+
+	if !tangle.GetSnapshotInfo().IsSpentAddressesEnabled() || !config.NodeConfig.GetBool(config.CfgSpentAddressesEnabled) {
+		return "", nil, ErrSpentAddressesDisabled
+	}
+
+	// the milestone index in the file name and in the header are read under the same lock
+	msIndex := tangle.GetSolidMilestoneIndex()
+	filePath := filepath.Join(SpentAddressesDirectory(), fmt.Sprintf("spent_addresses_%d.bin", msIndex))
+
+	log.Infof("exporting spent addresses to %s", filePath)
+
+	ts := time.Now()
+
+	header, err := spentaddresses.WriteFile(filePath, msIndex, tangle.StreamSpentAddressesToWriter, abortSignal)
+	if err != nil {
+		if err == tangle.ErrOperationAborted {
+			return "", nil, ErrSpentAddressesExportWasAborted
+		}
+		return "", nil, err
+	}
+
+	log.Infof("exported %d spent addresses (Merkle root: %x), took %v", header.SpentAddressesCount, header.MerkleRoot, time.Since(ts))
+
+	return filePath, header, nil
+}
+
+// importSpentAddressesWithoutLocking verifies the given spent addresses export file and merges
+// the contained spent addresses into the database. Already known spent addresses are skipped.
+func importSpentAddressesWithoutLocking(filePath string, abortSignal <-chan struct{}) (*spentaddresses.FileHeader, int, error) {
+
+	log.Infof("verifying spent addresses file %s", filePath)
+
+	// the whole file is verified first, so that a corrupted file doesn't lead to a partially merged set
+	if _, err := spentaddresses.VerifyFile(filePath, abortSignal); err != nil {
+		if errors.Is(err, spentaddresses.ErrOperationAborted) {
+			return nil, 0, ErrSpentAddressesImportWasAborted
+		}
+		return nil, 0, err
+	}
+
+	log.Infof("importing spent addresses from %s", filePath)
+
+	ts := time.Now()
+	newlyAddedCount := 0
+	processedCount := 0
+
+	header, err := spentaddresses.ForEachInFile(filePath, func(address hornet.Hash) error {
+		if daemon.IsStopped() {
+			return ErrSpentAddressesImportWasAborted
+		}
+
+		select {
+		case <-abortSignal:
+			return ErrSpentAddressesImportWasAborted
+		default:
+		}
+
+		if tangle.MarkAddressAsSpent(address) {
+			newlyAddedCount++
+		}
+
+		processedCount++
+		if processedCount%SpentAddressesImportBatchSize == 0 {
+			log.Infof("processed %d spent addresses", processedCount)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	log.Infof("imported %d new spent addresses out of %d (Merkle root: %x), took %v", newlyAddedCount, header.SpentAddressesCount, header.MerkleRoot, time.Since(ts))
+
+	return header, newlyAddedCount, nil
+}
+
+// ImportSpentAddresses verifies the given spent addresses export file in the snapshot directory and merges
+// the contained spent addresses into the database. Already known spent addresses are skipped,
+// so several files can be imported incrementally.
+// It returns the header of the file and the amount of newly added spent addresses.
+func ImportSpentAddresses(fileName string, abortSignal <-chan struct{}) (*spentaddresses.FileHeader, int, error) {
+
+	filePath, err := SpentAddressesFilePath(fileName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	localSnapshotLock.Lock()
+	defer localSnapshotLock.Unlock()
+
+	if !tangle.GetSnapshotInfo().IsSpentAddressesEnabled() || !config.NodeConfig.GetBool(config.CfgSpentAddressesEnabled) {
+		return nil, 0, ErrSpentAddressesDisabled
+	}
+
+	return importSpentAddressesWithoutLocking(filePath, abortSignal)
+}
@@ -125,6 +125,8 @@ func run(plugin *node.Plugin) {
 				}
 
 				// peer is connected but doesn't seem to be alive
+				p.Score.MissedHeartbeat()
+
 				if p.Autopeering != nil {
 					// it's better to drop the connection to autopeered peers and free the slots for other peers
 					peerIDsToRemove[p.ID] = struct{}{}
@@ -196,6 +196,12 @@ func cleanupMilestones(info *tangle.SnapshotInfo) error {
 		if err := tangle.DeleteLedgerDiffForMilestone(msIndex); err != nil {
 			panic(err)
 		}
+		if err := tangle.DeleteConflictsForMilestone(msIndex); err != nil {
+			panic(err)
+		}
+		if err := tangle.DeleteMilestoneConfirmation(msIndex); err != nil {
+			panic(err)
+		}
 
 		tangle.DeleteMilestone(msIndex)
 	}
@@ -446,6 +446,10 @@ func solidifyMilestone(newMilestoneIndex milestone.Index, force bool) {
 		log.Panic(err)
 	}
 
+	if conf.StoreErr != nil {
+		log.Warnf("Storing the confirmation of milestone %d failed: %v", conf.Index, conf.StoreErr)
+	}
+
 	log.Infof("Milestone confirmed (%d): txsConfirmed: %v, txsValue: %v, txsZeroValue: %v, txsConflicting: %v, collect: %v, total: %v",
 		conf.Index,
 		conf.TxsConfirmed,
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/events"
@@ -148,6 +149,10 @@ func processIncomingTx(incomingTx *hornet.Transaction, request *rqueue.Request,
 
 		if p != nil {
 			p.Metrics.NewTransactions.Inc()
+			p.Score.NewTransaction()
+			if request != nil && !request.RequestTime.IsZero() {
+				p.Score.RequestAnswered(time.Since(request.RequestTime))
+			}
 		}
 
 		// since we only add the approvees if there was a source request, we only
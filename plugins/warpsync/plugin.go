@@ -25,9 +25,12 @@ var (
 	log      *logger.Logger
 	warpSync *warpsync.WarpSync
 
+	milestoneConeSync bool
+
 	onPeerConnected                 *events.Closure
 	onSolidMilestoneIndexChanged    *events.Closure
 	onMilestoneSolidificationFailed *events.Closure
+	onReceivedNewMilestone          *events.Closure
 	onCheckpointUpdated             *events.Closure
 	onTargetUpdated                 *events.Closure
 	onStart                         *events.Closure
@@ -37,6 +40,7 @@ var (
 func configure(plugin *node.Plugin) {
 	log = logger.NewLogger(plugin.Name)
 	warpSync = warpsync.New(config.NodeConfig.GetInt(config.CfgWarpSyncAdvancementRange))
+	milestoneConeSync = config.NodeConfig.GetBool(config.CfgWarpSyncMilestoneConeSync)
 
 	configureEvents()
 }
@@ -75,12 +79,28 @@ func configureEvents() {
 		}
 	})
 
+	onReceivedNewMilestone = events.NewClosure(func(cachedBndl *tangle.CachedBundle) {
+		cachedBndl.ConsumeBundle(func(bndl *tangle.Bundle) { // bundle -1
+			if !milestoneConeSync {
+				return
+			}
+
+			// the cones of milestones within the synchronization range can be requested as soon as the milestone is known
+			msIndex := bndl.GetMilestoneIndex()
+			if msIndex <= tangle.GetSolidMilestoneIndex() || msIndex > warpSync.Checkpoint() {
+				return
+			}
+			gossip.RequestMilestoneCones(msIndex, msIndex)
+		})
+	})
+
 	onCheckpointUpdated = events.NewClosure(func(nextCheckpoint milestone.Index, oldCheckpoint milestone.Index, advRange int32, target milestone.Index) {
 		log.Infof("Checkpoint updated to milestone %d (target %d)", nextCheckpoint, target)
 		// prevent any requests in the queue above our next checkpoint
 		gossip.RequestQueue().Filter(func(r *rqueue.Request) bool {
 			return r.MilestoneIndex <= nextCheckpoint
 		})
+		requestMilestoneCones(oldCheckpoint, advRange)
 		requestMissingMilestoneApprovees := gossip.MemoizedRequestMissingMilestoneApprovees()
 		gossip.BroadcastMilestoneRequests(int(advRange), requestMissingMilestoneApprovees, oldCheckpoint)
 	})
@@ -94,6 +114,7 @@ func configureEvents() {
 		gossip.RequestQueue().Filter(func(r *rqueue.Request) bool {
 			return r.MilestoneIndex <= nextCheckpoint
 		})
+		requestMilestoneCones(tangle.GetSolidMilestoneIndex(), advRange)
 		requestMissingMilestoneApprovees := gossip.MemoizedRequestMissingMilestoneApprovees()
 		msRequested := gossip.BroadcastMilestoneRequests(int(advRange), requestMissingMilestoneApprovees)
 		// if the amount of requested milestones doesn't correspond to the range,
@@ -111,10 +132,41 @@ func configureEvents() {
 	})
 }
 
+// requests the cones of the known milestones after the given index in bulk.
+// received cones are verified against their milestone, so the cones of the milestones which are not known yet
+// are requested once the milestone was received.
+// the usual milestone and transaction requests act as a fallback for everything which is not delivered this way.
+func requestMilestoneCones(from milestone.Index, advRange int32) {
+	if !milestoneConeSync {
+		return
+	}
+
+	var requested int
+	endIndex := from + milestone.Index(advRange)
+	for msIndex := from + 1; msIndex <= endIndex; msIndex++ {
+		if !tangle.ContainsMilestone(msIndex) {
+			continue
+		}
+
+		// request the known milestones in contiguous ranges
+		rangeEndIndex := msIndex
+		for rangeEndIndex < endIndex && tangle.ContainsMilestone(rangeEndIndex+1) {
+			rangeEndIndex++
+		}
+		requested += gossip.RequestMilestoneCones(msIndex, rangeEndIndex)
+		msIndex = rangeEndIndex
+	}
+
+	if requested > 0 {
+		log.Infof("Requested cones of %d milestones in bulk", requested)
+	}
+}
+
 func attachEvents() {
 	peeringplugin.Manager().Events.PeerConnected.Attach(onPeerConnected)
 	tangleplugin.Events.SolidMilestoneIndexChanged.Attach(onSolidMilestoneIndexChanged)
 	tangleplugin.Events.MilestoneSolidificationFailed.Attach(onMilestoneSolidificationFailed)
+	tangleplugin.Events.ReceivedNewMilestone.Attach(onReceivedNewMilestone)
 	warpSync.Events.CheckpointUpdated.Attach(onCheckpointUpdated)
 	warpSync.Events.TargetUpdated.Attach(onTargetUpdated)
 	warpSync.Events.Start.Attach(onStart)
@@ -125,6 +177,7 @@ func detachEvents() {
 	peeringplugin.Manager().Events.PeerConnected.Detach(onPeerConnected)
 	tangleplugin.Events.SolidMilestoneIndexChanged.Detach(onSolidMilestoneIndexChanged)
 	tangleplugin.Events.MilestoneSolidificationFailed.Detach(onMilestoneSolidificationFailed)
+	tangleplugin.Events.ReceivedNewMilestone.Detach(onReceivedNewMilestone)
 	warpSync.Events.CheckpointUpdated.Detach(onCheckpointUpdated)
 	warpSync.Events.TargetUpdated.Detach(onTargetUpdated)
 	warpSync.Events.Start.Detach(onStart)
@@ -0,0 +1,101 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/iotaledger/iota.go/guards"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+)
+
+func init() {
+	addEndpoint("getConflictReasons", getConflictReasons, implementedAPIcalls)
+}
+
+// conflictReasonUnknown is returned for conflicting bundles which were confirmed
+// before the conflict reasons were stored or whose conflict reasons were already pruned.
+const conflictReasonUnknown = "unknown"
+
+func newConflictReason(conflict *tangle.Conflict) *ConflictReason {
+	reason := &ConflictReason{
+		TailTransaction: conflict.TailHash.Trytes(),
+		Conflicting:     true,
+		Reason:          conflict.Reason.String(),
+		Address:         conflict.Address.Trytes(),
+	}
+	if len(conflict.ConflictingTailHash) > 0 {
+		reason.ConflictingTailTransaction = conflict.ConflictingTailHash.Trytes()
+	}
+	return reason
+}
+
+func getConflictReasons(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetConflictReasons{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	for _, tx := range query.Transactions {
+		if !guards.IsTransactionHash(tx) {
+			e.Error = fmt.Sprintf("Invalid tail hash supplied: %s", tx)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+	}
+
+	reasons := make([]*ConflictReason, 0, len(query.Transactions))
+
+	for _, tx := range query.Transactions {
+		tailHash := hornet.HashFromHashTrytes(tx)
+
+		cachedTxMeta := tangle.GetCachedTxMetadataOrNil(tailHash) // meta +1
+		if cachedTxMeta == nil {
+			// if tx is unknown, it is not conflicting
+			reasons = append(reasons, &ConflictReason{TailTransaction: tx})
+			continue
+		}
+
+		isTail := cachedTxMeta.GetMetadata().IsTail()
+		conflicting := cachedTxMeta.GetMetadata().IsConflicting()
+		_, confirmationIndex := cachedTxMeta.GetMetadata().GetConfirmed()
+		cachedTxMeta.Release(true) // meta -1
+
+		if !isTail {
+			e.Error = fmt.Sprintf("transaction is not a tail: %s", tx)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		if !conflicting {
+			reasons = append(reasons, &ConflictReason{TailTransaction: tx, MilestoneIndex: confirmationIndex})
+			continue
+		}
+
+		conflict, err := tangle.GetConflict(confirmationIndex, tailHash)
+		if err != nil {
+			e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+
+		if conflict == nil {
+			reasons = append(reasons, &ConflictReason{TailTransaction: tx, Conflicting: true, MilestoneIndex: confirmationIndex, Reason: conflictReasonUnknown})
+			continue
+		}
+
+		reason := newConflictReason(conflict)
+		reason.MilestoneIndex = confirmationIndex
+		reasons = append(reasons, reason)
+	}
+
+	c.JSON(http.StatusOK, GetConflictReasonsReturn{Conflicts: reasons})
+}
@@ -0,0 +1,40 @@
+package webapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	coordinatorplugin "github.com/gohornet/hornet/plugins/coordinator"
+)
+
+func init() {
+	addEndpoint("issueMilestone", issueMilestone, implementedAPIcalls)
+}
+
+// issueMilestone issues a milestone if the coordinator runs in the "onDemand" scheduling mode.
+// The call is protected unless it is permitted via "httpAPI.permitRemoteAccess".
+func issueMilestone(_ interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	e := ErrorReturn{}
+
+	msIndex, msHash, err := coordinatorplugin.IssueMilestone(abortSignal)
+	if err != nil {
+		e.Error = err.Error()
+		if errors.Is(err, coordinatorplugin.ErrCoordinatorNotRunning) || errors.Is(err, coordinatorplugin.ErrNotOnDemand) || errors.Is(err, coordinatorplugin.ErrCoordinatorStandby) {
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		if errors.Is(err, coordinatorplugin.ErrCoordinatorBusy) {
+			c.JSON(http.StatusServiceUnavailable, e)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, IssueMilestoneReturn{
+		MilestoneIndex: msIndex,
+		MilestoneHash:  msHash.Trytes(),
+	})
+}
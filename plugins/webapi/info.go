@@ -12,6 +12,7 @@ import (
 	"github.com/gohornet/hornet/pkg/metrics"
 	"github.com/gohornet/hornet/pkg/model/tangle"
 	"github.com/gohornet/hornet/plugins/cli"
+	coordinatorplugin "github.com/gohornet/hornet/plugins/coordinator"
 	"github.com/gohornet/hornet/plugins/gossip"
 	"github.com/gohornet/hornet/plugins/peering"
 	tangleplugin "github.com/gohornet/hornet/plugins/tangle"
@@ -91,6 +92,9 @@ func getNodeInfo(_ interface{}, c *gin.Context, _ <-chan struct{}) {
 	// Coo addr
 	result.CoordinatorAddress = config.NodeConfig.GetString(config.CfgCoordinatorAddress)
 
+	// Merkle tree keys of the coordinator
+	result.CoordinatorMerkleKeys = coordinatorplugin.MerkleKeyUsage()
+
 	// Return node info
 	c.JSON(http.StatusOK, result)
 }
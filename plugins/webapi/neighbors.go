@@ -9,6 +9,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 
 	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/peering/peer"
 	"github.com/gohornet/hornet/plugins/peering"
 )
 
@@ -16,6 +17,7 @@ func init() {
 	addEndpoint("addNeighbors", addNeighbors, implementedAPIcalls)
 	addEndpoint("removeNeighbors", removeNeighbors, implementedAPIcalls)
 	addEndpoint("getNeighbors", getNeighbors, implementedAPIcalls)
+	addEndpoint("getPeerHistory", getPeerHistory, implementedAPIcalls)
 }
 
 func addNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
@@ -73,7 +75,7 @@ func addNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
 			added = true
 		}
 
-		if err := peering.Manager().Add(uri, preferIPv6, uri); err != nil {
+		if err := peering.Manager().Add(uri, preferIPv6, uri, ""); err != nil {
 			log.Warnf("can't add peer %s, Error: %s", uri, err)
 			continue
 		}
@@ -121,11 +123,12 @@ func addNeighborsWithAlias(s *AddNeighborsHornet, c *gin.Context) {
 				ID:         peer.Identity,
 				Alias:      peer.Alias,
 				PreferIPv6: peer.PreferIPv6,
+				PublicKey:  peer.PublicKey,
 			})
 			added = true
 		}
 
-		if err := peering.Manager().Add(peer.Identity, peer.PreferIPv6, peer.Alias); err != nil {
+		if err := peering.Manager().Add(peer.Identity, peer.PreferIPv6, peer.Alias, peer.PublicKey); err != nil {
 			log.Warnf("Can't add peer %s, Error: %s", peer.Identity, err)
 			continue
 		}
@@ -218,3 +221,28 @@ func removeNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
 func getNeighbors(i interface{}, c *gin.Context, _ <-chan struct{}) {
 	c.JSON(http.StatusOK, GetNeighborsReturn{Neighbors: peering.Manager().PeerInfos()})
 }
+
+func getPeerHistory(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetPeerHistory{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if query.Identity == "" {
+		c.JSON(http.StatusOK, GetPeerHistoryReturn{Histories: peering.Manager().PeerHistories()})
+		return
+	}
+
+	history := peering.Manager().PeerHistory(query.Identity)
+	if history == nil {
+		e.Error = fmt.Sprintf("no history recorded for peer %s", query.Identity)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetPeerHistoryReturn{Histories: map[string][]*peer.HistoryEvent{query.Identity: history}})
+}
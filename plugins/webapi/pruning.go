@@ -12,6 +12,8 @@ import (
 
 func init() {
 	addEndpoint("pruneDatabase", pruneDatabase, implementedAPIcalls)
+	addEndpoint("getPruningStatus", getPruningStatus, implementedAPIcalls)
+	addEndpoint("abortPruning", abortPruning, implementedAPIcalls)
 }
 
 func pruneDatabase(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -46,3 +48,42 @@ func pruneDatabase(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
 
 	c.JSON(http.StatusOK, PruneDatabaseReturn{})
 }
+
+func getPruningStatus(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+
+	status, err := snapshot.GetPruningStatus()
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	result := GetPruningStatusReturn{
+		IsPruning:          status.IsPruning,
+		IsInterrupted:      status.IsInterrupted,
+		StartIndex:         status.StartIndex,
+		PruningIndex:       status.PruningIndex,
+		TargetIndex:        status.TargetIndex,
+		TransactionsPruned: status.TransactionsPruned,
+		Percentage:         status.Percentage,
+		ETA:                int64(status.ETA.Seconds()),
+	}
+	if !status.StartTime.IsZero() {
+		result.StartTimestamp = status.StartTime.Unix()
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func abortPruning(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+
+	if err := snapshot.AbortPruning(); err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, AbortPruningReturn{})
+}
@@ -1,9 +1,12 @@
 package webapi
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mitchellh/mapstructure"
@@ -15,6 +18,9 @@ import (
 
 func init() {
 	addEndpoint("createSnapshotFile", createSnapshotFile, implementedAPIcalls)
+	addEndpoint("listSnapshots", listSnapshots, implementedAPIcalls)
+	addEndpoint("exportSpentAddresses", exportSpentAddresses, implementedAPIcalls)
+	addEndpoint("importSpentAddresses", importSpentAddresses, implementedAPIcalls)
 }
 
 func createSnapshotFile(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -37,3 +43,95 @@ func createSnapshotFile(i interface{}, c *gin.Context, abortSignal <-chan struct
 
 	c.JSON(http.StatusOK, CreateSnapshotFileReturn{})
 }
+
+func listSnapshots(_ interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+
+	snapshotFiles, err := snapshot.ListSnapshots()
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	result := ListSnapshotsReturn{Snapshots: []*SnapshotFile{}}
+	for _, snapshotFile := range snapshotFiles {
+		result.Snapshots = append(result.Snapshots, &SnapshotFile{
+			Type:               snapshotFile.Type,
+			Name:               snapshotFile.Name,
+			FilePath:           snapshotFile.FilePath,
+			MilestoneIndex:     snapshotFile.MilestoneIndex,
+			MilestoneTimestamp: snapshotFile.MilestoneTimestamp,
+			FileSize:           snapshotFile.FileSize,
+			ModTime:            snapshotFile.ModTime.Unix(),
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func exportSpentAddresses(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &ExportSpentAddresses{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	ts := time.Now()
+
+	spentAddressesFilePath, header, err := snapshot.ExportSpentAddresses(abortSignal)
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, ExportSpentAddressesReturn{
+		FilePath:            spentAddressesFilePath,
+		MilestoneIndex:      header.MilestoneIndex,
+		SpentAddressesCount: header.SpentAddressesCount,
+		MerkleRoot:          hex.EncodeToString(header.MerkleRoot),
+		Duration:            int(time.Since(ts).Milliseconds()),
+	})
+}
+
+func importSpentAddresses(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &ImportSpentAddresses{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if query.FilePath == "" {
+		e.Error = "No filePath provided"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	ts := time.Now()
+
+	header, newlyAddedCount, err := snapshot.ImportSpentAddresses(query.FilePath, abortSignal)
+	if err != nil {
+		e.Error = err.Error()
+		if errors.Is(err, snapshot.ErrSpentAddressesFileOutsideDir) {
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, ImportSpentAddressesReturn{
+		MilestoneIndex:         header.MilestoneIndex,
+		SpentAddressesCount:    header.SpentAddressesCount,
+		NewSpentAddressesCount: newlyAddedCount,
+		MerkleRoot:             hex.EncodeToString(header.MerkleRoot),
+		Duration:               int(time.Since(ts).Milliseconds()),
+	})
+}
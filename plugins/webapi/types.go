@@ -3,6 +3,8 @@ package webapi
 import (
 	"github.com/iotaledger/iota.go/trinary"
 
+	"github.com/gohornet/hornet/pkg/inclusionproof"
+	"github.com/gohornet/hornet/pkg/model/coordinator"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/peering/peer"
 )
@@ -26,6 +28,7 @@ type Neighbor struct {
 	Identity   string `mapstructure:"identity"`
 	Alias      string `mapstructure:"alias"`
 	PreferIPv6 bool   `mapstructure:"prefer_ipv6"`
+	PublicKey  string `mapstructure:"public_key"`
 }
 
 // AddNeighborsResponse struct
@@ -133,6 +136,30 @@ type GetInclusionStatesReturn struct {
 	Duration int    `json:"duration"`
 }
 
+///////////////////// getConflictReasons ////////////////////////////////
+
+// GetConflictReasons struct
+type GetConflictReasons struct {
+	Command      string         `mapstructure:"command"`
+	Transactions []trinary.Hash `mapstructure:"transactions"`
+}
+
+// ConflictReason struct
+type ConflictReason struct {
+	TailTransaction            trinary.Hash    `json:"tailTransaction"`
+	Conflicting                bool            `json:"conflicting"`
+	MilestoneIndex             milestone.Index `json:"milestoneIndex,omitempty"`
+	Reason                     string          `json:"reason,omitempty"`
+	Address                    trinary.Hash    `json:"address,omitempty"`
+	ConflictingTailTransaction trinary.Hash    `json:"conflictingTailTransaction,omitempty"`
+}
+
+// GetConflictReasonsReturn struct
+type GetConflictReasonsReturn struct {
+	Conflicts []*ConflictReason `json:"conflicts"`
+	Duration  int               `json:"duration"`
+}
+
 ////////////////////// getNeighbors ///////////////////////////////
 
 // GetNeighbors struct
@@ -146,6 +173,21 @@ type GetNeighborsReturn struct {
 	Duration  int          `json:"duration"`
 }
 
+///////////////////// getPeerHistory ////////////////////////////
+
+// GetPeerHistory struct
+type GetPeerHistory struct {
+	Command string `mapstructure:"command"`
+	// The ID (ip:port) of the peer, the histories of all peers are returned if it's empty.
+	Identity string `mapstructure:"identity"`
+}
+
+// GetPeerHistoryReturn struct
+type GetPeerHistoryReturn struct {
+	Histories map[string][]*peer.HistoryEvent `json:"histories"`
+	Duration  int                             `json:"duration"`
+}
+
 /////////////////////// getNodeInfo ///////////////////////////////
 
 // GetNodeInfo struct
@@ -172,7 +214,9 @@ type GetNodeInfoReturn struct {
 	TransactionsToRequest              int             `json:"transactionsToRequest"`
 	Features                           []string        `json:"features"`
 	CoordinatorAddress                 trinary.Hash    `json:"coordinatorAddress"`
-	Duration                           int             `json:"duration"`
+	// only set if the node runs the coordinator
+	CoordinatorMerkleKeys *coordinator.MerkleKeyUsage `json:"coordinatorMerkleKeys,omitempty"`
+	Duration              int                         `json:"duration"`
 }
 
 ////////////////// getNodeAPIConfiguration //////////////////////////
@@ -358,6 +402,63 @@ type CreateSnapshotFileReturn struct {
 	Duration int `json:"duration"`
 }
 
+/////////////////// listSnapshots ////////////////////////
+
+// ListSnapshots struct
+type ListSnapshots struct {
+	Command string `mapstructure:"command"`
+}
+
+// SnapshotFile struct
+type SnapshotFile struct {
+	Type               string          `json:"type"`
+	Name               string          `json:"name,omitempty"`
+	FilePath           string          `json:"filePath"`
+	MilestoneIndex     milestone.Index `json:"milestoneIndex"`
+	MilestoneTimestamp int64           `json:"milestoneTimestamp"`
+	FileSize           int64           `json:"fileSize"`
+	ModTime            int64           `json:"modTime"`
+}
+
+// ListSnapshotsReturn struct
+type ListSnapshotsReturn struct {
+	Snapshots []*SnapshotFile `json:"snapshots"`
+	Duration  int             `json:"duration"`
+}
+
+/////////////////// exportSpentAddresses ////////////////////////
+
+// ExportSpentAddresses struct
+type ExportSpentAddresses struct {
+	Command string `mapstructure:"command"`
+}
+
+// ExportSpentAddressesReturn struct
+type ExportSpentAddressesReturn struct {
+	FilePath            string          `json:"filePath"`
+	MilestoneIndex      milestone.Index `json:"milestoneIndex"`
+	SpentAddressesCount int32           `json:"spentAddressesCount"`
+	MerkleRoot          string          `json:"merkleRoot"`
+	Duration            int             `json:"duration"`
+}
+
+/////////////////// importSpentAddresses ////////////////////////
+
+// ImportSpentAddresses struct
+type ImportSpentAddresses struct {
+	Command  string `mapstructure:"command"`
+	FilePath string `mapstructure:"filePath"`
+}
+
+// ImportSpentAddressesReturn struct
+type ImportSpentAddressesReturn struct {
+	MilestoneIndex         milestone.Index `json:"milestoneIndex"`
+	SpentAddressesCount    int32           `json:"spentAddressesCount"`
+	NewSpentAddressesCount int             `json:"newSpentAddressesCount"`
+	MerkleRoot             string          `json:"merkleRoot"`
+	Duration               int             `json:"duration"`
+}
+
 /////////////////// pruneDatabase ////////////////////////
 
 // PruneDatabase struct
@@ -372,6 +473,29 @@ type PruneDatabaseReturn struct {
 	Duration int `json:"duration"`
 }
 
+/////////////////// getPruningStatus ////////////////////////
+
+// GetPruningStatusReturn struct
+type GetPruningStatusReturn struct {
+	IsPruning          bool            `json:"isPruning"`
+	IsInterrupted      bool            `json:"isInterrupted"`
+	StartIndex         milestone.Index `json:"startIndex"`
+	StartTimestamp     int64           `json:"startTimestamp"`
+	PruningIndex       milestone.Index `json:"pruningIndex"`
+	TargetIndex        milestone.Index `json:"targetIndex"`
+	TransactionsPruned int             `json:"transactionsPruned"`
+	Percentage         float64         `json:"percentage"`
+	ETA                int64           `json:"eta"`
+	Duration           int             `json:"duration"`
+}
+
+/////////////////// abortPruning ////////////////////////
+
+// AbortPruningReturn struct
+type AbortPruningReturn struct {
+	Duration int `json:"duration"`
+}
+
 ///////////////////// getRequests /////////////////////////////////
 
 // GetRequests struct
@@ -461,3 +585,72 @@ type AddressWithBalance struct {
 	Address trinary.Hash `mapstructure:"address"`
 	Balance uint64       `mapstructure:"balance"`
 }
+
+///////////////// simulateConfirmation ////////////////////////
+
+// SimulateConfirmation struct
+type SimulateConfirmation struct {
+	Command           string         `mapstructure:"command"`
+	TrunkTransaction  trinary.Hash   `mapstructure:"trunkTransaction"`
+	BranchTransaction trinary.Hash   `mapstructure:"branchTransaction"`
+	Tips              []trinary.Hash `mapstructure:"tips"`
+}
+
+// SimulateConfirmationReturn struct
+type SimulateConfirmationReturn struct {
+	TailsIncluded            []trinary.Hash         `json:"tailsIncluded"`
+	TailsExcludedConflicting []trinary.Hash         `json:"tailsExcludedConflicting"`
+	Conflicts                []*ConflictReason      `json:"conflicts"`
+	TailsExcludedZeroValue   []trinary.Hash         `json:"tailsExcludedZeroValue"`
+	TailsReferenced          []trinary.Hash         `json:"tailsReferenced"`
+	AddressMutations         map[trinary.Hash]int64 `json:"addressMutations"`
+	MerkleTreeHash           string                 `json:"merkleTreeHash"`
+	Duration                 int                    `json:"duration"`
+}
+
+///////////////// getMilestoneConfirmation ////////////////////////
+
+// GetMilestoneConfirmation struct
+type GetMilestoneConfirmation struct {
+	Command         string          `mapstructure:"command"`
+	MilestoneIndex  milestone.Index `mapstructure:"milestoneIndex"`
+	TailTransaction trinary.Hash    `mapstructure:"tailTransaction"`
+}
+
+// GetMilestoneConfirmationReturn struct
+type GetMilestoneConfirmationReturn struct {
+	MilestoneIndex           milestone.Index       `json:"milestoneIndex"`
+	MilestoneHash            trinary.Hash          `json:"milestoneHash"`
+	TailsIncluded            []trinary.Hash        `json:"tailsIncluded"`
+	TailsExcludedConflicting []trinary.Hash        `json:"tailsExcludedConflicting"`
+	TailsExcludedZeroValue   []trinary.Hash        `json:"tailsExcludedZeroValue"`
+	TailsReferenced          []trinary.Hash        `json:"tailsReferenced"`
+	MerkleTreeHash           string                `json:"merkleTreeHash"`
+	InclusionProof           *inclusionproof.Proof `json:"inclusionProof,omitempty"`
+	Duration                 int                   `json:"duration"`
+}
+
+///////////////// getInclusionProof ////////////////////////
+
+// GetInclusionProof struct
+type GetInclusionProof struct {
+	Command         string          `mapstructure:"command"`
+	TailTransaction trinary.Hash    `mapstructure:"tailTransaction"`
+	MilestoneIndex  milestone.Index `mapstructure:"milestoneIndex"`
+}
+
+// GetInclusionProofReturn struct
+type GetInclusionProofReturn struct {
+	MilestoneIndex milestone.Index       `json:"milestoneIndex"`
+	Proof          *inclusionproof.Proof `json:"proof"`
+	Duration       int                   `json:"duration"`
+}
+
+///////////////// issueMilestone ////////////////////////
+
+// IssueMilestoneReturn struct
+type IssueMilestoneReturn struct {
+	MilestoneIndex milestone.Index `json:"milestoneIndex"`
+	MilestoneHash  trinary.Hash    `json:"milestoneHash"`
+	Duration       int             `json:"duration"`
+}
@@ -0,0 +1,291 @@
+package webapi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/trinary"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/gohornet/hornet/pkg/config"
+	"github.com/gohornet/hornet/pkg/inclusionproof"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/tangle"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+)
+
+func init() {
+	addEndpoint("simulateConfirmation", simulateConfirmation, implementedAPIcalls)
+	addEndpoint("getMilestoneConfirmation", getMilestoneConfirmation, implementedAPIcalls)
+	addEndpoint("getInclusionProof", getInclusionProof, implementedAPIcalls)
+}
+
+func simulateConfirmation(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &SimulateConfirmation{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if !tangle.IsNodeSyncedWithThreshold() {
+		e.Error = "node is not synced"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	// the candidate tips are traversed in the given order, trunk and branch are used if no tips are given
+	tipsTrytes := query.Tips
+	if len(tipsTrytes) == 0 {
+		if query.TrunkTransaction == "" || query.BranchTransaction == "" {
+			e.Error = "either tips or trunk and branch transaction must be given"
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		tipsTrytes = []trinary.Hash{query.TrunkTransaction, query.BranchTransaction}
+	}
+
+	// the ledger is locked during the simulation, so the tips and the traversed cone are limited
+	maxTips := config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxSimulateConfirmationTips)
+	if len(tipsTrytes) > maxTips {
+		e.Error = fmt.Sprintf("too many tips given, max %d allowed", maxTips)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	tips := make(hornet.Hashes, 0, len(tipsTrytes))
+	for _, tipTrytes := range tipsTrytes {
+		if !guards.IsTransactionHash(tipTrytes) {
+			e.Error = fmt.Sprintf("invalid tail hash supplied: %s", tipTrytes)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		tip := hornet.HashFromHashTrytes(tipTrytes)
+
+		cachedTxMeta := tangle.GetCachedTxMetadataOrNil(tip) // meta +1
+		if cachedTxMeta == nil {
+			e.Error = fmt.Sprintf("unknown tail transaction: %s", tipTrytes)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		isTail := cachedTxMeta.GetMetadata().IsTail()
+		isSolid := cachedTxMeta.GetMetadata().IsSolid()
+		cachedTxMeta.Release(true) // meta -1
+
+		if !isTail {
+			e.Error = fmt.Sprintf("transaction is not a tail: %s", tipTrytes)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		if !isSolid {
+			e.Error = fmt.Sprintf("transaction is not solid: %s", tipTrytes)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		tips = append(tips, tip)
+	}
+
+	mutations, err := whiteflag.SimulateWhiteFlagMutations(tangle.GetMilestoneMerkleHashFunc(), tips, maxTips, config.NodeConfig.GetInt(config.CfgWebAPILimitsMaxSimulateConfirmationTransactions), abortSignal)
+	if err != nil {
+		e.Error = fmt.Sprintf("simulating the confirmation failed: %v", err)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	addressMutations := make(map[trinary.Hash]int64, len(mutations.AddressMutations))
+	for address, change := range mutations.AddressMutations {
+		addressMutations[hornet.Hash(address).Trytes()] = change
+	}
+
+	conflicts := make([]*ConflictReason, 0, len(mutations.Conflicts))
+	for _, conflict := range mutations.Conflicts {
+		conflicts = append(conflicts, newConflictReason(conflict))
+	}
+
+	c.JSON(http.StatusOK, SimulateConfirmationReturn{
+		TailsIncluded:            mutations.TailsIncluded.Trytes(),
+		TailsExcludedConflicting: mutations.TailsExcludedConflicting.Trytes(),
+		Conflicts:                conflicts,
+		TailsExcludedZeroValue:   mutations.TailsExcludedZeroValue.Trytes(),
+		TailsReferenced:          mutations.TailsReferenced.Trytes(),
+		AddressMutations:         addressMutations,
+		MerkleTreeHash:           hex.EncodeToString(mutations.MerkleTreeHash),
+	})
+}
+
+func getMilestoneConfirmation(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetMilestoneConfirmation{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if query.TailTransaction != "" && !guards.IsTransactionHash(query.TailTransaction) {
+		e.Error = "invalid tail hash supplied"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	smi := tangle.GetSolidMilestoneIndex()
+	if query.MilestoneIndex > smi {
+		e.Error = fmt.Sprintf("Invalid milestone index supplied, lsmi is %d", smi)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	conf, err := tangle.GetMilestoneConfirmation(query.MilestoneIndex)
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if conf == nil {
+		e.Error = fmt.Sprintf("confirmation of milestone %d not found", query.MilestoneIndex)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	// the stored Merkle tree hash has to match the one signed by the coordinator
+	cachedMs := tangle.GetMilestoneOrNil(query.MilestoneIndex) // bundle +1
+	if cachedMs == nil {
+		e.Error = fmt.Sprintf("milestone %d not found", query.MilestoneIndex)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+	merkleTreeHash, err := cachedMs.GetBundle().GetMilestoneMerkleTreeHash()
+	cachedMs.Release(true) // bundle -1
+
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if !bytes.Equal(conf.MerkleTreeHash, merkleTreeHash) {
+		e.Error = fmt.Sprintf("%v: stored Merkle tree hash %s does not match the value in the milestone %s", ErrInternalError, hex.EncodeToString(conf.MerkleTreeHash), hex.EncodeToString(merkleTreeHash))
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	result := GetMilestoneConfirmationReturn{
+		MilestoneIndex:           conf.MilestoneIndex,
+		MilestoneHash:            conf.MilestoneHash.Trytes(),
+		TailsIncluded:            conf.TailsIncluded.Trytes(),
+		TailsExcludedConflicting: conf.TailsExcludedConflicting.Trytes(),
+		TailsExcludedZeroValue:   conf.TailsExcludedZeroValue.Trytes(),
+		TailsReferenced:          conf.TailsReferenced.Trytes(),
+		MerkleTreeHash:           hex.EncodeToString(conf.MerkleTreeHash),
+	}
+
+	if query.TailTransaction != "" {
+		proof, err := createInclusionProof(conf, hornet.HashFromHashTrytes(query.TailTransaction))
+		if err != nil {
+			e.Error = err.Error()
+			if errors.Is(err, ErrInternalError) {
+				c.JSON(http.StatusInternalServerError, e)
+				return
+			}
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		result.InclusionProof = proof
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func getInclusionProof(i interface{}, c *gin.Context, _ <-chan struct{}) {
+	e := ErrorReturn{}
+	query := &GetInclusionProof{}
+
+	if err := mapstructure.Decode(i, query); err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if !guards.IsTransactionHash(query.TailTransaction) {
+		e.Error = "invalid tail hash supplied"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	tailHash := hornet.HashFromHashTrytes(query.TailTransaction)
+
+	// the milestone which confirmed the tail is used if no milestone index is given
+	msIndex := query.MilestoneIndex
+	if msIndex == 0 {
+		cachedTxMeta := tangle.GetCachedTxMetadataOrNil(tailHash) // meta +1
+		if cachedTxMeta == nil {
+			e.Error = "unknown tail transaction"
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		confirmed, confirmationIndex := cachedTxMeta.GetMetadata().GetConfirmed()
+		conflicting := cachedTxMeta.GetMetadata().IsConflicting()
+		cachedTxMeta.Release(true) // meta -1
+
+		if !confirmed || conflicting {
+			e.Error = "tail transaction was not included by a milestone"
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		msIndex = confirmationIndex
+	}
+
+	conf, err := tangle.GetMilestoneConfirmation(msIndex)
+	if err != nil {
+		e.Error = fmt.Sprintf("%v: %v", ErrInternalError, err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if conf == nil {
+		e.Error = fmt.Sprintf("confirmation of milestone %d not found", msIndex)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	proof, err := createInclusionProof(conf, tailHash)
+	if err != nil {
+		e.Error = err.Error()
+		if errors.Is(err, ErrInternalError) {
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetInclusionProofReturn{MilestoneIndex: msIndex, Proof: proof})
+}
+
+// createInclusionProof creates the proof that the bundle of the given tail was included by the given milestone confirmation.
+// Errors which are not caused by the request are wrapped in ErrInternalError.
+func createInclusionProof(conf *tangle.MilestoneConfirmation, tailHash hornet.Hash) (*inclusionproof.Proof, error) {
+
+	proof, err := inclusionproof.CreateProof(conf, tailHash, tangle.GetMilestoneMerkleHashFunc())
+	if err != nil {
+		if errors.Is(err, inclusionproof.ErrTailNotIncluded) || errors.Is(err, inclusionproof.ErrMilestoneNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	return proof, nil
+}